@@ -0,0 +1,75 @@
+// Copyright 2014 Rocky Bernstein.
+// Splitting command lines into arguments
+
+package repl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SplitArgs splits the arguments of a command line into words, much
+// as a shell does. Words are separated by spaces and tabs. Within
+// single quotes everything is taken as it is; within double quotes a
+// backslash escapes the next character; elsewhere a backslash
+// escapes the next character and quotes can start anywhere in a
+// word, so `a"b c"d` is the single word "ab cd". An empty pair of
+// quotes is an empty word.
+//
+// A "--" is kept as a word, for commands to end their options with.
+func SplitArgs(line string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("nothing to escape after \\ at the end of the line")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// QuoteArg quotes word, if need be, so that SplitArgs gives it back as
+// a single word.
+func QuoteArg(word string) string {
+	special := strings.IndexFunc(word, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '\'' || r == '"' || r == '\\'
+	})
+	if word != "" && special < 0 {
+		return word
+	}
+	return "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+}
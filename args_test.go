@@ -0,0 +1,54 @@
+package repl_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		"":                  {},
+		"a  b\tc":           {"a", "b", "c"},
+		`"My File.fish"`:    {"My File.fish"},
+		`'a "b" \c'`:        {`a "b" \c`},
+		`"a \"b\" \\c"`:     {`a "b" \c`},
+		`a\ b x"y z"w`:      {"a b", "xy zw"},
+		`'' ""`:             {"", ""},
+		"-q -- -file":       {"-q", "--", "-file"},
+		`"it's" 'say "hi"'`: {"it's", `say "hi"`},
+	}
+	for line, want := range tests {
+		got, err := repl.SplitArgs(line)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitArgs(%q): expecting %q; got %q, %v", line, want,
+				got, err)
+		}
+	}
+	for _, word := range []string{"", "plain", "My File.fish", `it's`,
+		`a "b" \c`, "tab\there", "no\u00a0break"} {
+		got, err := repl.SplitArgs("source -q " + repl.QuoteArg(word))
+		if err != nil || len(got) != 3 || got[2] != word {
+			t.Errorf("QuoteArg(%q): expecting it back; got %q, %v", word,
+				got, err)
+		}
+	}
+	for _, line := range []string{`"abc`, `'abc`, `abc\`} {
+		if _, err := repl.SplitArgs(line); err == nil {
+			t.Errorf("SplitArgs(%q): expecting an error", line)
+		}
+	}
+}
+
+func TestBlankLines(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	for _, line := range []string{"\v", "\f", "\u00a0", "\r", " \v \r\n",
+		":\v", "history\r"} {
+		c := s.Capture(line, true)
+		if c.Result != nil {
+			t.Errorf("%q: expecting nothing evaluated", line)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2014 Rocky Bernstein.
+// Declarative specs for command arguments
+
+package repl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArgType is the type of value an argument or option takes.
+type ArgType int
+
+const (
+	StringArg   ArgType = iota // any word
+	IntArg                     // an integer between Min and Max
+	BoolArg                    // an option that takes no value
+	DurationArg                // a duration such as "10s"
+	EnumArg                    // one of Values
+	FileArg                    // a file name; a leading "~" is $HOME
+	ExprArg                    // a Go expression: the rest of the line
+)
+
+// ArgSpec describes an argument or option of a command, for CmdInfo.Args.
+type ArgSpec struct {
+	// Name is what the argument is called in usage and in errors,
+	// and what its value is looked up by in ParsedArgs. The name of
+	// an option starts with "-", as in "-q".
+	Name string
+	Type ArgType
+
+	// ValueName is what an option's value is called in usage, as in
+	// "-n *count*".
+	ValueName string
+
+	// Optional is set for positional arguments that may be left out;
+	// options are always optional.
+	Optional bool
+
+	// Repeated lets the last positional argument take any number of
+	// words; get them with ParsedArgs.Strings.
+	Repeated bool
+
+	// Min and Max bound an IntArg; a Max of 0 means no bound.
+	Min, Max int
+
+	// Values are the choices for an EnumArg.
+	Values []string
+}
+
+// ArgError is an argument that a command can't make sense of.
+type ArgError struct {
+	Arg    string // the name of the argument, if known
+	Value  string // what was given, if anything
+	Reason string
+}
+
+func (e *ArgError) Error() string {
+	msg := e.Reason
+	if e.Arg != "" {
+		msg = e.Arg + ": " + msg
+	}
+	if e.Value != "" {
+		msg += "; got " + e.Value
+	}
+	return msg
+}
+
+// ParsedArgs are the arguments and options of a command, converted
+// according to the command's CmdInfo.Args.
+type ParsedArgs struct {
+	values map[string]interface{}
+}
+
+// CmdArgs are the parsed arguments of the command being run, for
+// commands that have CmdInfo.Args.
+var CmdArgs *ParsedArgs
+
+// Has reports whether argument or option "name" was given.
+func (a *ParsedArgs) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// String returns the value of a StringArg, EnumArg, FileArg or
+// ExprArg, or "" if it wasn't given.
+func (a *ParsedArgs) String(name string) string {
+	s, _ := a.values[name].(string)
+	return s
+}
+
+// Int returns the value of an IntArg, or 0 if it wasn't given.
+func (a *ParsedArgs) Int(name string) int {
+	i, _ := a.values[name].(int)
+	return i
+}
+
+// Bool reports whether BoolArg option "name" was given.
+func (a *ParsedArgs) Bool(name string) bool {
+	b, _ := a.values[name].(bool)
+	return b
+}
+
+// Duration returns the value of a DurationArg, or 0 if it wasn't
+// given.
+func (a *ParsedArgs) Duration(name string) time.Duration {
+	d, _ := a.values[name].(time.Duration)
+	return d
+}
+
+// Strings returns the words of a Repeated argument.
+func (a *ParsedArgs) Strings(name string) []string {
+	words, _ := a.values[name].([]string)
+	return words
+}
+
+// ParseArgs checks and converts words, the arguments after a command
+// name, according to specs. Options come first, up to a "--" or the
+// first word that doesn't start with "-". An ExprArg takes what is
+// left of rest, the text that words came from, after the words
+// before it; so commands with one should have RawArgs set.
+func ParseArgs(specs []*ArgSpec, words []string, rest string) (*ParsedArgs, error) {
+	a := &ParsedArgs{values: make(map[string]interface{})}
+	options, positional := splitSpecs(specs)
+	i := 0
+	for ; i < len(words) && len(words[i]) > 1 && words[i][0] == '-'; i++ {
+		if words[i] == "--" {
+			i++
+			break
+		}
+		spec := options[words[i]]
+		if spec == nil {
+			return nil, &ArgError{Arg: words[i], Reason: "unknown option"}
+		}
+		if spec.Type == BoolArg {
+			a.values[spec.Name] = true
+			continue
+		}
+		if i+1 == len(words) {
+			return nil, &ArgError{Arg: spec.Name, Reason: "expecting " +
+				spec.describe()}
+		}
+		i++
+		value, err := spec.convert(words[i])
+		if err != nil {
+			return nil, err
+		}
+		a.values[spec.Name] = value
+	}
+
+	for _, spec := range positional {
+		if i == len(words) {
+			if !spec.Optional {
+				return nil, &ArgError{Arg: spec.Name, Reason: "expecting " +
+					spec.describe()}
+			}
+			break
+		}
+		switch {
+		case spec.Type == ExprArg:
+			a.values[spec.Name] = skipFields(rest, i)
+			i = len(words)
+		case spec.Repeated:
+			for _, word := range words[i:] {
+				if _, err := spec.convert(word); err != nil {
+					return nil, err
+				}
+			}
+			a.values[spec.Name] = words[i:]
+			i = len(words)
+		default:
+			value, err := spec.convert(words[i])
+			if err != nil {
+				return nil, err
+			}
+			a.values[spec.Name] = value
+			i++
+		}
+	}
+	if i < len(words) {
+		return nil, &ArgError{Value: words[i], Reason: "too many arguments"}
+	}
+	return a, nil
+}
+
+// splitSpecs separates the options in specs, by name, from the
+// positional arguments.
+func splitSpecs(specs []*ArgSpec) (map[string]*ArgSpec, []*ArgSpec) {
+	options := make(map[string]*ArgSpec)
+	positional := []*ArgSpec{}
+	for _, spec := range specs {
+		if strings.HasPrefix(spec.Name, "-") {
+			options[spec.Name] = spec
+		} else {
+			positional = append(positional, spec)
+		}
+	}
+	return options, positional
+}
+
+// skipFields returns what is left of text after n words separated by
+// white space.
+func skipFields(text string, n int) string {
+	for ; n > 0; n-- {
+		text = strings.TrimLeft(text, " \t")
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			text = text[i:]
+		} else {
+			text = ""
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// convert converts word to the type of value that spec takes.
+func (spec *ArgSpec) convert(word string) (interface{}, error) {
+	bad := &ArgError{Arg: spec.Name, Value: word,
+		Reason: "expecting " + spec.describe()}
+	switch spec.Type {
+	case IntArg:
+		i, err := strconv.Atoi(word)
+		if err != nil || i < spec.Min || (spec.Max > 0 && i > spec.Max) {
+			return nil, bad
+		}
+		return i, nil
+	case DurationArg:
+		d, err := time.ParseDuration(word)
+		if err != nil {
+			return nil, bad
+		}
+		return d, nil
+	case EnumArg:
+		for _, value := range spec.Values {
+			if word == value {
+				return word, nil
+			}
+		}
+		return nil, bad
+	case FileArg:
+		if word == "~" || strings.HasPrefix(word, "~/") {
+			word = filepath.Join(os.Getenv("HOME"), word[1:])
+		}
+		return word, nil
+	}
+	return word, nil
+}
+
+// describe says what kind of value spec takes, for error messages.
+func (spec *ArgSpec) describe() string {
+	switch spec.Type {
+	case IntArg:
+		switch {
+		case spec.Max > 0:
+			return fmt.Sprintf("an integer from %d to %d", spec.Min, spec.Max)
+		case spec.Min != 0:
+			return fmt.Sprintf("an integer of at least %d", spec.Min)
+		}
+		return "an integer"
+	case DurationArg:
+		return `a duration such as "10s"`
+	case EnumArg:
+		return "one of " + strings.Join(spec.Values, ", ")
+	case FileArg:
+		return "a file name"
+	case ExprArg:
+		return "a Go expression"
+	}
+	if spec.ValueName != "" {
+		return "a " + spec.ValueName
+	}
+	return "a value"
+}
+
+// maxUsageValues is how many values of an EnumArg a usage line shows;
+// one with more is shown by name, and its help should list them.
+const maxUsageValues = 4
+
+// usage is how spec is shown in a usage line.
+func (spec *ArgSpec) usage() string {
+	text := "*" + spec.Name + "*"
+	switch {
+	case spec.Type == BoolArg:
+		text = spec.Name
+	case strings.HasPrefix(spec.Name, "-"):
+		text = spec.Name + " *" + spec.ValueName + "*"
+	case spec.Type == EnumArg && len(spec.Values) <= maxUsageValues:
+		text = strings.Join(spec.Values, "|")
+	}
+	if spec.Repeated {
+		text += "..."
+	}
+	if spec.Optional || strings.HasPrefix(spec.Name, "-") {
+		text = "[" + text + "]"
+	}
+	return text
+}
+
+// Usage returns the synopsis of command "name": cmd.Synopsis, or else
+// a usage line generated from cmd.Args, as in "history [*count*]", or
+// else "".
+func (cmd *CmdInfo) Usage(name string) string {
+	if cmd.Synopsis != "" {
+		return cmd.Synopsis
+	}
+	if cmd.Args == nil {
+		return ""
+	}
+	words := []string{name}
+	options, positional := splitSpecs(cmd.Args)
+	for _, spec := range cmd.Args {
+		if options[spec.Name] != nil {
+			words = append(words, spec.usage())
+		}
+	}
+	for _, spec := range positional {
+		words = append(words, spec.usage())
+	}
+	return strings.Join(words, " ")
+}
+
+// argCandidates returns the completions for word, an argument of a
+// command with specs that comes after the arguments in before. It
+// returns false if word isn't an option name or a value that there
+// are completions for.
+func argCandidates(specs []*ArgSpec, before []string, word string) ([]string, bool) {
+	options, positional := splitSpecs(specs)
+	candidates := []string{}
+	if strings.HasPrefix(word, "-") && len(options) > 0 {
+		for name := range options {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+		return candidates, true
+	}
+	var spec *ArgSpec
+	n := 0
+	for i := 0; i < len(before); i++ {
+		if option := options[before[i]]; option != nil {
+			if option.Type != BoolArg {
+				i++
+				if i == len(before) {
+					spec = option
+				}
+			}
+			continue
+		}
+		n++
+	}
+	if spec == nil {
+		if n >= len(positional) {
+			if n == 0 || !positional[len(positional)-1].Repeated {
+				return nil, false
+			}
+			n = len(positional) - 1
+		}
+		spec = positional[n]
+	}
+	switch spec.Type {
+	case EnumArg:
+		for _, value := range spec.Values {
+			if strings.HasPrefix(value, word) {
+				candidates = append(candidates, value)
+			}
+		}
+	case FileArg:
+		matches, _ := filepath.Glob(word + "*")
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				match += string(filepath.Separator)
+			}
+			candidates = append(candidates, match)
+		}
+	default:
+		return nil, false
+	}
+	return candidates, true
+}
@@ -0,0 +1,105 @@
+package repl_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestParseArgs(t *testing.T) {
+	cmd := &repl.CmdInfo{Args: []*repl.ArgSpec{
+		{Name: "-q", Type: repl.BoolArg},
+		{Name: "-n", Type: repl.IntArg, ValueName: "count", Min: 1},
+		{Name: "-d", Type: repl.DurationArg, ValueName: "time"},
+		{Name: "kind", Type: repl.EnumArg, Values: []string{"cpu", "heap"}},
+		{Name: "expression", Type: repl.ExprArg, Optional: true},
+	}}
+	want := "profile [-q] [-n *count*] [-d *time*] cpu|heap [*expression*]"
+	if got := cmd.Usage("profile"); got != want {
+		t.Errorf("expecting usage %q; got %q", want, got)
+	}
+
+	rest := "-q -n 5  -d 2s heap  f(1,  2)"
+	a, err := repl.ParseArgs(cmd.Args, strings.Fields(rest), rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Bool("-q") || a.Int("-n") != 5 || a.Duration("-d") != 2*time.Second ||
+		a.String("kind") != "heap" || a.String("expression") != "f(1,  2)" {
+		t.Errorf("wrong values parsing %q: %+v", rest, a)
+	}
+	if a, err = repl.ParseArgs(cmd.Args, []string{"cpu"}, "cpu"); err != nil ||
+		a.Has("expression") || a.Has("-n") {
+		t.Errorf("expecting only kind to be given; got %+v, %v", a, err)
+	}
+
+	errors := map[string]string{
+		"":          "kind: expecting one of cpu, heap",
+		"disk":      "kind: expecting one of cpu, heap; got disk",
+		"-x cpu":    "-x: unknown option",
+		"-n 0 cpu":  "-n: expecting an integer of at least 1; got 0",
+		"-d":        `-d: expecting a duration such as "10s"`,
+		"-- -q cpu": "kind: expecting one of cpu, heap; got -q",
+	}
+	for rest, want := range errors {
+		_, err := repl.ParseArgs(cmd.Args, strings.Fields(rest), rest)
+		if _, ok := err.(*repl.ArgError); !ok || err.Error() != want {
+			t.Errorf("%q: expecting ArgError %q; got %v", rest, want, err)
+		}
+	}
+	words := &repl.ArgSpec{Name: "word", Repeated: true, Optional: true}
+	a, err = repl.ParseArgs([]*repl.ArgSpec{words}, []string{"a", "b"}, "a b")
+	if err != nil || !reflect.DeepEqual(a.Strings("word"), []string{"a", "b"}) {
+		t.Errorf("expecting words a and b; got %+v, %v", a, err)
+	}
+	var shown bytes.Buffer
+	saveOut := repl.Out
+	repl.Out = &shown
+	if _, err := repl.GetInt("x", "count", 0, 0); err == nil ||
+		err.Error() != "count: expecting an integer; got x" {
+		t.Errorf("GetInt: expecting an ArgError; got %v", err)
+	}
+	if _, err := repl.GetUInt("5", "count", 10, 0); err == nil {
+		t.Errorf("GetUInt: expecting an ArgError")
+	}
+	repl.Out = saveOut
+	if shown.Len() > 0 {
+		t.Errorf("GetInt, GetUInt: expecting nothing to be shown; got %q",
+			shown.String())
+	}
+
+	env := repl.NewSession(nil, nil, nil).Env
+	completions := map[string][]string{
+		"show wi":  {"width"},
+		"source -": {"-q"},
+		":set max": {"maxgoroutines", "maxheap"},
+	}
+	for line, want := range completions {
+		_, got := repl.Complete(env, line, len(line))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Complete(%q): expecting %q; got %q", line, want, got)
+		}
+	}
+
+	for _, line := range []string{":", "  :"} {
+		_, got := repl.Complete(env, line, len(line))
+		found := false
+		for _, c := range got {
+			found = found || c == "help"
+		}
+		if !found {
+			t.Errorf("Complete(%q): expecting the commands; got %q", line, got)
+		}
+	}
+
+	out := repl.NewSession(nil, nil, nil).Capture("complete set max",
+		true).Output
+	if out != "set maxgoroutines\nset maxheap\n" {
+		t.Errorf("complete: expecting the completions of set max; got %q", out)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 Rocky Bernstein.
+// Capturing what gets written while processing a line
+
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// captureLock keeps more than one CaptureOutput at a time from
+// changing os.Stdout and os.Stderr.
+var captureLock sync.Mutex
+
+// CaptureOutput calls fn and returns what it wrote to os.Stdout and
+// os.Stderr. Those are process-wide, so only one call at a time
+// captures them, and anything else in the process that writes to them
+// meanwhile, such as an expression evaluated in another session, is
+// captured too. They are set back even if fn panics.
+func CaptureOutput(fn func()) (string, string) {
+	captureLock.Lock()
+	defer captureLock.Unlock()
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		fn()
+		return "", ""
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		fn()
+		return "", ""
+	}
+	saveStdout, saveStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+
+	// Drain the pipes as we go so that fn doesn't block on a full pipe.
+	drain := func(r *os.File, done chan<- string) {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		r.Close()
+		done <- buf.String()
+	}
+	// The channels are buffered so that the drains finish even if fn
+	// panics and nothing waits for them.
+	outDone, errDone := make(chan string, 1), make(chan string, 1)
+	go drain(outR, outDone)
+	go drain(errR, errDone)
+
+	func() {
+		defer func() {
+			os.Stdout, os.Stderr = saveStdout, saveStderr
+			outW.Close()
+			errW.Close()
+		}()
+		fn()
+	}()
+	return <-outDone, <-errDone
+}
+
+// Captured is what came of processing a line without showing it on
+// the session's output.
+type Captured struct {
+	// Result is the outcome of evaluating an expression; nil if
+	// the line was a REPL command.
+	Result *EvalResult
+
+	// Output is what was written to the session's output, for
+	// example by a REPL command, or the shown result if asked for.
+	Output string
+
+	// Stdout and Stderr are what was written to os.Stdout and
+	// os.Stderr, for example by fmt.Println in an expression.
+	Stdout string
+	Stderr string
+}
+
+// Capture runs the REPL command on line or else evaluates it,
+// capturing all output. If "show" is set, the outcome of an
+// evaluation is shown in Output as it would be at the prompt.
+func (s *Session) Capture(line string, show bool) *Captured {
+	var result *EvalResult
+	entry := len(s.Transcript)
+	c := s.capture(func() { result = s.processLine(line, show) })
+	c.Result = result
+	if entry < len(s.Transcript) {
+		s.Transcript[entry].Output += c.Stdout + c.Stderr
+	}
+	return c
+}
+
+// capture runs fn in session s, capturing all output.
+func (s *Session) capture(fn func()) *Captured {
+	var buf bytes.Buffer
+	c := &Captured{}
+	saveOut := s.Out
+	s.Out = &buf
+	defer func() { s.Out = saveOut }()
+	c.Stdout, c.Stderr = CaptureOutput(func() { s.run(fn) })
+	c.Output = buf.String()
+	return c
+}
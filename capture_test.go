@@ -0,0 +1,26 @@
+package repl_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rocky/go-fish"
+)
+
+func TestCaptureOutputPanic(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expecting the panic to be passed on")
+			}
+		}()
+		repl.CaptureOutput(func() { panic("oops") })
+	}()
+	if os.Stdout != stdout || os.Stderr != stderr {
+		t.Errorf("expecting os.Stdout and os.Stderr to be set back")
+	}
+	if out, _ := repl.CaptureOutput(func() { os.Stdout.WriteString("hi") }); out != "hi" {
+		t.Errorf("expecting to capture %q; got %q", "hi", out)
+	}
+}
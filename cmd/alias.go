@@ -0,0 +1,169 @@
+// Copyright 2014 Rocky Bernstein.
+// alias command
+
+package fishcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "alias"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: AliasCommand,
+		Summary: "make a word stand for a longer command",
+		Synopsis: `alias [*name* [*expansion*]]`,
+		Help: `Makes *name*, as the first word of a line, stand for *expansion*.
+Whatever follows *name* on the line is added to the end of it. See
+"macro" for aliases with parameters, and "unalias" to remove one.
+
+With just *name*, shows what it stands for. With no arguments, lists
+the aliases and macros, as "help aliases" does.
+
+Aliases defined at the prompt are saved in the init file,
+~/.gofishrc unless the -init option says otherwise, which is read
+when go-fish starts.
+`,
+		Examples: []string{
+			`alias ll packages -l`,
+			`alias p fmt.Println`,
+		},
+		SeeAlso: []string{"macro", "unalias"},
+
+		Min_args: 0,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// AliasCommand implements the command:
+//    alias [*name* [*expansion*]]
+// which defines or shows aliases.
+func AliasCommand(args []string) {
+	defineMacro(args, true)
+}
+
+// defineMacro defines, shows or lists aliases or macros for the
+// "alias" and "macro" commands.
+func defineMacro(args []string, alias bool) {
+	s := repl.Current
+	switch len(args) {
+	case 1:
+		listAliases()
+		return
+	case 2:
+		if m := s.Macros[args[1]]; m != nil {
+			repl.Msg("%s", m.Definition())
+		} else if cmd := repl.Aliases[args[1]]; cmd != "" {
+			repl.Msg("%s is a built-in alias for command %s", args[1], cmd)
+		} else {
+			repl.Errmsg("%s is not an alias or macro", args[1])
+		}
+		return
+	}
+	name := args[1]
+	body := strings.TrimSpace(repl.CmdRest[len(name):])
+	if err := s.DefineMacro(name, body, alias); err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	saveDefinition(name, s.Macros[name])
+}
+
+// saveDefinition updates the definition of alias or macro "name" in
+// the session's init file: the definition of m replaces any earlier
+// one, or if m is nil, the definition is removed. Nothing is saved
+// while a file is being sourced, which is how definitions are read
+// back.
+func saveDefinition(name string, m *repl.Macro) {
+	file := repl.Current.InitFile
+	if file == "" || repl.Current.SourceDepth > 0 {
+		return
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		repl.Errmsg("%s", err)
+		return
+	}
+	lines := []string{}
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	kept := []string{}
+	for _, line := range lines {
+		words := strings.Fields(line)
+		if len(words) < 2 {
+			kept = append(kept, line)
+			continue
+		}
+		// The command prefix may have changed since the line was
+		// saved, and prefixes can't have letters.
+		command := strings.TrimLeftFunc(words[0], func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		if (command == "alias" || command == "macro") && words[1] == name {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if m != nil {
+		kept = append(kept, m.Definition())
+	}
+	data = []byte(strings.Join(kept, "\n") + "\n")
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		repl.Errmsg("%s", err)
+	}
+}
+
+// listAliases shows the built-in aliases for commands, and the
+// aliases and macros defined in the session.
+func listAliases() {
+	builtins := [][]string{}
+	for alias, cmd := range repl.Aliases {
+		builtins = append(builtins, []string{alias, cmd})
+	}
+	aliases, macros := [][]string{}, [][]string{}
+	for name, m := range repl.Current.Macros {
+		if m.Alias {
+			aliases = append(aliases, []string{name, m.Body})
+		} else {
+			macros = append(macros, []string{name, m.Body})
+		}
+	}
+	for _, list := range []struct {
+		title string
+		rows  [][]string
+	}{
+		{"Built-in aliases", builtins},
+		{"Aliases", aliases},
+		{"Macros", macros},
+	} {
+		if len(list.rows) == 0 {
+			continue
+		}
+		repl.Section(list.title)
+		sort.Sort(byFirst(list.rows))
+		width := 0
+		for _, row := range list.rows {
+			if len(row[0]) > width {
+				width = len(row[0])
+			}
+		}
+		for _, row := range list.rows {
+			repl.Msg("  %-*s  %s", width, row[0], row[1])
+		}
+	}
+}
+
+type byFirst [][]string
+
+func (r byFirst) Len() int           { return len(r) }
+func (r byFirst) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byFirst) Less(i, j int) bool { return r[i][0] < r[j][0] }
@@ -0,0 +1,219 @@
+// Copyright 2014 Rocky Bernstein.
+// apropos command
+
+package fishcmd
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "apropos"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: AproposCommand,
+		Summary: "search the members of imported packages",
+		Synopsis: `apropos [-d] *pattern*`,
+		Help: `Searches the exported constants, functions, types, variables and
+methods of all imported packages for *pattern*. Results are grouped
+by package and show the kind of thing found and its signature.
+
+*pattern* can be:
+   /regexp/   a regular expression
+   Trim*      a glob pattern when it contains *, ? or [
+   trim       otherwise a case-insensitive substring
+
+Doc comments are searched too when documentation was embedded at
+build time. With -d, package source is consulted for doc comments
+as well; see "help doc".
+`,
+		Examples: []string{
+			`apropos Suffix`,
+			`apropos Trim*`,
+			`apropos /^Is[A-Z]/`,
+			`apropos -d whitespace`,
+		},
+		SeeAlso: []string{"doc", "packages"},
+
+		Min_args: 1,
+		Max_args: 2,
+	}
+	repl.AddToCategory("data", name)
+}
+
+// pkgMember is an exported constant, function, type, variable or
+// method of an imported package.
+type pkgMember struct {
+	kind    string   // "const", "func", "type", "var" or "method"
+	name    string   // methods are named Type.Method
+	sig     string   // Go declaration-like signature
+	names   []string // names to pass to repl.LookupDoc
+	methods int      // number of methods of a type, including *T's
+}
+
+// kindOrder is the order in which we list kinds of package members.
+var kindOrder = map[string]int{
+	"const": 0, "var": 1, "func": 2, "type": 3, "method": 4,
+}
+
+// pkgMembers returns the members of pkg sorted by kind and then name.
+func pkgMembers(pkg eval.Pkg) []pkgMember {
+	members := []pkgMember{}
+	for name, v := range pkg.Consts {
+		members = append(members, pkgMember{kind: "const", name: name,
+			sig:   fmt.Sprintf("const %s %s = %v", name, v.Type(), v.Interface()),
+			names: []string{name}})
+	}
+	for name, v := range pkg.Vars {
+		members = append(members, pkgMember{kind: "var", name: name,
+			sig:   fmt.Sprintf("var %s %s", name, v.Type().Elem()),
+			names: []string{name}})
+	}
+	for name, v := range pkg.Funcs {
+		members = append(members, pkgMember{kind: "func", name: name,
+			sig:   repl.FuncSignature(name, v.Type()),
+			names: []string{name}})
+	}
+	for name, t := range pkg.Types {
+		recv := t
+		if t.Kind() != reflect.Interface {
+			recv = reflect.PtrTo(t)
+		}
+		members = append(members, pkgMember{kind: "type", name: name,
+			sig:     fmt.Sprintf("type %s %s", name, t.Kind()),
+			names:   []string{name},
+			methods: recv.NumMethod()})
+		for i := 0; i < recv.NumMethod(); i++ {
+			m := recv.Method(i)
+			mrecv := recv
+			if tm, ok := t.MethodByName(m.Name); ok {
+				mrecv, m = t, tm
+			}
+			members = append(members, pkgMember{kind: "method",
+				name:  name + "." + m.Name,
+				sig:   repl.MethodSignature(mrecv, m),
+				names: []string{name, m.Name}})
+		}
+	}
+	sort.Sort(byKindName(members))
+	return members
+}
+
+type byKindName []pkgMember
+
+func (s byKindName) Len() int      { return len(s) }
+func (s byKindName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byKindName) Less(i, j int) bool {
+	if s[i].kind != s[j].kind {
+		return kindOrder[s[i].kind] < kindOrder[s[j].kind]
+	}
+	return s[i].name < s[j].name
+}
+
+// makeMatcher turns pattern into a function that reports whether a
+// string matches. See "help apropos" for the pattern forms.
+func makeMatcher(pattern string) (func(string) bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") &&
+		strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+		return func(s string) bool {
+			matched, _ := path.Match(pattern, s)
+			return matched
+		}, nil
+	}
+	lower := strings.ToLower(pattern)
+	return func(s string) bool {
+		return strings.Contains(strings.ToLower(s), lower)
+	}, nil
+}
+
+// memberMatches reports whether match accepts the name of member m. A
+// method matches on either its own name or on Type.Method.
+func memberMatches(match func(string) bool, m pkgMember) bool {
+	if match(m.name) {
+		return true
+	}
+	if m.kind == "method" {
+		return match(m.names[1])
+	}
+	return false
+}
+
+// memberDoc returns the doc text for member m of the package with
+// import path "path". Unless useSource is set, only documentation
+// embedded at build time is consulted.
+func memberDoc(path string, m pkgMember, useSource bool) string {
+	if !useSource {
+		return repl.Docs[path+"."+m.name]
+	}
+	if info, err := repl.LookupDoc(path, m.names...); err == nil {
+		return info.Doc
+	}
+	return ""
+}
+
+// AproposCommand implements the command:
+//    apropos [-d] *pattern*
+// which searches package members by name and doc text.
+func AproposCommand(args []string) {
+	useSource := false
+	pattern := args[1]
+	if len(args) == 3 {
+		if args[1] != "-d" {
+			repl.Errmsg("Unknown option %s; expecting -d", args[1])
+			return
+		}
+		useSource = true
+		pattern = args[2]
+	}
+	match, err := makeMatcher(pattern)
+	if err != nil {
+		repl.Errmsg("Bad pattern %s: %s", pattern, err)
+		return
+	}
+	searchDocs := useSource || len(repl.Docs) > 0
+
+	pkgNames := []string{}
+	for name := range repl.Env.Pkgs {
+		pkgNames = append(pkgNames, name)
+	}
+	sort.Strings(pkgNames)
+	found := 0
+	for _, pkgName := range pkgNames {
+		pkg := repl.Env.Pkgs[pkgName]
+		hits := []pkgMember{}
+		for _, m := range pkgMembers(pkg) {
+			if memberMatches(match, m) ||
+				(searchDocs && match(memberDoc(pkg.Path, m, useSource))) {
+				hits = append(hits, m)
+			}
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		repl.Section("%s (\"%s\"):", pkgName, pkg.Path)
+		for _, m := range hits {
+			repl.Msg("  %-6s %s", m.kind, m.sig)
+		}
+		found += len(hits)
+	}
+	if found == 0 {
+		repl.Msg("Nothing found for %s", pattern)
+	}
+}
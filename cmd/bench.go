@@ -0,0 +1,224 @@
+// Copyright 2014 Rocky Bernstein.
+// bench command
+
+package fishcmd
+
+import (
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "bench"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: BenchCommand,
+		Summary: "benchmark an expression, or compare two",
+		Synopsis: `bench *expression* [;; *expression*]`,
+		Help: `Benchmarks *expression* the way "go test -bench" does: it is
+evaluated b.N times, with N raised until the run takes about a
+second, and then time, bytes allocated and allocations per evaluation
+are shown.
+
+Give two expressions separated by ";;" to compare them side by side.
+
+The expression is type checked once, so what is measured is
+evaluation by the go-fish interpreter, which is much slower than
+compiled Go. Comparisons are fairer than absolute numbers.
+`,
+		Examples: []string{
+			`bench strings.Fields("a b c")`,
+			`bench fmt.Sprint(42) ;; strconv.Itoa(42)`,
+		},
+		SeeAlso: []string{"time", "profile"},
+
+		Min_args: 1,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// BenchCommand implements the command:
+//    bench *expression* [;; *expression*]
+// which benchmarks the evaluation of one expression or compares two.
+func BenchCommand(args []string) {
+	exprs, ok := checkedExprs(repl.CmdRest)
+	if !ok {
+		return
+	}
+	results := []testing.BenchmarkResult{}
+	for _, e := range exprs {
+		var r testing.BenchmarkResult
+		err := repl.Current.WithLimits(func() error {
+			var err error
+			r = testing.Benchmark(func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N && err == nil; i++ {
+					err = e.eval()
+				}
+			})
+			return err
+		})
+		if err != nil {
+			evalErrmsg(err)
+			return
+		}
+		results = append(results, r)
+	}
+
+	rows := [][]string{{"", "ns/op", "B/op", "allocs/op", "N"}}
+	for i, r := range results {
+		rows = append(rows, []string{exprs[i].src,
+			fmt.Sprintf("%d", r.NsPerOp()),
+			fmt.Sprintf("%d", r.AllocedBytesPerOp()),
+			fmt.Sprintf("%d", r.AllocsPerOp()),
+			fmt.Sprintf("%d", r.N)})
+	}
+	printTable(rows)
+	if len(results) == 2 {
+		printRatio(exprs, float64(results[0].NsPerOp()),
+			float64(results[1].NsPerOp()))
+	}
+}
+
+// checkedExpr is an expression that has been parsed and type checked
+// so that it can be evaluated over and over.
+type checkedExpr struct {
+	src   string
+	ctx   *eval.Ctx
+	cexpr eval.Expr
+	env   *eval.Env
+}
+
+func (e *checkedExpr) eval() error {
+	_, _, err := eval.EvalExpr(e.ctx, e.cexpr, e.env)
+	return err
+}
+
+// evalErrmsg reports err from evaluating an expression.
+func evalErrmsg(err error) {
+	if _, ok := err.(*repl.LimitError); ok {
+		repl.Errmsg("%s", err)
+		return
+	}
+	repl.Errmsg("eval error: %s", err)
+}
+
+// checkedExprs parses and type checks the expression, or the two
+// expressions separated by ";;", in line. Errors are reported, and
+// false is returned if there were any.
+func checkedExprs(line string) ([]*checkedExpr, bool) {
+	srcs, err := splitExprs(line)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return nil, false
+	}
+	exprs := []*checkedExpr{}
+	for _, src := range srcs {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			if pair := eval.FormatErrorPos(src, err.Error()); len(pair) == 2 {
+				repl.Msg(pair[0])
+				repl.Msg(pair[1])
+			}
+			repl.Errmsg("parse error: %s", err)
+			return nil, false
+		}
+		if err := repl.Current.CheckAllowed(expr); err != nil {
+			repl.Errmsg("%s", err)
+			return nil, false
+		}
+		ctx := &eval.Ctx{src}
+		cexpr, errs := eval.CheckExpr(ctx, expr, repl.Env)
+		if len(errs) != 0 {
+			for _, cerr := range errs {
+				repl.Errmsg("%s", cerr)
+			}
+			return nil, false
+		}
+		exprs = append(exprs, &checkedExpr{src, ctx, cexpr, repl.Env})
+	}
+	return exprs, true
+}
+
+// splitExprs splits line at a ";;" that is not inside a string or
+// comment. There can be at most one.
+func splitExprs(line string) ([]string, error) {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(line))
+	s.Init(file, []byte(line), nil, scanner.ScanComments)
+	splits := []int{}
+	lastSemi := -1
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		offset := file.Offset(pos)
+		if tok == token.SEMICOLON && lit == ";" {
+			if lastSemi >= 0 && lastSemi == offset-1 {
+				splits = append(splits, lastSemi)
+				lastSemi = -1
+				continue
+			}
+			lastSemi = offset
+		} else {
+			lastSemi = -1
+		}
+	}
+	if len(splits) == 0 {
+		return []string{line}, nil
+	}
+	if len(splits) > 1 {
+		return nil, fmt.Errorf("expecting at most two expressions separated by \";;\"")
+	}
+	first, second := strings.TrimSpace(line[:splits[0]]),
+		strings.TrimSpace(line[splits[0]+2:])
+	if first == "" || second == "" {
+		return nil, fmt.Errorf("expecting an expression on each side of \";;\"")
+	}
+	return []string{first, second}, nil
+}
+
+// printTable shows rows in columns, the first left-aligned and the
+// others right-aligned.
+func printTable(rows [][]string) {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for _, row := range rows {
+		line := fmt.Sprintf("%-*s", widths[0], row[0])
+		for i, cell := range row[1:] {
+			line += fmt.Sprintf("  %*s", widths[i+1], cell)
+		}
+		repl.Msg("%s", line)
+	}
+}
+
+// printRatio says how much faster or slower the second of two
+// expressions is, given the time each took.
+func printRatio(exprs []*checkedExpr, first, second float64) {
+	if first <= 0 || second <= 0 {
+		return
+	}
+	if second < first {
+		repl.Msg("%s is %.2fx faster than %s", exprs[1].src, first/second,
+			exprs[0].src)
+	} else {
+		repl.Msg("%s is %.2fx slower than %s", exprs[1].src, second/first,
+			exprs[0].src)
+	}
+}
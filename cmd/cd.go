@@ -0,0 +1,70 @@
+// Copyright 2014 Rocky Bernstein.
+// cd command
+
+package fishcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "cd"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: CdCommand,
+		Summary: "change the working directory",
+		Synopsis: `cd [*directory* | -]`,
+		Help: `Changes the working directory of go-fish, and so of the commands that
+"shell" runs and of files opened by relative name, to *directory*.
+Without a directory, changes to your home directory, and with "-",
+back to the directory before the last "cd". A leading "~" stands for
+your home directory.
+`,
+		SeeAlso: []string{"pwd"},
+
+		Min_args: 0,
+		Max_args: 1,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// previousDir is the working directory before the last cd.
+var previousDir string
+
+// CdCommand implements the command:
+//    cd [*directory* | -]
+// which changes the working directory.
+func CdCommand(args []string) {
+	home := os.Getenv("HOME")
+	dir := home
+	if len(args) == 2 {
+		dir = args[1]
+	}
+	switch {
+	case dir == "-":
+		if previousDir == "" {
+			repl.Errmsg("No previous directory")
+			return
+		}
+		dir = previousDir
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		dir = filepath.Join(home, dir[1:])
+	}
+	if dir == "" {
+		repl.Errmsg("HOME is not set; expecting a directory")
+		return
+	}
+	cwd, err := os.Getwd()
+	if err == nil {
+		err = os.Chdir(dir)
+	}
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	previousDir = cwd
+	PwdCommand(args[:1])
+}
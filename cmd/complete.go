@@ -0,0 +1,45 @@
+// Copyright 2014 Rocky Bernstein.
+// complete command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "complete"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: CompleteCommand,
+		Summary: "list the ways to complete a line",
+		Synopsis: `complete *text*`,
+		Help: `Lists the ways that the last word of *text* can be completed, one
+line each, as Tab would in the web and Jupyter front-ends. Names in
+Go expressions are completed, and so are command names and the
+options, settings, file names and other values that commands take.
+This is for a terminal that doesn't complete with Tab, and for
+programs that drive go-fish.
+`,
+		Examples: []string{
+			`complete strings.Tr`,
+			`complete set max`,
+		},
+		SeeAlso: []string{"help"},
+
+		Min_args: 1,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// CompleteCommand implements the command:
+//    complete *text*
+// which lists the completions of text.
+func CompleteCommand(args []string) {
+	text := repl.CmdRest
+	start, candidates := repl.Complete(repl.Env, text, len(text))
+	for _, c := range candidates {
+		repl.Msg("%s%s", text[:start], c)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 Rocky Bernstein.
+// doc command
+
+package fishcmd
+
+import (
+	"bytes"
+	"go/doc"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "doc"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: DocCommand,
+		Summary: "show Go documentation",
+		Synopsis: `doc *package* | *package*.*symbol* | *expression*.*method*`,
+		Help: `Shows Go documentation: declarations, doc comments and examples.
+
+*package* is either a package name as seen in "packages", or an import
+path. Documentation is read with go/doc from package source found in
+GOROOT, GOPATH or the module cache. If the source isn't around, we
+fall back to documentation embedded when go-fish was built; see the
+-docs option of make_env.
+`,
+		Examples: []string{
+			`doc strings`,
+			`doc strings.Fields`,
+			`doc strings.Reader.Len`,
+			`doc os.Stdout.Write`,
+		},
+		SeeAlso: []string{"whatis", "apropos"},
+
+		Min_args: 1,
+		Max_args: 1,
+	}
+	repl.AddToCategory("data", name)
+}
+
+// DocCommand implements the command:
+//    doc *package* | *package*.*symbol* | *expression*.*method*
+// which shows Go documentation.
+func DocCommand(args []string) {
+	path, names, err := repl.ResolveDocName(repl.Env, args[1])
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	info, err := repl.LookupDoc(path, names...)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	printDoc(info)
+}
+
+func printDoc(info *repl.DocInfo) {
+	repl.Section("%s", info.Decl)
+	if info.Doc != "" {
+		var buf bytes.Buffer
+		doc.ToText(&buf, info.Doc, "", "    ", repl.Maxwidth)
+		repl.Msg("%s", strings.TrimRight(buf.String(), "\n"))
+	}
+	for _, ex := range info.Examples {
+		if ex.Name == "" {
+			repl.Section("Example:")
+		} else {
+			repl.Section("Example %s:", ex.Name)
+		}
+		repl.Msg("%s", ex.Code)
+		if ex.Output != "" {
+			repl.Msg("Output:\n%s", strings.TrimRight(ex.Output, "\n"))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2014 Rocky Bernstein.
+// gc command
+
+package fishcmd
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "gc"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: GCCommand,
+		Summary: "run a garbage collection",
+		Synopsis: `gc`,
+		Help: `Runs a garbage collection now, and shows how long it took and how
+much heap was freed.
+`,
+		SeeAlso: []string{"gcpercent", "memstats"},
+
+		Min_args: 0,
+		Max_args: 0,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// GCCommand implements the command:
+//    gc
+// which runs and times a garbage collection.
+func GCCommand(args []string) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	runtime.GC()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	repl.Msg("GC took %s; heap %s -> %s, %d objects freed", elapsed,
+		repl.FormatBytes(int64(before.HeapAlloc)), repl.FormatBytes(int64(after.HeapAlloc)),
+		int64(after.Frees-before.Frees))
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 Rocky Bernstein.
+// gcpercent command
+
+package fishcmd
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "gcpercent"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: GCPercentCommand,
+		Summary: "show or set the garbage collection percentage",
+		Synopsis: `gcpercent [*percent* | off]`,
+		Help: `Shows, or sets, how much the heap may grow, as a percentage of the
+live heap after the last collection, before the next garbage
+collection starts. "off" turns garbage collection off. See
+runtime/debug.SetGCPercent and the GOGC environment variable.
+`,
+		SeeAlso: []string{"gc", "memstats"},
+
+		Min_args: 0,
+		Max_args: 1,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// gcPercentString shows a GC percent as GOGC would be set.
+func gcPercentString(percent int) string {
+	if percent < 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d%%", percent)
+}
+
+// GCPercentCommand implements the command:
+//    gcpercent [*percent* | off]
+// which shows or sets runtime/debug.SetGCPercent.
+func GCPercentCommand(args []string) {
+	if len(args) == 1 {
+		// The only way to find the setting is to change it.
+		percent := debug.SetGCPercent(100)
+		debug.SetGCPercent(percent)
+		repl.Msg("GC percent is %s", gcPercentString(percent))
+		return
+	}
+	percent := -1
+	if args[1] != "off" {
+		var err error
+		if percent, err = repl.GetInt(args[1], "GC percent", 0, 0); err != nil {
+			repl.Errmsg("%s", err)
+			return
+		}
+	}
+	old := debug.SetGCPercent(percent)
+	repl.Msg("GC percent was %s; now %s", gcPercentString(old),
+		gcPercentString(percent))
+}
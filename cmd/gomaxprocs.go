@@ -0,0 +1,40 @@
+// Copyright 2014 Rocky Bernstein.
+// gomaxprocs command
+
+package fishcmd
+
+import (
+	"runtime"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "gomaxprocs"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: GomaxprocsCommand,
+		Summary: "show or set GOMAXPROCS",
+		Help: `Shows, or with *n* sets, the number of CPUs that can run Go code at
+the same time; see runtime.GOMAXPROCS.
+`,
+
+		Args: []*repl.ArgSpec{
+			{Name: "n", Type: repl.IntArg, Optional: true, Min: 1},
+		},
+	}
+	repl.AddToCategory("running", name)
+}
+
+// GomaxprocsCommand implements the command:
+//    gomaxprocs [*n*]
+// which shows or sets runtime.GOMAXPROCS.
+func GomaxprocsCommand(args []string) {
+	if !repl.CmdArgs.Has("n") {
+		repl.Msg("GOMAXPROCS is %d; this machine has %d CPUs",
+			runtime.GOMAXPROCS(0), runtime.NumCPU())
+		return
+	}
+	n := repl.CmdArgs.Int("n")
+	old := runtime.GOMAXPROCS(n)
+	repl.Msg("GOMAXPROCS was %d; now %d", old, n)
+}
@@ -0,0 +1,187 @@
+// Copyright 2014 Rocky Bernstein.
+// goroutines command
+
+package fishcmd
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "goroutines"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: GoroutinesCommand,
+		Summary: "list the goroutines of go-fish",
+		Synopsis: `goroutines [-v]`,
+		Help: `Lists the goroutines in go-fish, grouped by where they are: those with
+the same stack trace and state are shown together. For each group we
+show how many goroutines are in it, their state and the innermost
+function outside of the Go runtime.
+
+With -v, the goroutine numbers and whole stack trace of each group are
+shown too. Function arguments and program counter offsets are left
+out, since they keep otherwise identical stacks from being grouped.
+`,
+		SeeAlso: []string{"memstats"},
+
+		Min_args: 0,
+		Max_args: 1,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// goroutine is a goroutine from a runtime.Stack trace.
+type goroutine struct {
+	id     int
+	state  string
+	frames []stackFrame
+}
+
+// stackFrame is a function and where in it a goroutine is.
+type stackFrame struct {
+	fn, pos string
+}
+
+// goroutineGroup is goroutines with the same state and stack.
+type goroutineGroup struct {
+	state  string
+	frames []stackFrame
+	ids    []int
+}
+
+type byGroupSize []*goroutineGroup
+
+func (g byGroupSize) Len() int      { return len(g) }
+func (g byGroupSize) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g byGroupSize) Less(i, j int) bool {
+	if len(g[i].ids) != len(g[j].ids) {
+		return len(g[i].ids) > len(g[j].ids)
+	}
+	return g[i].ids[0] < g[j].ids[0]
+}
+
+// GoroutinesCommand implements the command:
+//    goroutines [-v]
+// which lists goroutines grouped by stack trace.
+func GoroutinesCommand(args []string) {
+	verbose := false
+	if len(args) == 2 {
+		if args[1] != "-v" {
+			repl.Errmsg("Expecting -v; got %s", args[1])
+			return
+		}
+		verbose = true
+	}
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	goroutines := parseStacks(string(buf))
+
+	groups := []*goroutineGroup{}
+	byKey := make(map[string]*goroutineGroup)
+	for _, g := range goroutines {
+		key := g.state
+		for _, f := range g.frames {
+			key += "\n" + f.fn + " " + f.pos
+		}
+		group := byKey[key]
+		if group == nil {
+			group = &goroutineGroup{state: g.state, frames: g.frames}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.ids = append(group.ids, g.id)
+	}
+	sort.Sort(byGroupSize(groups))
+
+	repl.Section("%d goroutines in %d groups", len(goroutines), len(groups))
+	for _, group := range groups {
+		if !verbose {
+			repl.Msg("%5d  [%s]  %s", len(group.ids), group.state,
+				topFrame(group.frames))
+			continue
+		}
+		ids := []string{}
+		for _, id := range group.ids {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		repl.Section("%d [%s]: goroutine %s", len(group.ids), group.state,
+			strings.Join(ids, ", "))
+		for _, f := range group.frames {
+			repl.Msg("    %s", f.fn)
+			if f.pos != "" {
+				repl.Msg("        %s", f.pos)
+			}
+		}
+	}
+}
+
+// topFrame returns the innermost function outside of the runtime.
+func topFrame(frames []stackFrame) string {
+	for _, f := range frames {
+		if !strings.HasPrefix(f.fn, "runtime.") {
+			return f.fn
+		}
+	}
+	if len(frames) > 0 {
+		return frames[0].fn
+	}
+	return "?"
+}
+
+// parseStacks parses the output of runtime.Stack for all goroutines.
+func parseStacks(trace string) []*goroutine {
+	goroutines := []*goroutine{}
+	var g *goroutine
+	for _, line := range strings.Split(trace, "\n") {
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			// goroutine 7 [chan receive, 5 minutes]:
+			g = &goroutine{}
+			fields := strings.SplitN(line[len("goroutine "):], " ", 2)
+			g.id, _ = strconv.Atoi(fields[0])
+			if len(fields) == 2 {
+				state := strings.Trim(fields[1], "[]:")
+				if i := strings.Index(state, ","); i >= 0 {
+					state = state[:i]
+				}
+				g.state = state
+			}
+			goroutines = append(goroutines, g)
+		case g == nil || line == "":
+		case strings.HasPrefix(line, "\t"):
+			// \t/path/to/file.go:123 +0x1d
+			if len(g.frames) > 0 {
+				pos := strings.TrimSpace(line)
+				if i := strings.LastIndex(pos, " +0x"); i >= 0 {
+					pos = pos[:i]
+				}
+				g.frames[len(g.frames)-1].pos = pos
+			}
+		default:
+			fn := line
+			if strings.HasPrefix(fn, "created by ") {
+				if i := strings.Index(fn, " in goroutine "); i >= 0 {
+					fn = fn[:i]
+				}
+			} else if strings.HasSuffix(fn, ")") {
+				if i := strings.LastIndex(fn, "("); i > 0 {
+					fn = fn[:i]
+				}
+			}
+			g.frames = append(g.frames, stackFrame{fn: fn})
+		}
+	}
+	return goroutines
+}
@@ -13,80 +13,154 @@ func init() {
 	name := "help"
 	repl.Cmds[name] = &repl.CmdInfo{
 		Fn: HelpCommand,
-		Help: `help [*command* | * ]
+		Summary: "show help on commands and topics",
+		Help: `To evaluate an expression, just type the expression.
 
-To evaluate an expression, just type the expression.
+A line starting with ":" is a gofish command, as in ":help". The ":"
+can usually be left out: if the first word of the line is a gofish
+command, then that takes precendence, unless the word is also a
+variable or other Go name and the line is a Go expression. See "show
+cmdprefix" and "show legacy" to change this.
 
-If the first word of the line starts with a gofish command, then that
-takes precendence. For example, "help" is a gofish command.
+A command can be abbreviated to any prefix of its name that no other
+command shares, as in "hist" for "history", unless the line is Go that
+makes sense on its own.
 
-Typing "help *" will print a list of available gofish commands.
-
-When "help and an argument is given, if it is '*' a list of repl
-commands is shown. Otherwise the argument is checked to see if it is
-command name. For example 'help quit' gives help on the 'quit'
-debugger command.
+Arguments of commands are separated by spaces. Put an argument with
+spaces in single or double quotes, as in 'source "My File.fish"', or
+put a backslash before a space or quote. Commands that take Go
+expressions, such as "whatis", take the rest of the line as it is.
 
+With no argument, "help" lists the commands by category. *topic* is a
+command, a category, "categories" for the list of them, "aliases" for
+aliases and macros, or "*" for the names of all commands. With -s,
+"help" lists the commands whose help mentions *term*.
 `,
+		Examples: []string{
+			`help`,
+			`help quit`,
+			`help running`,
+			`help -s heap`,
+		},
 
-		Min_args: 0,
-		Max_args: 2,
+		Args: []*repl.ArgSpec{
+			{Name: "-s", ValueName: "term"},
+			{Name: "topic", Optional: true},
+		},
 	}
 	repl.AddToCategory("support", name)
-	repl.AddAlias("?", name)
-	// Down the line we'll have abbrevs
-	repl.AddAlias("h", name)
+	repl.MustAddAlias("?", name)
+	// "h" would otherwise be ambiguous with "history"
+	repl.MustAddAlias("h", name)
 }
 
 // HelpCommand implements the command:
-//    help [*name* |* ]
+//    help [-s *term*] [*topic*]
 // which gives help.
 func HelpCommand(args []string) {
-	if len(args) == 1 {
-		repl.Msg(repl.Cmds["help"].Help)
-	} else {
-		what := args[1]
-		cmd := repl.LookupCmd(what)
-		if what == "*" {
-			var names []string
-			for k, _ := range repl.Cmds {
-				names = append(names, k)
-			}
-			repl.Section("All command names:")
-			sort.Strings(names)
-			opts := columnize.DefaultOptions()
-			opts.LinePrefix  = "  "
-			opts.DisplayWidth = repl.Maxwidth
-			mems := strings.TrimRight(columnize.Columnize(names, opts),
-				"\n")
-			repl.Msg(mems)
-		} else if what == "categories" {
-			repl.Section("Categories")
-			for k, _ := range repl.Categories {
-				repl.Msg("\t %s", k)
-			}
-		} else if info := repl.Cmds[cmd]; info != nil {
-			// if len(args) > 2 {
-			// 	if info.SubcmdMgr != nil {
-			// 		repl.HelpSubCommand(info.SubcmdMgr, args)
-			// 		return
-			// 	}
-			// }
-			repl.Msg(info.Help)
-			if len(info.Aliases) > 0 {
-				repl.Msg("Aliases: %s",
-					strings.Join(info.Aliases, ", "))
+	if repl.CmdArgs.Has("-s") {
+		term := repl.CmdArgs.String("-s")
+		if repl.CmdArgs.Has("topic") {
+			repl.Errmsg("Expecting either -s *term* or a topic, not both")
+			return
+		}
+		names := repl.SearchHelp(term)
+		if len(names) == 0 {
+			repl.Errmsg("No help mentions %s", term)
+			return
+		}
+		repl.Section("Commands whose help mentions %s:", term)
+		listCommands(names)
+		return
+	}
+	what := repl.CmdArgs.String("topic")
+	cmd := repl.LookupCmd(what)
+	if what == "" {
+		helpOverview()
+	} else if what == "*" {
+		var names []string
+		for k, _ := range repl.Cmds {
+			names = append(names, k)
+		}
+		repl.Section("All command names:")
+		sort.Strings(names)
+		opts := columnize.DefaultOptions()
+		opts.LinePrefix  = "  "
+		opts.DisplayWidth = repl.Maxwidth
+		mems := strings.TrimRight(columnize.Columnize(names, opts),
+			"\n")
+		repl.Msg(mems)
+	} else if what == "aliases" {
+		listAliases()
+	} else if what == "categories" {
+		repl.Section("Categories:")
+		names := repl.CategoryNames()
+		width := 0
+		for _, name := range names {
+			if len(name) > width {
+				width = len(name)
 			}
-		} else if cmds := repl.Categories[what]; len(cmds) > 0 {
-			repl.Section("Commands in class: %s", what)
-			sort.Strings(cmds)
-			opts := columnize.DefaultOptions()
-			opts.DisplayWidth = repl.Maxwidth
-			mems := strings.TrimRight(columnize.Columnize(cmds, opts),
-				"\n")
-			repl.Msg(mems)
-		} else {
-			repl.Errmsg("Can't find help for %s", what)
 		}
+		for _, name := range names {
+			repl.Msg("  %-*s  %s", width, name, repl.CategoryHelp[name])
+		}
+	} else if info := repl.Cmds[cmd]; info != nil {
+		repl.Msg("%s", info.HelpText(cmd))
+	} else if cmds := repl.Categories[what]; len(cmds) > 0 {
+		listCategory(what, cmds)
+	} else if names := repl.CmdCandidates(what); len(names) > 1 {
+		repl.Errmsg("Ambiguous command %s: %s", what,
+			strings.Join(names, ", "))
+	} else {
+		repl.Errmsg("Can't find help for %s", what)
+	}
+}
+
+// helpOverview lists the commands by category, for "help" with no
+// arguments.
+func helpOverview() {
+	for _, category := range repl.CategoryNames() {
+		listCategory(category, repl.Categories[category])
+		repl.Msg("")
+	}
+	others := []string{}
+	for name, cmd := range repl.Cmds {
+		if cmd.Category == "" {
+			others = append(others, name)
+		}
+	}
+	if len(others) > 0 {
+		repl.Section("Other commands:")
+		listCommands(others)
+		repl.Msg("")
+	}
+	repl.Msg("%s", repl.Markup(`Type "help *command*" for help on a `+
+		`command, "help help" for how lines are run, and "help -s *term*" `+
+		`to search help.`))
+}
+
+// listCategory lists the commands in category "category" with what
+// they do.
+func listCategory(category string, cmds []string) {
+	if title := repl.CategoryHelp[category]; title != "" {
+		repl.Section("%s (%s):", title, category)
+	} else {
+		repl.Section("Commands in category %s:", category)
+	}
+	listCommands(cmds)
+}
+
+// listCommands lists commands "names" with their summaries, in order.
+func listCommands(names []string) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		repl.Msg("  %-*s  %s", width, name, repl.Cmds[name].Summary)
 	}
 }
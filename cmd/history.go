@@ -0,0 +1,40 @@
+// Copyright 2014 Rocky Bernstein.
+// history command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "history"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: HistoryCommand,
+		Summary: "show the lines entered in this session",
+		Help: `Shows the lines entered in this session, most recent last. If
+*count* is given, only the last *count* lines are shown.
+`,
+
+		Args: []*repl.ArgSpec{
+			{Name: "count", Type: repl.IntArg, Optional: true},
+		},
+	}
+	repl.AddToCategory("support", name)
+}
+
+// HistoryCommand implements the command:
+//    history [*count*]
+// which shows the lines entered in the current session.
+func HistoryCommand(args []string) {
+	history := repl.Current.History
+	start := 0
+	if repl.CmdArgs.Has("count") {
+		if count := repl.CmdArgs.Int("count"); count < len(history) {
+			start = len(history) - count
+		}
+	}
+	for i := start; i < len(history); i++ {
+		repl.Msg("%5d  %s", i+1, history[i])
+	}
+}
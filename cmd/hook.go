@@ -0,0 +1,114 @@
+// Copyright 2014 Rocky Bernstein.
+// hook command
+
+package fishcmd
+
+import (
+	"fmt"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "hook"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: HookCommand,
+		Summary: "do something whenever a line is entered or evaluated",
+		Help: `With no arguments, lists the hooks of this session: things done
+whenever a line is entered or evaluated.
+
+"hook elapsed" shows how long each evaluation takes, and "hook json"
+shows strings holding JSON objects and arrays indented.
+
+Otherwise, *line* is entered as if at the prompt whenever this
+happens:
+
+   input   a line is entered
+   before  an expression is about to be evaluated
+   after   an expression has been evaluated and its value shown
+   exit    go-fish is leaving this session
+
+Lines that hooks enter don't set off hooks themselves. The value of
+the last expression is results[len(results)-1].
+
+With -d, removes the hook named *name*.
+`,
+		Examples: []string{
+			`hook elapsed`,
+			`hook after fmt.Println(time.Now())`,
+			`hook exit save session.fish`,
+			`hook -d after1`,
+		},
+
+		Args: []*repl.ArgSpec{
+			{Name: "-d", ValueName: "name"},
+			{Name: "event", Type: repl.EnumArg, Optional: true,
+				Values: []string{"elapsed", "json", "input", "before", "after",
+					"exit"}},
+			{Name: "line", Type: repl.ExprArg, Optional: true},
+		},
+		RawArgs: true,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// HookCommand implements the command:
+//    hook [-d *name*] [elapsed | json | *event* *line*]
+// which lists, adds or removes hooks.
+func HookCommand(args []string) {
+	s := repl.Current
+	event, line := repl.CmdArgs.String("event"), repl.CmdArgs.String("line")
+	switch {
+	case repl.CmdArgs.Has("-d"):
+		if event != "" {
+			repl.Errmsg("Expecting nothing after the name of the hook to remove")
+			return
+		}
+		if err := s.RemoveHook(repl.CmdArgs.String("-d")); err != nil {
+			repl.Errmsg("%s", err)
+		}
+	case event == "":
+		if len(s.Hooks) == 0 {
+			repl.Msg("No hooks")
+			return
+		}
+		repl.Section("Hooks:")
+		for _, h := range s.Hooks {
+			repl.Msg("  %-10s %s", h.Name, h.Description)
+		}
+	case repl.BuiltinHooks[event] != nil:
+		if line != "" {
+			repl.Errmsg("Expecting nothing after hook %s", event)
+			return
+		}
+		if err := s.AddHook(repl.BuiltinHooks[event]); err != nil {
+			repl.Errmsg("%s", err)
+		}
+	case line == "":
+		repl.Errmsg("Expecting a line to enter on %s", event)
+	default:
+		name := ""
+		for n := 1; name == "" || hookNamed(s, name); n++ {
+			name = fmt.Sprintf("%s%d", event, n)
+		}
+		h, err := repl.LineHook(name, event, line)
+		if err == nil {
+			err = s.AddHook(h)
+		}
+		if err != nil {
+			repl.Errmsg("%s", err)
+			return
+		}
+		repl.Msg("Hook %s added", name)
+	}
+}
+
+// hookNamed reports whether session s has a hook named "name".
+func hookNamed(s *repl.Session, name string) bool {
+	for _, h := range s.Hooks {
+		if h.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 Rocky Bernstein.
+// import command
+
+package fishcmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "import"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: ImportCommand,
+		Summary: "make a package available under a name",
+		Synopsis: `import [*name*] *import-path*`,
+		Help: `Makes a package compiled into go-fish available under *name*, or under
+its usual name if *name* is not given. Use this to pick your own name
+for a package whose name collides with another package's name.
+
+The import path may be quoted as in Go source. See "packages" for the
+packages available, including name conflicts and aliases.
+`,
+		Examples: []string{
+			`import crand "crypto/rand"`,
+			`import htemplate html/template`,
+		},
+		SeeAlso: []string{"packages"},
+
+		Min_args: 1,
+		Max_args: 2,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// ImportCommand implements the command:
+//    import [*name*] *import-path*
+// which makes a package available under a name.
+func ImportCommand(args []string) {
+	name, path := "", args[len(args)-1]
+	if len(args) == 3 {
+		name = args[1]
+	}
+	if strings.HasPrefix(path, "\"") || strings.HasPrefix(path, "`") {
+		unquoted, err := strconv.Unquote(path)
+		if err != nil {
+			repl.Errmsg("Bad import path %s: %s", path, err)
+			return
+		}
+		path = unquoted
+	}
+	if err := repl.ImportPackage(repl.Env, name, path); err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	if name == "" {
+		name = repl.Packages[path].Name
+		if alias, ok := repl.PkgAliases[path]; ok {
+			name = alias
+		}
+	}
+	repl.Msg("Package \"%s\" imported as %s", path, name)
+}
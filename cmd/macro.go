@@ -0,0 +1,44 @@
+// Copyright 2014 Rocky Bernstein.
+// macro command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "macro"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: MacroCommand,
+		Summary: "define a macro with parameters",
+		Synopsis: `macro [*name* [*body*]]`,
+		Help: `Makes *name*, as the first word of a line, stand for *body*, with $1
+through $9 in *body* replaced by the words after *name* and $* by all
+of them. The result is run as a command or evaluated like anything
+else entered. Remove a macro with "unalias".
+
+With just *name*, shows its definition. With no arguments, lists the
+aliases and macros, as "help aliases" does. Like aliases, macros
+defined at the prompt are saved in the init file. After "macro sq
+$1*$1", "sq 12" is the same as entering 12*12.
+`,
+		Examples: []string{
+			`macro sq $1*$1`,
+			`macro hex fmt.Sprintf("%x", $1)`,
+		},
+		SeeAlso: []string{"alias", "unalias"},
+
+		Min_args: 0,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// MacroCommand implements the command:
+//    macro [*name* [*body*]]
+// which defines or shows macros.
+func MacroCommand(args []string) {
+	defineMacro(args, false)
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 Rocky Bernstein.
+// memstats command
+
+package fishcmd
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "memstats"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: MemstatsCommand,
+		Summary: "show memory and garbage collector statistics",
+		Synopsis: `memstats`,
+		Help: `Shows a summary of runtime.MemStats: memory allocated and obtained
+from the system, the heap, and the garbage collector. After the first
+time, how much each number has changed since the last "memstats" is
+shown too.
+
+`,
+		SeeAlso: []string{"gc", "goroutines"},
+
+		Min_args: 0,
+		Max_args: 0,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// lastMemStats is what was shown by the last memstats command.
+var lastMemStats *runtime.MemStats
+
+// memStat is a runtime.MemStats field to show.
+type memStat struct {
+	name  string
+	unit  string // as in pprof profiles: "bytes", "nanoseconds" or ""
+	value func(m *runtime.MemStats) int64
+}
+
+var memStatSections = []struct {
+	title string
+	stats []memStat
+}{
+	{"General", []memStat{
+		{"Alloc", "bytes", func(m *runtime.MemStats) int64 { return int64(m.Alloc) }},
+		{"TotalAlloc", "bytes", func(m *runtime.MemStats) int64 { return int64(m.TotalAlloc) }},
+		{"Sys", "bytes", func(m *runtime.MemStats) int64 { return int64(m.Sys) }},
+		{"Mallocs", "", func(m *runtime.MemStats) int64 { return int64(m.Mallocs) }},
+		{"Frees", "", func(m *runtime.MemStats) int64 { return int64(m.Frees) }},
+		{"Live objects", "", func(m *runtime.MemStats) int64 { return int64(m.Mallocs - m.Frees) }},
+	}},
+	{"Heap", []memStat{
+		{"HeapAlloc", "bytes", func(m *runtime.MemStats) int64 { return int64(m.HeapAlloc) }},
+		{"HeapSys", "bytes", func(m *runtime.MemStats) int64 { return int64(m.HeapSys) }},
+		{"HeapIdle", "bytes", func(m *runtime.MemStats) int64 { return int64(m.HeapIdle) }},
+		{"HeapInuse", "bytes", func(m *runtime.MemStats) int64 { return int64(m.HeapInuse) }},
+		{"HeapReleased", "bytes", func(m *runtime.MemStats) int64 { return int64(m.HeapReleased) }},
+		{"HeapObjects", "", func(m *runtime.MemStats) int64 { return int64(m.HeapObjects) }},
+		{"StackInuse", "bytes", func(m *runtime.MemStats) int64 { return int64(m.StackInuse) }},
+	}},
+	{"Garbage collector", []memStat{
+		{"NumGC", "", func(m *runtime.MemStats) int64 { return int64(m.NumGC) }},
+		{"NextGC", "bytes", func(m *runtime.MemStats) int64 { return int64(m.NextGC) }},
+		{"PauseTotal", "nanoseconds", func(m *runtime.MemStats) int64 { return int64(m.PauseTotalNs) }},
+		{"GCSys", "bytes", func(m *runtime.MemStats) int64 { return int64(m.GCSys) }},
+	}},
+}
+
+// MemstatsCommand implements the command:
+//    memstats
+// which shows a summary of runtime.MemStats.
+func MemstatsCommand(args []string) {
+	m := &runtime.MemStats{}
+	runtime.ReadMemStats(m)
+	for _, section := range memStatSections {
+		repl.Section(section.title)
+		rows := [][]string{}
+		for _, stat := range section.stats {
+			row := []string{stat.name + ":", formatValue(stat.value(m), stat.unit)}
+			if lastMemStats != nil {
+				change := stat.value(m) - stat.value(lastMemStats)
+				sign := "+"
+				if change < 0 {
+					sign = "-"
+					change = -change
+				}
+				row = append(row, sign+formatValue(change, stat.unit))
+			}
+			rows = append(rows, row)
+		}
+		printTable(rows)
+	}
+	if m.NumGC > 0 {
+		last := time.Unix(0, int64(m.LastGC))
+		repl.Msg("Last GC %s ago, pause %s; GC CPU fraction %.4f%%",
+			time.Since(last).Round(time.Millisecond),
+			time.Duration(m.PauseNs[(m.NumGC+255)%256]), 100*m.GCCPUFraction)
+	}
+	lastMemStats = m
+}
@@ -3,7 +3,10 @@
 package fishcmd
 
 import (
-	"reflect"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/rocky/go-fish"
 )
 
@@ -11,64 +14,285 @@ func init() {
 	name := "packages"
 	repl.Cmds[name] = &repl.CmdInfo{
 		Fn: PackageCommand,
-		Help: `packages [*package* [*package* ...] ]
-
-Show information about imported packages.
+		Summary: "show information about imported packages",
+		Synopsis: `packages [-l] [-k *kinds*] [-f *pattern*] [*package* [*package* ...] ]
+packages -t`,
+		Help: `Show information about imported packages.
 
 If a package name is given, then detailed information is given about
-that package import. Otherwise we give a list of imported packages.
+that package import. Otherwise we give a list of imported packages,
+followed by those packages imported under some other name and by
+package names shared by more than one package.
+
+Options:
+   -l           long listing: show function signatures, the type and
+                value of constants, the type of variables, and the
+                kind and number of methods of types. Without package
+                names, list import paths alongside package names.
+   -k *kinds*   only show members of the comma-separated *kinds*:
+                const, var, func, type or method. Methods are only
+                shown when asked for.
+   -f *pattern* only show members whose name matches *pattern*; see
+                "help apropos" for the pattern forms. Without package
+                names all packages are searched.
+   -t           show imported packages as a tree of import paths.
 `,
+		Examples: []string{
+			`packages -l strings`,
+			`packages -f 'Trim*' strings`,
+			`packages -k func,type -l bufio`,
+			`packages -t`,
+		},
+		SeeAlso: []string{"import"},
 
 		Min_args: 0,
 		Max_args: -1,  // Max_args < 0 means an arbitrary number
 	}
 	repl.AddToCategory("support", name)
-	repl.AddAlias("pkg", name)
-	repl.AddAlias("pkgs", name)
-	repl.AddAlias("package", name)
+	repl.MustAddAlias("pkg", name)
+	repl.MustAddAlias("pkgs", name)
+	repl.MustAddAlias("package", name)
+}
+
+// kindTitles gives the section titles used for each kind of package
+// member.
+var kindTitles = map[string]string{
+	"const":  "Constants",
+	"var":    "Variables",
+	"func":   "Functions",
+	"type":   "Types",
+	"method": "Methods",
+}
+
+// memberFilter selects which package members get shown.
+type memberFilter struct {
+	kinds map[string]bool
+	match func(string) bool
 }
 
-func printReflectMap(title string, m map[string] reflect.Value) {
-	if len(m) > 0 {
-		list := []string {}
-		for item := range m {
-			list = append(list, item)
+func (f *memberFilter) keep(m pkgMember) bool {
+	if f.kinds == nil {
+		if m.kind == "method" {
+			return false
+		}
+	} else if !f.kinds[m.kind] {
+		return false
+	}
+	return f.match == nil || memberMatches(f.match, m)
+}
+
+// printMembers shows the members of package pkgName that pass filter,
+// grouped by kind. If long is set we show signatures; otherwise just
+// names in columns. false is returned if nothing was shown.
+func printMembers(pkgName string, members []pkgMember, filter *memberFilter,
+	long bool) bool {
+	shown := false
+	for _, kind := range []string{"const", "func", "type", "var", "method"} {
+		list := []string{}
+		for _, m := range members {
+			if m.kind != kind || !filter.keep(m) {
+				continue
+			}
+			if !long {
+				list = append(list, m.name)
+			} else if kind == "type" {
+				list = append(list, fmt.Sprintf("%s; %d methods", m.sig, m.methods))
+			} else {
+				list = append(list, m.sig)
+			}
+		}
+		if len(list) == 0 {
+			continue
+		}
+		shown = true
+		title := kindTitles[kind] + " of " + pkgName
+		if long {
+			repl.Section("%s:", title)
+			for _, item := range list {
+				repl.Msg("  %s", item)
+			}
+		} else {
+			repl.PrintSorted(title, list)
 		}
-		repl.PrintSorted(title, list)
 	}
+	return shown
 }
 
-func printReflectTypeMap(title string, m map[string] reflect.Type) {
-	if len(m) > 0 {
-		list := []string {}
-		for item := range m {
-			list = append(list, item)
+// printPackageTree shows the import paths of imported packages as a
+// tree. When a package's name differs from the last element of its
+// path the name is shown too.
+func printPackageTree() {
+	names := map[string]string{}
+	paths := []string{}
+	for name, pkg := range repl.Env.Pkgs {
+		if other, ok := names[pkg.Path]; ok {
+			if name < other {
+				names[pkg.Path] = name + ", " + other
+			} else {
+				names[pkg.Path] = other + ", " + name
+			}
+			continue
 		}
-		repl.PrintSorted(title, list)
+		names[pkg.Path] = name
+		paths = append(paths, pkg.Path)
+	}
+	sort.Strings(paths)
+	repl.Section("All imported packages by import path:")
+	var last []string
+	for _, path := range paths {
+		elts := strings.Split(path, "/")
+		common := 0
+		for common < len(last) && common < len(elts)-1 &&
+			last[common] == elts[common] {
+			common++
+		}
+		for i := common; i < len(elts); i++ {
+			line := strings.Repeat("  ", i+1) + elts[i]
+			if i == len(elts)-1 && names[path] != elts[i] {
+				line += " (" + names[path] + ")"
+			}
+			repl.Msg("%s", line)
+		}
+		last = elts
+	}
+}
+
+// printAliases shows the packages known under a name other than their
+// package name, and the package names shared by several packages.
+func printAliases() {
+	aliases := []string{}
+	namesOf := make(map[string][]string)
+	for name, pkg := range repl.Env.Pkgs {
+		namesOf[pkg.Path] = append(namesOf[pkg.Path], name)
+		if name != pkg.Name {
+			aliases = append(aliases, fmt.Sprintf("%s => \"%s\" (package %s)",
+				name, pkg.Path, pkg.Name))
+		}
+	}
+	if len(aliases) > 0 {
+		sort.Strings(aliases)
+		repl.Section("Packages imported under another name:")
+		for _, alias := range aliases {
+			repl.Msg("  %s", alias)
+		}
+	}
+	if len(repl.PkgConflicts) == 0 {
+		return
+	}
+	conflicts := []string{}
+	for name, paths := range repl.PkgConflicts {
+		uses := []string{}
+		for _, path := range paths {
+			names := namesOf[path]
+			sort.Strings(names)
+			if len(names) == 0 {
+				uses = append(uses, fmt.Sprintf("\"%s\" not imported", path))
+			} else {
+				uses = append(uses, fmt.Sprintf("\"%s\" as %s", path,
+					strings.Join(names, ", ")))
+			}
+		}
+		conflicts = append(conflicts, name+": "+strings.Join(uses, "; "))
+	}
+	sort.Strings(conflicts)
+	repl.Section("Package name conflicts:")
+	for _, conflict := range conflicts {
+		repl.Msg("  %s", conflict)
 	}
 }
 
 // PackageCommand implements the command:
-//    package [*name* [name*...]]
+//    package [-l] [-k kinds] [-f pattern] [*name* [name*...]]
+//    package -t
 // which shows information about a package or lists all packages.
 func PackageCommand(args []string) {
-	if len(args) > 1 {
-		for _, pkg_name := range args[1:len(args)] {
+	long, tree := false, false
+	filter := &memberFilter{}
+	pkgNames := []string{}
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-l":
+			long = true
+		case "-t":
+			tree = true
+		case "-k", "-f":
+			if i+1 == len(args) {
+				repl.Errmsg("Option %s needs an argument", arg)
+				return
+			}
+			i++
+			if arg == "-f" {
+				match, err := makeMatcher(args[i])
+				if err != nil {
+					repl.Errmsg("Bad pattern %s: %s", args[i], err)
+					return
+				}
+				filter.match = match
+				continue
+			}
+			filter.kinds = make(map[string]bool)
+			for _, kind := range strings.Split(args[i], ",") {
+				if _, ok := kindTitles[kind]; !ok {
+					repl.Errmsg("Unknown kind %s; expecting one of: "+
+						"const, var, func, type, method", kind)
+					return
+				}
+				filter.kinds[kind] = true
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				repl.Errmsg("Unknown option %s", arg)
+				return
+			}
+			pkgNames = append(pkgNames, arg)
+		}
+	}
+
+	if tree {
+		printPackageTree()
+		return
+	}
+
+	if len(pkgNames) == 0 && (filter.match != nil || filter.kinds != nil) {
+		for name := range repl.Env.Pkgs {
+			pkgNames = append(pkgNames, name)
+		}
+		sort.Strings(pkgNames)
+		found := false
+		for _, pkg_name := range pkgNames {
+			members := pkgMembers(repl.Env.Pkgs[pkg_name])
+			found = printMembers(pkg_name, members, filter, long) || found
+		}
+		if !found {
+			repl.Msg("Nothing found")
+		}
+		return
+	}
+
+	if len(pkgNames) > 0 {
+		for _, pkg_name := range pkgNames {
 			if pkg, ok := repl.Env.Pkgs[pkg_name]; ok {
 				repl.Section("=== Package %s (\"%s\"): ===", pkg_name, pkg.Path)
-				printReflectMap("Constants of "+pkg_name, pkg.Consts)
-				printReflectMap("Functions of "+pkg_name, pkg.Funcs)
-				printReflectTypeMap("Types of "+pkg_name, pkg.Types)
-				printReflectMap("Variables of "+pkg_name, pkg.Vars)
+				printMembers(pkg_name, pkgMembers(pkg), filter, long)
 			} else {
-			repl.Errmsg("Package %s not imported", pkg_name)
+				repl.Errmsg("Package %s not imported", pkg_name)
 			}
 		}
+	} else if long {
+		for name := range repl.Env.Pkgs {
+			pkgNames = append(pkgNames, name)
+		}
+		sort.Strings(pkgNames)
+		repl.Section("All imported packages:")
+		for _, name := range pkgNames {
+			repl.Msg("  %-10s %s", name, repl.Env.Pkgs[name].Path)
+		}
+		printAliases()
 	} else {
-		pkgNames := []string {}
 		for pkg := range repl.Env.Pkgs {
 			pkgNames = append(pkgNames, pkg)
 		}
 		repl.PrintSorted("All imported packages", pkgNames)
+		printAliases()
 	}
 }
@@ -0,0 +1,308 @@
+// Copyright 2014 Rocky Bernstein.
+// profile command
+
+package fishcmd
+
+import (
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "profile"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: ProfileCommand,
+		Summary: "profile go-fish with runtime/pprof",
+		Synopsis: `profile [-n *count*] cpu start|stop [*file*]
+profile [-n *count*] cpu|block|mutex [*file*] -- *expression*
+profile [-n *count*] block|mutex start|stop [*file*]
+profile [-n *count*] heap|goroutine [*file*]
+profile [-n *count*] show *file*
+profile`,
+		Help: `Profiles go-fish with runtime/pprof, writes the profile to *file*
+("cpu.pprof", "heap.pprof" and so on if not given), and shows the
+*count* (default 10) functions with the largest values, as "top" in
+"go tool pprof" does. The file can be looked at further with "go tool
+pprof".
+
+"cpu start" starts a CPU profile, which covers everything evaluated
+until "cpu stop". "block start" and "mutex start" turn on recording of
+blocking and mutex contention, and "stop" writes what was recorded and
+turns recording off again.
+
+With "-- *expression*", just *expression* is profiled. For a CPU
+profile it is evaluated over and over for a second, since the
+profiler takes only 100 samples a second.
+
+"heap" and "goroutine" write a snapshot of memory in use, after a
+garbage collection, and of the goroutines running. "show" summarizes a
+profile written earlier. With no arguments, we show which profiles are
+running.
+`,
+		Examples: []string{
+			`profile cpu -- strings.Repeat("ab", 1000)`,
+			`profile cpu start`,
+			`profile cpu stop /tmp/cpu.pprof`,
+			`profile -n 20 heap`,
+		},
+		SeeAlso: []string{"time", "bench"},
+
+		Min_args: 0,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// cpuProfile is the file being written by a running CPU profile.
+var cpuProfile *os.File
+
+// profileDuration is how long an expression is run for a CPU profile.
+var profileDuration = time.Second
+
+// ProfileCommand implements the command:
+//    profile [-n *count*] *kind* [start|stop] [*file*] [-- *expression*]
+// which profiles go-fish with runtime/pprof.
+func ProfileCommand(args []string) {
+	rest := " " + repl.CmdRest + " "
+	expr := ""
+	if i := strings.Index(rest, " -- "); i >= 0 {
+		rest, expr = rest[:i], strings.TrimSpace(rest[i+4:])
+		if expr == "" {
+			repl.Errmsg("Expecting an expression after --")
+			return
+		}
+	}
+	words := strings.Fields(rest)
+	count := 10
+	if len(words) >= 2 && words[0] == "-n" {
+		n, err := repl.GetInt(words[1], "count", 1, 0)
+		if err != nil {
+			repl.Errmsg("%s", err)
+			return
+		}
+		count, words = n, words[2:]
+	}
+	if len(words) == 0 {
+		if expr != "" {
+			repl.Errmsg("Expecting a profile kind before --")
+			return
+		}
+		profileStatus()
+		return
+	}
+
+	kind, words := words[0], words[1:]
+	action := ""
+	if len(words) > 0 && (words[0] == "start" || words[0] == "stop") {
+		action, words = words[0], words[1:]
+	}
+	if len(words) > 1 {
+		repl.Errmsg("Too many arguments: %s", strings.Join(words[1:], " "))
+		return
+	}
+	file := kind + ".pprof"
+	if len(words) == 1 {
+		file = words[0]
+	}
+	if action != "" && expr != "" {
+		repl.Errmsg("Give either %s or an expression, not both", action)
+		return
+	}
+
+	switch kind {
+	case "cpu":
+		switch {
+		case action == "start":
+			startCPUProfile(file)
+		case action == "stop":
+			stopCPUProfile(count)
+		case expr != "":
+			profileCPU(file, expr, count)
+		default:
+			repl.Errmsg("Expecting start, stop or -- *expression* after cpu")
+		}
+	case "block", "mutex":
+		switch {
+		case action == "start":
+			setContentionRate(kind, 1)
+			repl.Msg("Recording %s profile.", kind)
+		case action == "stop":
+			writeProfile(kind, file, count)
+			setContentionRate(kind, 0)
+		case expr != "":
+			profileContention(kind, file, expr, count)
+		default:
+			writeProfile(kind, file, count)
+		}
+	case "heap", "goroutine":
+		if action != "" || expr != "" {
+			repl.Errmsg("A %s profile is a snapshot; just give a file name", kind)
+			return
+		}
+		if kind == "heap" {
+			runtime.GC()
+		}
+		writeProfile(kind, file, count)
+	case "show":
+		if len(words) != 1 {
+			repl.Errmsg("Expecting a profile file to show")
+			return
+		}
+		showProfile(file, count)
+	default:
+		repl.Errmsg("Unknown profile kind %s; expecting cpu, heap, goroutine, "+
+			"block, mutex or show", kind)
+	}
+}
+
+// contentionRates holds the block and mutex profile settings.
+var contentionRates = map[string]int{}
+
+func setContentionRate(kind string, rate int) {
+	contentionRates[kind] = rate
+	if kind == "block" {
+		runtime.SetBlockProfileRate(rate)
+	} else {
+		runtime.SetMutexProfileFraction(rate)
+	}
+}
+
+func profileStatus() {
+	if cpuProfile != nil {
+		repl.Msg("CPU profile is being written to %s.", cpuProfile.Name())
+	} else {
+		repl.Msg("No CPU profile is running.")
+	}
+	for _, kind := range []string{"block", "mutex"} {
+		if contentionRates[kind] > 0 {
+			repl.Msg("%s profile is being recorded.", kind)
+		}
+	}
+}
+
+func startCPUProfile(file string) bool {
+	if cpuProfile != nil {
+		repl.Errmsg("A CPU profile is already being written to %s",
+			cpuProfile.Name())
+		return false
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return false
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		repl.Errmsg("%s", err)
+		return false
+	}
+	cpuProfile = f
+	return true
+}
+
+func stopCPUProfile(count int) {
+	if cpuProfile == nil {
+		repl.Errmsg("No CPU profile is running")
+		return
+	}
+	pprof.StopCPUProfile()
+	file := cpuProfile.Name()
+	err := cpuProfile.Close()
+	cpuProfile = nil
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	repl.Msg("Wrote CPU profile to %s", file)
+	showProfile(file, count)
+}
+
+// profileCPU profiles evaluating expr over and over.
+func profileCPU(file, expr string, count int) {
+	exprs, ok := checkedExprs(expr)
+	if !ok {
+		return
+	}
+	if len(exprs) != 1 {
+		repl.Errmsg("Expecting a single expression")
+		return
+	}
+	if !startCPUProfile(file) {
+		return
+	}
+	start := time.Now()
+	// The evaluations may go on after a LimitError, so n is counted
+	// atomically.
+	var n int64
+	err := repl.Current.WithLimits(func() error {
+		var err error
+		for err == nil && time.Since(start) < profileDuration {
+			err = exprs[0].eval()
+			atomic.AddInt64(&n, 1)
+		}
+		return err
+	})
+	if err != nil {
+		evalErrmsg(err)
+	}
+	repl.Msg("Evaluated %d times in %s", atomic.LoadInt64(&n),
+		time.Since(start))
+	stopCPUProfile(count)
+}
+
+// profileContention records blocking or mutex contention while expr
+// is evaluated once.
+func profileContention(kind, file, expr string, count int) {
+	exprs, ok := checkedExprs(expr)
+	if !ok {
+		return
+	}
+	if len(exprs) != 1 {
+		repl.Errmsg("Expecting a single expression")
+		return
+	}
+	saveRate := contentionRates[kind]
+	setContentionRate(kind, 1)
+	err := repl.Current.WithLimits(exprs[0].eval)
+	setContentionRate(kind, saveRate)
+	if err != nil {
+		evalErrmsg(err)
+		return
+	}
+	writeProfile(kind, file, count)
+}
+
+// writeProfile writes the runtime/pprof profile named kind to file.
+func writeProfile(kind, file string, count int) {
+	f, err := os.Create(file)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	err = pprof.Lookup(kind).WriteTo(f, 0)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	repl.Msg("Wrote %s profile to %s", kind, file)
+	showProfile(file, count)
+}
+
+func showProfile(file string, count int) {
+	p, err := readProfile(file)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	printTop(p, count)
+}
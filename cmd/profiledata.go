@@ -0,0 +1,348 @@
+// Copyright 2014 Rocky Bernstein.
+// Reading and summarizing pprof profiles
+
+package fishcmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+// This reads just enough of the gzipped protocol buffer format that
+// runtime/pprof writes (see profile.proto in github.com/google/pprof)
+// to show the functions with the largest values, like "top" in
+// "go tool pprof".
+
+// profileData is the part of a decoded profile that we use.
+type profileData struct {
+	sampleTypes   []valueType
+	samples       []profileSample
+	locations     map[uint64][]uint64 // id -> function ids, innermost first
+	functions     map[uint64]int64    // id -> function name string index
+	strings       []string
+	defaultSample int64 // string index of the default sample type
+}
+
+type valueType struct {
+	typ, unit int64 // string indices
+}
+
+type profileSample struct {
+	locations []uint64
+	values    []int64
+}
+
+var errBadProfile = errors.New("malformed profile")
+
+// pbuf decodes protocol buffer fields.
+type pbuf struct {
+	data []byte
+	err  error
+}
+
+func (b *pbuf) varint() uint64 {
+	var x uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if len(b.data) == 0 {
+			b.err = errBadProfile
+			return 0
+		}
+		c := b.data[0]
+		b.data = b.data[1:]
+		x |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return x
+		}
+	}
+	b.err = errBadProfile
+	return 0
+}
+
+// field returns the next field's number and wire type, and for
+// length-delimited fields, its bytes. Fixed-size fields are skipped
+// over; we don't need any.
+func (b *pbuf) field() (int, int, uint64, []byte) {
+	key := b.varint()
+	num, wire := int(key>>3), int(key&7)
+	switch wire {
+	case 0:
+		return num, wire, b.varint(), nil
+	case 1, 5:
+		size := 8
+		if wire == 5 {
+			size = 4
+		}
+		if len(b.data) < size {
+			b.err = errBadProfile
+			return 0, 0, 0, nil
+		}
+		b.data = b.data[size:]
+		return num, wire, 0, nil
+	case 2:
+		n := b.varint()
+		if uint64(len(b.data)) < n {
+			b.err = errBadProfile
+			return 0, 0, 0, nil
+		}
+		data := b.data[:n]
+		b.data = b.data[n:]
+		return num, wire, 0, data
+	}
+	b.err = errBadProfile
+	return 0, 0, 0, nil
+}
+
+// fields calls fn for each field in data.
+func fields(data []byte, fn func(num, wire int, x uint64, data []byte)) error {
+	b := &pbuf{data: data}
+	for len(b.data) > 0 && b.err == nil {
+		num, wire, x, data := b.field()
+		if b.err == nil {
+			fn(num, wire, x, data)
+		}
+	}
+	return b.err
+}
+
+// repeatedInts appends to ints the value of a repeated integer field,
+// which may be packed.
+func repeatedInts(ints []uint64, wire int, x uint64, data []byte) []uint64 {
+	if wire == 0 {
+		return append(ints, x)
+	}
+	b := &pbuf{data: data}
+	for len(b.data) > 0 && b.err == nil {
+		ints = append(ints, b.varint())
+	}
+	return ints
+}
+
+// readProfile reads the profile that runtime/pprof wrote to file.
+func readProfile(file string) (*profileData, error) {
+	gz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &profileData{
+		locations: make(map[uint64][]uint64),
+		functions: make(map[uint64]int64),
+	}
+	var ferr error
+	err = fields(data, func(num, wire int, x uint64, data []byte) {
+		switch num {
+		case 1: // sample_type
+			var vt valueType
+			ferr = fields(data, func(num, wire int, x uint64, data []byte) {
+				switch num {
+				case 1:
+					vt.typ = int64(x)
+				case 2:
+					vt.unit = int64(x)
+				}
+			})
+			p.sampleTypes = append(p.sampleTypes, vt)
+		case 2: // sample
+			var s profileSample
+			ferr = fields(data, func(num, wire int, x uint64, data []byte) {
+				switch num {
+				case 1:
+					s.locations = repeatedInts(s.locations, wire, x, data)
+				case 2:
+					for _, v := range repeatedInts(nil, wire, x, data) {
+						s.values = append(s.values, int64(v))
+					}
+				}
+			})
+			p.samples = append(p.samples, s)
+		case 4: // location
+			var id uint64
+			funcs := []uint64{}
+			ferr = fields(data, func(num, wire int, x uint64, data []byte) {
+				switch num {
+				case 1:
+					id = x
+				case 4: // line
+					fields(data, func(num, wire int, x uint64, data []byte) {
+						if num == 1 {
+							funcs = append(funcs, x)
+						}
+					})
+				}
+			})
+			p.locations[id] = funcs
+		case 5: // function
+			var id uint64
+			var name int64
+			ferr = fields(data, func(num, wire int, x uint64, data []byte) {
+				switch num {
+				case 1:
+					id = x
+				case 2:
+					name = int64(x)
+				}
+			})
+			p.functions[id] = name
+		case 6: // string_table
+			p.strings = append(p.strings, string(data))
+		case 14: // default_sample_type
+			p.defaultSample = int64(x)
+		}
+	})
+	if err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return p, nil
+}
+
+func (p *profileData) str(i int64) string {
+	if i < 0 || i >= int64(len(p.strings)) {
+		return "?"
+	}
+	return p.strings[i]
+}
+
+// sampleIndex picks the value to show: the profile's default, or
+// else the last one, which is the one "go tool pprof" shows.
+func (p *profileData) sampleIndex() int {
+	for i, st := range p.sampleTypes {
+		if p.defaultSample != 0 && st.typ == p.defaultSample {
+			return i
+		}
+	}
+	return len(p.sampleTypes) - 1
+}
+
+// formatValue shows v in unit.
+func formatValue(v int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(v).String()
+	case "bytes":
+		return repl.FormatBytes(v)
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+// funcValue is the flat and cumulative value for a function.
+type funcValue struct {
+	name      string
+	flat, cum int64
+}
+
+type byFlat []*funcValue
+
+func (f byFlat) Len() int      { return len(f) }
+func (f byFlat) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f byFlat) Less(i, j int) bool {
+	if f[i].flat != f[j].flat {
+		return f[i].flat > f[j].flat
+	}
+	if f[i].cum != f[j].cum {
+		return f[i].cum > f[j].cum
+	}
+	return f[i].name < f[j].name
+}
+
+// printTop shows the top n functions of profile p by flat value.
+func printTop(p *profileData, n int) {
+	if len(p.sampleTypes) == 0 {
+		repl.Msg("Profile has no samples.")
+		return
+	}
+	idx := p.sampleIndex()
+	st := p.sampleTypes[idx]
+	unit := p.str(st.unit)
+
+	byName := make(map[string]*funcValue)
+	var total int64
+	for _, s := range p.samples {
+		if idx >= len(s.values) {
+			continue
+		}
+		v := s.values[idx]
+		total += v
+		seen := make(map[string]bool)
+		for i, loc := range s.locations {
+			for j, fn := range p.locations[loc] {
+				name := p.str(p.functions[fn])
+				fv := byName[name]
+				if fv == nil {
+					fv = &funcValue{name: name}
+					byName[name] = fv
+				}
+				if i == 0 && j == 0 {
+					fv.flat += v
+				}
+				if !seen[name] {
+					fv.cum += v
+					seen[name] = true
+				}
+			}
+		}
+	}
+	funcs := []*funcValue{}
+	for _, fv := range byName {
+		funcs = append(funcs, fv)
+	}
+	sort.Sort(byFlat(funcs))
+
+	shown := len(funcs)
+	if n > 0 && n < shown {
+		shown = n
+	}
+	repl.Msg("Showing top %d of %d functions by %s; total %s", shown,
+		len(funcs), p.str(st.typ), formatValue(total, unit))
+	if total == 0 {
+		return
+	}
+	percent := func(v int64) string {
+		return fmt.Sprintf("%.2f%%", 100*float64(v)/float64(total))
+	}
+	rows := [][]string{{"flat", "flat%", "sum%", "cum", "cum%", ""}}
+	var sum int64
+	for _, fv := range funcs[:shown] {
+		sum += fv.flat
+		rows = append(rows, []string{formatValue(fv.flat, unit), percent(fv.flat),
+			percent(sum), formatValue(fv.cum, unit), percent(fv.cum), fv.name})
+	}
+	printRightTable(rows)
+}
+
+// printRightTable shows rows in columns, all right-aligned except the
+// last.
+func printRightTable(rows [][]string) {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for _, row := range rows {
+		line := ""
+		for i, cell := range row[:len(row)-1] {
+			line += fmt.Sprintf("%*s  ", widths[i], cell)
+		}
+		repl.Msg("%s", strings.TrimRight(line+row[len(row)-1], " "))
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 Rocky Bernstein.
+// pwd command
+
+package fishcmd
+
+import (
+	"os"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "pwd"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: PwdCommand,
+		Summary: "show the working directory",
+		Synopsis: `pwd`,
+		Help: `Shows the working directory of go-fish.
+`,
+		SeeAlso: []string{"cd"},
+
+		Min_args: 0,
+		Max_args: 0,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// PwdCommand implements the command:
+//    pwd
+// which shows the working directory.
+func PwdCommand(args []string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	repl.Msg("Working directory is %s", cwd)
+}
@@ -12,18 +12,19 @@ func init() {
 	name := "quit"
 	repl.Cmds[name] = &repl.CmdInfo{
 		Fn: QuitCommand,
-		Help: `quit [exit-code]
-
-Terminates program. If an exit code is given, that is the exit code
+		Summary: "leave go-fish",
+		Synopsis: `quit [exit-code]`,
+		Help: `Terminates program. If an exit code is given, that is the exit code
 for the program. Zero (normal termination) is used if no
 termintation code.
 `,
 
 		Min_args: 0,
 		Max_args: 1,
+		MinAbbrev: 4,
 	}
 	repl.AddToCategory("support", name)
-	repl.Aliases["q"] = name
+	repl.MustAddAlias("q", name)
 }
 
 func QuitCommand(args []string) {
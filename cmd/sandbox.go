@@ -0,0 +1,97 @@
+// Copyright 2014 Rocky Bernstein.
+// sandbox command
+
+package fishcmd
+
+import (
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "sandbox"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: SandboxCommand,
+		Summary: "show or set the sandbox policy of this session",
+		Synopsis: `sandbox [*policy*[,*policy*...]]`,
+		Help: `With no argument, shows the sandbox policy of this session: which
+packages, functions and commands may not be used.
+
+Otherwise, puts this session in a sandbox. A *policy* is one of the
+built-in policies, or a policy file:
+
+   pure     only calls to functions without side effects
+   no-exec  no running programs or system calls, and no exiting
+   no-fs    no reading or writing files
+
+Once a session is in a sandbox, its policy can't be changed. The
+package "github.com/rocky/go-fish", the evaluator and variable "env"
+are denied by every policy, since they give access to everything
+else.
+
+A policy file has one rule per line, for example:
+
+   # Like no-fs but reading environment variables is fine
+   include no-fs
+   allow os.Getenv os.LookupEnv
+   deny net/...
+   deny command import
+
+Use "default deny" to allow only what is listed, and "readonly" to
+allow only calls without side effects. See also the -sandbox option.
+`,
+
+		Min_args: 0,
+		Max_args: 1,
+		MinAbbrev: 7,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// SandboxCommand implements the command:
+//    sandbox [*policy*[,*policy*...]]
+// which shows the session's sandbox policy or sets it.
+func SandboxCommand(args []string) {
+	s := repl.Current
+	if len(args) == 1 {
+		showPolicy(s.Policy)
+		return
+	}
+	if s.Policy != nil {
+		repl.Errmsg("This session is already in sandbox %s", s.Policy.Name)
+		return
+	}
+	policy, err := repl.LoadPolicy(args[1])
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	s.Policy = policy
+	repl.Msg("This session is now in sandbox %s.", policy.Name)
+}
+
+func showPolicy(policy *repl.Policy) {
+	if policy == nil {
+		repl.Msg("This session is not in a sandbox. Built-in policies: %s",
+			strings.Join(repl.PolicyNames(), ", "))
+		return
+	}
+	repl.Section("Sandbox %s", policy.Name)
+	for _, rule := range policy.Rules {
+		action := "deny"
+		if rule.Allow {
+			action = "allow"
+		}
+		if rule.Command {
+			action += " command"
+		}
+		repl.Msg("%s %s", action, rule.Pattern)
+	}
+	if policy.DenyOthers {
+		repl.Msg("default deny")
+	}
+	if policy.ReadOnly {
+		repl.Msg("readonly")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2014 Rocky Bernstein.
+// save command
+
+package fishcmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "save"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: SaveCommand,
+		Summary: "save this session as a transcript, script or Go program",
+		Help: `Saves what has been entered in this session. The format depends on
+the extension of *file*:
+
+   .md    a Markdown transcript: each input and its output in a
+          code block
+   .fish  the lines entered, which "source" can replay
+   .go    a Go program that evaluates each expression again and
+          prints its value
+
+In a Go program, expression values are kept in "results" as they are
+here, and packages are imported under the names used in this session.
+REPL commands and expressions that failed become comments. Lines
+replayed with "source" are saved rather than the "source" command.
+`,
+		Examples: []string{
+			`save transcript.md`,
+			`save session.fish`,
+			`save main.go`,
+		},
+		SeeAlso: []string{"source"},
+
+		Args: []*repl.ArgSpec{{Name: "file", Type: repl.FileArg}},
+	}
+	repl.AddToCategory("support", name)
+}
+
+// SaveCommand implements the command:
+//    save *file*
+// which saves the session as a transcript, a script or a Go program.
+func SaveCommand(args []string) {
+	file := repl.CmdArgs.String("file")
+	entries := savedEntries(repl.Current.Transcript)
+	var data []byte
+	switch filepath.Ext(file) {
+	case ".md":
+		data = markdownTranscript(entries)
+	case ".fish":
+		data = fishScript(entries)
+	case ".go":
+		data = goProgram(repl.Env, entries)
+	default:
+		repl.Errmsg("Don't know how to save to %s; expecting a file ending "+
+			"in .md, .fish or .go", file)
+		return
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	repl.Msg("Saved %d entries to %s", len(entries), file)
+}
+
+// commandName returns the REPL command that line runs, or "" if it
+// isn't a command.
+func commandName(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := fields[0]
+	if alias := repl.LookupCmd(name); alias != "" {
+		name = alias
+	}
+	if _, ok := repl.Cmds[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// savedEntries drops the entries that shouldn't be saved: "save"
+// commands, and "source" commands, since the lines they replay are
+// entries of their own.
+func savedEntries(transcript []repl.TranscriptEntry) []repl.TranscriptEntry {
+	entries := []repl.TranscriptEntry{}
+	for _, entry := range transcript {
+		if entry.Result == nil {
+			if name := commandName(entry.Input); name == "save" || name == "source" {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func markdownTranscript(entries []repl.TranscriptEntry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# go-fish session\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&buf, "\n```\ngofish> %s\n%s", entry.Input, entry.Output)
+		if entry.Output != "" && !strings.HasSuffix(entry.Output, "\n") {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("```\n")
+	}
+	return buf.Bytes()
+}
+
+func fishScript(entries []repl.TranscriptEntry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		fmt.Fprintln(&buf, entry.Input)
+	}
+	return buf.Bytes()
+}
+
+// goProgram turns the expressions in entries into a Go program that
+// prints their values, importing the packages from env that they use.
+func goProgram(env *eval.Env, entries []repl.TranscriptEntry) []byte {
+	imports := map[string]string{} // name -> import path
+	var body bytes.Buffer
+	for _, entry := range entries {
+		result := entry.Result
+		switch {
+		case result == nil:
+			comment := strings.TrimSpace(strings.TrimPrefix(entry.Input, "//"))
+			fmt.Fprintf(&body, "\t// %s\n", comment)
+			continue
+		case len(result.Errors) > 0:
+			fmt.Fprintf(&body, "\t// failed: %s\n", entry.Input)
+			continue
+		}
+		expr, err := parser.ParseExpr(entry.Input)
+		if err != nil {
+			fmt.Fprintf(&body, "\t// failed: %s\n", entry.Input)
+			continue
+		}
+		addImports(env, expr, imports)
+
+		values := result.Values
+		if len(values) == 0 && values != nil {
+			fmt.Fprintf(&body, "\t%s\n", entry.Input)
+			continue
+		}
+		imports["fmt"] = "fmt"
+		switch {
+		case len(values) > 1:
+			names := []string{}
+			for i := range values {
+				names = append(names, fmt.Sprintf("v%d", i))
+			}
+			vars := strings.Join(names, ", ")
+			fmt.Fprintf(&body, "\t{\n\t\t%s := %s\n", vars, entry.Input)
+			fmt.Fprintf(&body, "\t\tresults = append(results, []interface{}{%s})\n", vars)
+			fmt.Fprintf(&body, "\t\tfmt.Println(%s)\n\t}\n", vars)
+		case result.ResultIndex >= 0:
+			fmt.Fprintf(&body, "\tresults = append(results, %s)\n", entry.Input)
+			fmt.Fprintf(&body, "\tfmt.Println(results[%d])\n", result.ResultIndex)
+		default:
+			fmt.Fprintf(&body, "\tfmt.Println(%s)\n", entry.Input)
+		}
+	}
+
+	// Sort by import path, as goimports does.
+	specs := []string{}
+	for name, path := range imports {
+		specs = append(specs, path+" "+name)
+	}
+	sort.Strings(specs)
+	var buf bytes.Buffer
+	buf.WriteString("// Saved from a go-fish session.\n\npackage main\n\nimport (\n")
+	for _, spec := range specs {
+		space := strings.LastIndex(spec, " ")
+		path, name := spec[:space], spec[space+1:]
+		if pkg, ok := env.Pkgs[name]; ok && pkg.Name != name {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+	}
+	buf.WriteString(")\n\n// results holds expression values, as in go-fish.\n" +
+		"var results []interface{}\n\nfunc main() {\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n")
+	if src, err := format.Source(buf.Bytes()); err == nil {
+		return src
+	}
+	return buf.Bytes()
+}
+
+// addImports adds the packages in env that expr refers to to imports.
+func addImports(env *eval.Env, expr ast.Expr, imports map[string]string) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if _, isVar := env.Vars[id.Name]; !isVar {
+				if pkg, ok := env.Pkgs[id.Name]; ok {
+					imports[id.Name] = pkg.Path
+				}
+			}
+		}
+		return true
+	})
+}
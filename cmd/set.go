@@ -0,0 +1,49 @@
+// Copyright 2014 Rocky Bernstein.
+// set command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "set"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: SetCommand,
+		Summary: "change a setting",
+		Help: `Changes one of the settings that "show" lists. Limits on evaluations
+take a value or "off":
+
+   set timeout 5s         abort evaluations that take longer than this
+   set maxheap 256MB      ... that grow the heap by more than this
+   set maxgoroutines 100  ... that start more goroutines than this
+
+Limits apply to the current session. The -timeout, -maxheap and
+-maxgoroutines options set them for all sessions, and sessions can
+lower those limits but not raise them or turn them off. The heap and
+goroutines are counted for the whole program, so other sessions
+running at the same time count too.
+`,
+		SeeAlso: []string{"show"},
+
+		Args: []*repl.ArgSpec{
+			{Name: "setting", Type: repl.EnumArg, Values: repl.SettingNames()},
+			{Name: "value"},
+		},
+	}
+	repl.AddToCategory("support", name)
+}
+
+// SetCommand implements the command:
+//    set *setting* *value*
+// which changes a setting.
+func SetCommand(args []string) {
+	name := repl.CmdArgs.String("setting")
+	setting := repl.Settings[name]
+	if err := setting.Set(repl.CmdArgs.String("value")); err != nil {
+		repl.Errmsg("%s: %s", name, err)
+		return
+	}
+	repl.Msg("%s is %s", name, setting.Get())
+}
@@ -0,0 +1,128 @@
+// Copyright 2014 Rocky Bernstein.
+// shell command
+
+package fishcmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "shell"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: ShellCommand,
+		Summary: "run a shell command",
+		Synopsis: `shell [-o *variable* | -r] *command*
+!*command*
+*variable* := !!*command*
+!!*command*`,
+		Help: `Runs *command* with $SHELL, or /bin/sh if that isn't set. Its output
+is shown as it runs, and its exit status is saved in "results".
+
+With -o, or as "*variable* := !!*command*", the command's standard
+output is saved in string *variable* instead of being shown. The
+variable is created if need be. With -r, or as "!!*command*", the
+output is saved in "results" and shown as a value.
+
+A line such as "!ok" that is a Go expression negating something Go
+knows of is evaluated rather than run; enter ":!ok" to run command
+"ok".
+`,
+		Examples: []string{
+			`!ls -l`,
+			`files := !!ls *.go`,
+			`shell git log -1`,
+		},
+		SeeAlso: []string{"cd"},
+
+		Min_args: 1,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// ShellCommand implements the command:
+//    shell [-o *variable* | -r] *command*
+// which runs a command with $SHELL.
+func ShellCommand(args []string) {
+	rest := repl.CmdRest
+	words := strings.Fields(rest)
+	variable, toResult := "", false
+	switch words[0] {
+	case "-o":
+		if len(words) < 3 {
+			repl.Errmsg("Expecting a variable and a command after -o")
+			return
+		}
+		variable = words[1]
+		rest = strings.TrimSpace(rest[2:])[len(variable):]
+	case "-r":
+		toResult = true
+		rest = rest[2:]
+	}
+	command := strings.TrimSpace(rest)
+	if command == "" {
+		repl.Errmsg("Expecting a command to run")
+		return
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell, "-c", command)
+	var output bytes.Buffer
+	if variable != "" || toResult {
+		cmd.Stdout = &output
+	} else {
+		cmd.Stdout = repl.Out
+	}
+	// Only a session at the terminal has somewhere to read input from
+	// and show errors on.
+	if repl.Current.Out == os.Stdout {
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+	} else {
+		cmd.Stderr = repl.Out
+	}
+	status := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			repl.Errmsg("%s", err)
+			return
+		}
+		status = exitErr.ExitCode()
+	}
+
+	s := repl.Current
+	switch {
+	case variable != "":
+		if err := s.SetVar(variable, output.String()); err != nil {
+			repl.Errmsg("%s", err)
+			return
+		}
+		repl.Msg("%s = %d bytes of output", variable, output.Len())
+	case toResult:
+		s.Results = append(s.Results, output.String())
+		repl.Msg("results[%d] = %s", len(s.Results)-1,
+			s.Inspect(reflect.ValueOf(output.String())))
+	default:
+		s.Results = append(s.Results, status)
+		if status != 0 {
+			repl.Errmsg("Exit status %d, saved in results[%d]", status,
+				len(s.Results)-1)
+		}
+		return
+	}
+	if status != 0 {
+		repl.Errmsg("Exit status %d", status)
+	}
+}
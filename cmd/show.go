@@ -0,0 +1,48 @@
+// Copyright 2014 Rocky Bernstein.
+// show command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "show"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: ShowCommand,
+		Summary: "show settings",
+		Help: `Shows the value of *setting*, or of all settings, along with what each
+is for. See "set" for changing them.
+`,
+		SeeAlso: []string{"set"},
+
+		Args: []*repl.ArgSpec{
+			{Name: "setting", Type: repl.EnumArg, Values: repl.SettingNames(),
+				Optional: true},
+		},
+	}
+	repl.AddToCategory("support", name)
+}
+
+// ShowCommand implements the command:
+//    show [*setting*]
+// which shows settings.
+func ShowCommand(args []string) {
+	if name := repl.CmdArgs.String("setting"); name != "" {
+		setting := repl.Settings[name]
+		repl.Msg("%s is %s: %s", name, setting.Get(), setting.Help)
+		return
+	}
+	names := repl.SettingNames()
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		setting := repl.Settings[name]
+		repl.Msg("%-*s  %-8s  %s", width, name, setting.Get(), setting.Help)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2014 Rocky Bernstein.
+// source command
+
+package fishcmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "source"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: SourceCommand,
+		Summary: "run the lines in a file",
+		Help: `Reads lines from *file* and processes each as if it had been entered
+at the prompt, showing it first unless -q is given. Blank lines and
+lines starting with "#" are skipped. "save *file*.fish" writes such a
+file. Put a file name with spaces in quotes, and one that starts with
+"-" after "--".
+`,
+		SeeAlso: []string{"save"},
+
+		Args: []*repl.ArgSpec{
+			{Name: "-q", Type: repl.BoolArg},
+			{Name: "file", Type: repl.FileArg},
+		},
+	}
+	repl.AddToCategory("support", name)
+}
+
+// maxSourceDepth limits how deeply "source" commands can nest.
+const maxSourceDepth = 10
+
+// SourceCommand implements the command:
+//    source [-q] *file*
+// which replays the lines in a file.
+func SourceCommand(args []string) {
+	s := repl.Current
+	if s.SourceDepth >= maxSourceDepth {
+		repl.Errmsg("\"source\" commands nested more than %d deep",
+			maxSourceDepth)
+		return
+	}
+	file, quiet := repl.CmdArgs.String("file"), repl.CmdArgs.Bool("-q")
+	f, err := os.Open(file)
+	if err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	defer f.Close()
+	s.SourceDepth++
+	defer func() { s.SourceDepth-- }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && !s.LeaveREPL {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !quiet {
+			repl.Msg("gofish> %s", line)
+		}
+		s.Enter(line)
+		// Commands set the package variable.
+		s.LeaveREPL = repl.LeaveREPL
+	}
+	if err := scanner.Err(); err != nil {
+		repl.Errmsg("%s: %s", file, err)
+	}
+}
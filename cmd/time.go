@@ -0,0 +1,103 @@
+// Copyright 2014 Rocky Bernstein.
+// time command
+
+package fishcmd
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "time"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: TimeCommand,
+		Summary: "time an evaluation, or compare two",
+		Synopsis: `time *expression* [;; *expression*]`,
+		Help: `Evaluates *expression* once and shows its value, the wall-clock time
+it took, and the number of allocations and bytes allocated while it
+ran. Give two expressions separated by ";;" to compare them side by
+side.
+
+Allocation counts are for the whole program, so anything else running
+at the same time is counted too.
+`,
+		Examples: []string{
+			`time strings.Repeat("x", 1000000)`,
+			`time fmt.Sprint(42) ;; strconv.Itoa(42)`,
+		},
+		SeeAlso: []string{"bench", "profile"},
+
+		Min_args: 1,
+		Max_args: -1,
+		RawArgs: true,
+	}
+	repl.AddToCategory("running", name)
+}
+
+// TimeCommand implements the command:
+//    time *expression* [;; *expression*]
+// which times one evaluation of an expression, or of two.
+func TimeCommand(args []string) {
+	exprs, ok := checkedExprs(repl.CmdRest)
+	if !ok {
+		return
+	}
+	rows := [][]string{{"", "time", "allocs", "bytes"}}
+	elapsed := []time.Duration{}
+	for _, e := range exprs {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		var vals *[]reflect.Value
+		err := repl.Current.WithLimits(func() error {
+			var err error
+			vals, _, err = eval.EvalExpr(e.ctx, e.cexpr, e.env)
+			return err
+		})
+		d := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if err != nil {
+			evalErrmsg(err)
+			return
+		}
+		if vals != nil {
+			showValues(e.src, *vals)
+		}
+		elapsed = append(elapsed, d)
+		rows = append(rows, []string{e.src, d.String(),
+			fmt.Sprintf("%d", after.Mallocs-before.Mallocs),
+			fmt.Sprintf("%d", after.TotalAlloc-before.TotalAlloc)})
+	}
+	printTable(rows)
+	if len(exprs) == 2 {
+		printRatio(exprs, float64(elapsed[0]), float64(elapsed[1]))
+	}
+}
+
+// showValues shows the values of expression src as "=" lines.
+func showValues(src string, vals []reflect.Value) {
+	inspect := repl.Current.Inspect
+	switch len(vals) {
+	case 0:
+		return
+	case 1:
+		if vals[0].IsValid() {
+			repl.Msg("%s = %s", src, inspect(vals[0]))
+		} else {
+			repl.Msg("%s = nil", src)
+		}
+	default:
+		texts := []string{}
+		for _, v := range vals {
+			texts = append(texts, inspect(v))
+		}
+		repl.Msg("%s = %s", src, strings.Join(texts, ", "))
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2014 Rocky Bernstein.
+// unalias command
+
+package fishcmd
+
+import (
+	"github.com/rocky/go-fish"
+)
+
+func init() {
+	name := "unalias"
+	repl.Cmds[name] = &repl.CmdInfo{
+		Fn: UnaliasCommand,
+		Summary: "remove an alias or macro",
+		Synopsis: `unalias *name*`,
+		Help: `Removes alias or macro *name*, also from the init file.
+`,
+		SeeAlso: []string{"alias", "macro"},
+
+		Min_args: 1,
+		Max_args: 1,
+	}
+	repl.AddToCategory("support", name)
+}
+
+// UnaliasCommand implements the command:
+//    unalias *name*
+// which removes an alias or macro.
+func UnaliasCommand(args []string) {
+	if err := repl.Current.RemoveMacro(args[1]); err != nil {
+		repl.Errmsg("%s", err)
+		return
+	}
+	saveDefinition(args[1], nil)
+}
@@ -4,28 +4,55 @@
 package fishcmd
 
 import (
+	"fmt"
+	"go/ast"
 	"go/parser"
-	"github.com/rocky/go-fish"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
 )
 
 func init() {
 	name := "whatis"
 	repl.Cmds[name] = &repl.CmdInfo{
 		Fn: WhatisCommand,
-		Help: `whatis expression
+		Summary: "show the type of an expression",
+		Synopsis: `whatis expression`,
+		Help: `Shows the type checker information for an expression, and explores
+its type:
 
-Shows the type checker information for an expression
+ - the underlying type of named types
+ - struct fields with their types, tags and offsets
+ - the method sets of both T and *T
+ - the interfaces in the environment that T or *T implement
+ - channel direction and element type
+ - function signatures, with parameter names where documentation
+   is available
+
+When the expression is just a name, or a package-qualified name, we
+also say whether it is a package, constant, variable, function or type
+and where it comes from. Type names may be given too.
 `,
+		Examples: []string{
+			`whatis os.Stdout`,
+			`whatis strings.Builder`,
+			`whatis strings.Fields`,
+			`whatis env`,
+		},
+		SeeAlso: []string{"doc"},
 
 		Min_args: 0,
 		Max_args: -1,
+		RawArgs: true,
 	}
 	repl.AddToCategory("data", name)
 }
 
 func WhatisCommand(args []string) {
-	line := repl.CmdLine[len(args[0]):len(repl.CmdLine)]
+	line := repl.CmdRest
 	ctx  := &eval.Ctx{line}
 	if expr, err := parser.ParseExpr(line); err != nil {
 		if pair := eval.FormatErrorPos(line, err.Error()); len(pair) == 2 {
@@ -33,6 +60,8 @@ func WhatisCommand(args []string) {
 			repl.Msg(pair[1])
 		}
 		repl.Errmsg("parse error: %s\n", err)
+	} else if t, isType := whatisName(expr); isType {
+		describeType(t)
 	} else {
 		cexpr, errs := eval.CheckExpr(ctx, expr, repl.Env)
 		if len(errs) != 0 {
@@ -47,6 +76,9 @@ func WhatisCommand(args []string) {
 			knownTypes := cexpr.KnownType()
 			if len(knownTypes) == 1{
 				repl.Msg("type:\t%s", knownTypes[0])
+				if !cexpr.IsConst() {
+					describeType(knownTypes[0])
+				}
 			} else {
 				for i, v := range knownTypes {
 					repl.Msg("type[%d]:\t%s", i, v)
@@ -55,3 +87,185 @@ func WhatisCommand(args []string) {
 		}
 	}
 }
+
+// whatisName says what kind of thing expr is when it is a name or a
+// package-qualified name, and where it comes from. If the name is a
+// type, the type is returned along with true.
+func whatisName(expr ast.Expr) (reflect.Type, bool) {
+	env := repl.Env
+	name, where := "", "the top-level environment"
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+		if pkg, ok := env.Pkgs[name]; ok {
+			repl.Msg("%s is package %s, import path \"%s\"", name, pkg.Name,
+				pkg.Path)
+			return nil, false
+		}
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		pkg, ok := env.Pkgs[x.Name]
+		if !ok {
+			return nil, false
+		}
+		env = pkg
+		name = e.Sel.Name
+		where = fmt.Sprintf("package %s (\"%s\")", pkg.Name, pkg.Path)
+		if _, ok := env.Funcs[name]; ok {
+			// Show the signature with parameter names if we can.
+			if info, err := repl.LookupDoc(pkg.Path, name); err == nil {
+				repl.Msg("%s is a func from %s:\n  %s", x.Name+"."+name,
+					where, info.Decl)
+				return nil, false
+			}
+		}
+		name = x.Name + "." + name
+	default:
+		return nil, false
+	}
+	member := name[strings.LastIndex(name, ".")+1:]
+	if _, ok := env.Consts[member]; ok {
+		repl.Msg("%s is a const from %s", name, where)
+	} else if _, ok := env.Vars[member]; ok {
+		repl.Msg("%s is a var from %s", name, where)
+	} else if _, ok := env.Funcs[member]; ok {
+		repl.Msg("%s is a func from %s", name, where)
+	} else if t, ok := env.Types[member]; ok {
+		repl.Msg("%s is a type from %s", name, where)
+		return t, t != nil
+	}
+	return nil, false
+}
+
+// underlyingString describes the structure of t, which for named
+// types is their underlying type. Named component types are given by
+// name.
+func underlyingString(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + t.Elem().String()
+		case reflect.SendDir:
+			return "chan<- " + t.Elem().String()
+		}
+		return "chan " + t.Elem().String()
+	case reflect.Func:
+		return repl.FuncSignature("", t)
+	case reflect.Interface:
+		return fmt.Sprintf("interface { %d methods }", t.NumMethod())
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", t.Key(), t.Elem())
+	case reflect.Ptr:
+		return "*" + t.Elem().String()
+	case reflect.Slice:
+		return "[]" + t.Elem().String()
+	case reflect.Struct:
+		return fmt.Sprintf("struct { %d fields }", t.NumField())
+	}
+	return t.Kind().String()
+}
+
+// describeType shows the structure, method sets and the implemented
+// interfaces of t.
+func describeType(t reflect.Type) {
+	if t.Name() != "" {
+		if t.PkgPath() != "" {
+			repl.Msg("named type %s declared in package \"%s\"", t, t.PkgPath())
+		} else {
+			repl.Msg("predeclared type %s", t)
+		}
+	}
+	repl.Msg("underlying type:\t%s", underlyingString(t))
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.NumField() > 0 {
+			repl.Section("Fields of %s (size %d):", t, t.Size())
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			line := fmt.Sprintf("  %-4d %-20s %s", f.Offset, f.Name, f.Type)
+			if f.Anonymous {
+				line += " (embedded)"
+			}
+			if f.Tag != "" {
+				line += fmt.Sprintf(" `%s`", f.Tag)
+			}
+			if f.PkgPath != "" {
+				line += " (unexported)"
+			}
+			repl.Msg("%s", line)
+		}
+	case reflect.Chan:
+		repl.Msg("direction:\t%s", t.ChanDir())
+		repl.Msg("element type:\t%s", t.Elem())
+	case reflect.Func:
+		params := []string{}
+		for i := 0; i < t.NumIn(); i++ {
+			params = append(params, t.In(i).String())
+		}
+		repl.Msg("parameters:\t(%s)", strings.Join(params, ", "))
+		if t.IsVariadic() {
+			repl.Msg("variadic:\tyes")
+		}
+		results := []string{}
+		for i := 0; i < t.NumOut(); i++ {
+			results = append(results, t.Out(i).String())
+		}
+		repl.Msg("results:\t(%s)", strings.Join(results, ", "))
+	case reflect.Array, reflect.Slice, reflect.Ptr:
+		repl.Msg("element type:\t%s", t.Elem())
+	case reflect.Map:
+		repl.Msg("key type:\t%s", t.Key())
+		repl.Msg("element type:\t%s", t.Elem())
+	}
+
+	printMethodSet(t)
+	if t.Kind() != reflect.Interface && t.Kind() != reflect.Ptr {
+		printMethodSet(reflect.PtrTo(t))
+	}
+	printImplements(t)
+	if t.Kind() != reflect.Interface && t.Kind() != reflect.Ptr {
+		printImplements(reflect.PtrTo(t))
+	}
+}
+
+func printMethodSet(t reflect.Type) {
+	if t.NumMethod() == 0 {
+		return
+	}
+	repl.Section("Method set of %s:", t)
+	for i := 0; i < t.NumMethod(); i++ {
+		repl.Msg("  %s", repl.MethodSignature(t, t.Method(i)))
+	}
+}
+
+// printImplements shows the interface types in the environment, other
+// than the empty interface, that t implements.
+func printImplements(t reflect.Type) {
+	names := []string{}
+	check := func(name string, it reflect.Type) {
+		if it != nil && it.Kind() == reflect.Interface && it.NumMethod() > 0 &&
+			it != t && t.Implements(it) {
+			names = append(names, name)
+		}
+	}
+	for name, it := range repl.Env.Types {
+		check(name, it)
+	}
+	for pkgName, pkg := range repl.Env.Pkgs {
+		for name, it := range pkg.Types {
+			check(pkgName+"."+name, it)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		repl.PrintSorted(t.String()+" implements", names)
+	}
+}
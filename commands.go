@@ -2,15 +2,57 @@
 
 package repl
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type CmdFunc func([]string)
 
 type CmdInfo struct {
+	// Summary says in a few words what the command does, for the
+	// list of commands that "help" shows.
+	Summary string
+
+	// Synopsis shows how the command is used, as in "quit
+	// [exit-code]", with a line for each form. If it is empty, it
+	// is generated from Args.
+	Synopsis string
+
+	// Help describes the command; see Markup for how it is shown.
 	Help string
+
+	// Examples are lines showing the command in use.
+	Examples []string
+
+	// SeeAlso names related commands.
+	SeeAlso []string
+
+	// Category is set by AddToCategory.
 	Category string
+
 	Min_args int
 	Max_args int
 	Fn CmdFunc
 	Aliases []string
+
+	// MinAbbrev is the length of the shortest prefix of the command's
+	// name that may be used for it, so that a command which does
+	// something drastic isn't run by accident. Zero means any prefix
+	// that no other command shares.
+	MinAbbrev int
+
+	// RawArgs is set for commands that take Go code or a shell command
+	// from CmdRest. Their arguments are split at white space, without
+	// regard to quotes or backslashes.
+	RawArgs bool
+
+	// Args, if not nil, describes the command's arguments and
+	// options. They are then checked and converted into CmdArgs
+	// before the command runs, and the usage line in help and tab
+	// completion come from them; Min_args and Max_args are ignored.
+	Args []*ArgSpec
 	// SubcmdMgr *SubcmdMgr
 }
 
@@ -27,28 +69,90 @@ var	Aliases map[string]string = make(map[string]string)
 // REPL commands in that category.
 var	Categories map[string] []string = make(map[string] []string)
 
-// AddAlias adds "alias" for a command name "cmdname"
-func AddAlias(alias string, cmdname string) bool {
-	if unalias := Aliases[alias]; unalias != "" {
-		return false
+// AddAlias adds "alias" for a command name "cmdname". It is an error
+// if "alias" is already a command or an alias, or if there is no
+// command "cmdname".
+func AddAlias(alias string, cmdname string) error {
+	if other := Aliases[alias]; other != "" {
+		return fmt.Errorf("%s is already an alias for command %s", alias, other)
+	}
+	if Cmds[alias] != nil {
+		return fmt.Errorf("%s is already a command", alias)
+	}
+	cmd := Cmds[cmdname]
+	if cmd == nil {
+		return fmt.Errorf("can't add alias %s for unknown command %s", alias,
+			cmdname)
 	}
 	Aliases[alias] = cmdname
-	Cmds[cmdname].Aliases = append(Cmds[cmdname].Aliases, alias)
-	return true
+	cmd.Aliases = append(cmd.Aliases, alias)
+	return nil
+}
+
+// MustAddAlias is like AddAlias but panics if the alias can't be
+// added. It is for the aliases that commands add in init functions,
+// where a conflict is a mistake in go-fish itself.
+func MustAddAlias(alias string, cmdname string) {
+	if err := AddAlias(alias, cmdname); err != nil {
+		panic("go-fish: " + err.Error())
+	}
 }
 
 // AddToCategory adds "cmdname" into general category "category".
 func AddToCategory(category string, cmdname string) {
 	Categories[category] = append(Categories[category], cmdname)
-	// Cmds[cmdname].category = category
+	if cmd := Cmds[cmdname]; cmd != nil {
+		cmd.Category = category
+	}
 }
 
 
 // LookupCmd canonicalize parameter cmd, by changing it to the underlying
-// gofish command if it is an alias.
+// gofish command if it is an alias or a unique abbreviation. It
+// returns "" if cmd isn't any of these.
 func LookupCmd(cmd string) (string) {
-	if Cmds[cmd] == nil {
-		cmd = Aliases[cmd];
+	if Cmds[cmd] != nil {
+		return cmd
+	}
+	if name := Aliases[cmd]; name != "" {
+		return name
+	}
+	if names := CmdCandidates(cmd); len(names) == 1 {
+		return names[0]
+	}
+	return ""
+}
+
+// CmdCandidates returns the sorted names of the commands that
+// "prefix" could be an abbreviation of, taking their aliases and
+// MinAbbrev into account.
+func CmdCandidates(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	var names []string
+	for name, cmd := range Cmds {
+		if cmd.abbreviates(name, prefix) {
+			names = append(names, name)
+			continue
+		}
+		for _, alias := range cmd.Aliases {
+			if strings.HasPrefix(alias, prefix) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// abbreviates reports whether prefix is long enough to stand for
+// command "name".
+func (cmd *CmdInfo) abbreviates(name, prefix string) bool {
+	min := cmd.MinAbbrev
+	if min > len(name) {
+		min = len(name)
 	}
-	return cmd
+	return len(prefix) >= min && strings.HasPrefix(name, prefix)
 }
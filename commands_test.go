@@ -0,0 +1,145 @@
+package repl_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestAbbrev(t *testing.T) {
+	lookups := map[string]string{"history": "history", "hist": "history",
+		"q": "quit", "qui": "", "sh": "", "nosuch": ""}
+	for abbrev, want := range lookups {
+		if got := repl.LookupCmd(abbrev); got != want {
+			t.Errorf("LookupCmd(%q): expecting %q; got %q", abbrev, want, got)
+		}
+	}
+
+	s := repl.NewSession(nil, nil, nil)
+	if err := s.Define("hi", 5); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line    string
+		command bool
+		output  string
+	}{
+		{"hist 1", true, "hist 1"},
+		{"sh", true, "Ambiguous command sh: shell, show"},
+		{"hi", false, ""},
+		{"his + 1", false, ""},
+		{"len(\"hist\")", false, ""},
+	}
+	for _, test := range tests {
+		c := s.Capture(test.line, true)
+		if command := c.Result == nil; command != test.command {
+			t.Errorf("%s: expecting command %v; got %v", test.line,
+				test.command, command)
+		}
+		if !strings.Contains(c.Output, test.output) {
+			t.Errorf("%s: expecting %q in output; got %q", test.line,
+				test.output, c.Output)
+		}
+	}
+}
+
+func TestCmdPrefix(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	if err := s.Define("h", 5); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line    string
+		command bool
+		output  string
+	}{
+		{":hist 1", true, ":hist 1"},
+		{": history 1", true, ": history 1"},
+		{":nosuch", true, "Unknown command nosuch"},
+		{"h", false, "h is a Go name as well as a command"},
+		{"h", false, ""},
+		{"h quit", true, "quit [exit-code]"},
+		{":h", true, "Working with go-fish itself"},
+		{":set legacy off", true, ""},
+		{"history", false, ""},
+		{":set cmdprefix off", true, "commands need a prefix"},
+		{":set cmdprefix x", true, "can't contain 'x'"},
+	}
+	for _, test := range tests {
+		c := s.Capture(test.line, true)
+		if command := c.Result == nil; command != test.command {
+			t.Errorf("%s: expecting command %v; got %v", test.line,
+				test.command, command)
+		}
+		if !strings.Contains(c.Output, test.output) {
+			t.Errorf("%s: expecting %q in output; got %q", test.line,
+				test.output, c.Output)
+		}
+	}
+	if strings.Contains(s.Capture("h", true).Output, "Go name") {
+		t.Errorf("expecting to be warned about h only once")
+	}
+
+	// Other sessions keep their own prefix and legacy mode.
+	s.Capture(":set cmdprefix %", true)
+	other := repl.NewSession(nil, nil, nil)
+	if c := other.Capture("history", true); c.Result != nil {
+		t.Errorf("expecting another session to be in legacy mode")
+	}
+	if c := other.Capture("%history", true); c.Result == nil {
+		t.Errorf("expecting another session's prefix to be unchanged")
+	}
+	if repl.CmdPrefix != ":" || !repl.LegacyCommands {
+		t.Errorf("expecting the settings outside of sessions to be unchanged")
+	}
+	if c := s.Capture("%history", true); c.Result != nil {
+		t.Errorf("expecting the session to keep its prefix")
+	}
+
+	width := repl.Maxwidth
+	s.Capture("%set width 40", true)
+	if out := other.Capture("show width", true).Output; !strings.Contains(out,
+		"width is "+strconv.Itoa(width)) {
+		t.Errorf("expecting another session's width to be unchanged; got %q",
+			out)
+	}
+	if out := s.Capture("%show width", true).Output; !strings.Contains(out,
+		"width is 40") {
+		t.Errorf("expecting the session to keep its width; got %q", out)
+	}
+}
+
+func TestShellEscape(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	if err := s.Define("ok", true); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"!ok":                           false,
+		`!strings.HasPrefix("ab", "a")`: false,
+		"!(1 < 2)":                      false,
+		"!echo hi":                      true,
+		"!ls -l":                        true,
+		":!ok":                          true,
+	}
+	for line, command := range tests {
+		c := s.Capture(line, true)
+		if got := c.Result == nil; got != command {
+			t.Errorf("%s: expecting command %v; got %v", line, command, got)
+		}
+	}
+}
+
+func TestPackagesFilter(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	for _, line := range []string{"packages -f 'Trim*' strings",
+		`packages -f "Trim*" strings`, "packages -f Trim* strings"} {
+		out := s.Capture(line, true).Output
+		if !strings.Contains(out, "TrimSuffix") || strings.Contains(out, "Fields") {
+			t.Errorf("%s: expecting only the Trim functions; got %q", line, out)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2014 Rocky Bernstein.
+// Name completion
+
+package repl
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/0xfaded/eval"
+)
+
+// builtinNames are the predeclared Go names other than types.
+var builtinNames = []string{
+	"append", "cap", "close", "complex", "copy", "delete", "false", "imag",
+	"len", "make", "new", "nil", "panic", "print", "println", "real",
+	"recover", "true",
+}
+
+// Complete returns completions for the word ending at byte offset
+// pos of line, along with the offset where that word starts. A word
+// is a Go identifier, possibly qualified by a package name or by an
+// expression and a dot. When the word starts the line, REPL command
+// names are candidates too. Candidates are whole words, meant to
+// replace line[start:pos].
+func Complete(env *eval.Env, line string, pos int) (int, []string) {
+	if pos < 0 || pos > len(line) {
+		pos = len(line)
+	}
+	if start, candidates, ok := completeArg(line, pos); ok {
+		sort.Strings(candidates)
+		return start, candidates
+	}
+	start := pos
+	for start > 0 {
+		r := rune(line[start-1])
+		if r != '.' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) &&
+			r < 0x80 {
+			break
+		}
+		start--
+	}
+	word := line[start:pos]
+
+	candidates := []string{}
+	add := func(prefix, name string) {
+		if strings.HasPrefix(name, word[len(prefix):]) {
+			candidates = append(candidates, prefix+name)
+		}
+	}
+	if dot := strings.LastIndex(word, "."); dot >= 0 {
+		prefix := word[:dot+1]
+		for _, name := range memberNames(env, word[:dot]) {
+			add(prefix, name)
+		}
+	} else {
+		for _, names := range [][]string{
+			valueNames(env.Vars), valueNames(env.Consts), valueNames(env.Funcs),
+			builtinNames,
+		} {
+			for _, name := range names {
+				add("", name)
+			}
+		}
+		for name := range env.Types {
+			add("", name)
+		}
+		for name := range env.Pkgs {
+			add("", name)
+		}
+		if before := strings.TrimSpace(line[:start]); before == "" ||
+			(CmdPrefix != "" && before == CmdPrefix) {
+			for name := range Cmds {
+				add("", name)
+			}
+			for name := range Aliases {
+				add("", name)
+			}
+		}
+	}
+	sort.Strings(candidates)
+	unique := candidates[:0]
+	for i, c := range candidates {
+		if i == 0 || c != candidates[i-1] {
+			unique = append(unique, c)
+		}
+	}
+	return start, unique
+}
+
+// Complete returns completions as the function Complete does, in
+// session s's environment and with its settings, such as CmdPrefix,
+// in place.
+func (s *Session) Complete(line string, pos int) (start int, candidates []string) {
+	s.run(func() { start, candidates = Complete(s.Env, line, pos) })
+	return start, candidates
+}
+
+// completeArg completes the word ending at pos of line if it is an
+// argument of a command with CmdInfo.Args that argCandidates knows
+// what to do with.
+func completeArg(line string, pos int) (int, []string, bool) {
+	text := strings.TrimLeft(line[:pos], " \t")
+	if CmdPrefix != "" {
+		text = strings.TrimPrefix(text, CmdPrefix)
+	}
+	words := strings.Fields(text)
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	word := line[start:pos]
+	// The word being completed is the last of words, unless it is only
+	// the command prefix, which was trimmed from them.
+	if word != "" && len(words) > 0 {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return 0, nil, false
+	}
+	cmd := Cmds[LookupCmd(words[0])]
+	if cmd == nil || cmd.Args == nil {
+		return 0, nil, false
+	}
+	candidates, ok := argCandidates(cmd.Args, words[1:], word)
+	return start, candidates, ok
+}
+
+func valueNames(m map[string]reflect.Value) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// memberNames gives the names that can follow "x." in env: the
+// members of package x, or the fields and methods of the type of
+// expression x.
+func memberNames(env *eval.Env, x string) []string {
+	names := []string{}
+	if pkg, ok := env.Pkgs[x]; ok {
+		names = append(names, valueNames(pkg.Vars)...)
+		names = append(names, valueNames(pkg.Consts)...)
+		names = append(names, valueNames(pkg.Funcs)...)
+		for name := range pkg.Types {
+			names = append(names, name)
+		}
+		return names
+	}
+	t, err := TypeOfExpr(env, x)
+	if err != nil {
+		return names
+	}
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		t = reflect.PtrTo(t)
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		names = append(names, t.Method(i).Name)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" {
+				names = append(names, f.Name)
+			}
+		}
+	}
+	return names
+}
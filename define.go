@@ -0,0 +1,195 @@
+// Copyright 2014 Rocky Bernstein.
+// Defining host program values in a session
+
+package repl
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"reflect"
+
+	"github.com/0xfaded/eval"
+)
+
+// checkNewName returns an error if name isn't a Go identifier or is
+// already defined at the top level of env.
+func checkNewName(env *eval.Env, name string) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("%q is not a Go identifier", name)
+	}
+	if _, ok := env.Vars[name]; ok {
+		return fmt.Errorf("%s is already defined as a variable", name)
+	}
+	if _, ok := env.Consts[name]; ok {
+		return fmt.Errorf("%s is already defined as a constant", name)
+	}
+	if _, ok := env.Funcs[name]; ok {
+		return fmt.Errorf("%s is already defined as a function", name)
+	}
+	if _, ok := env.Types[name]; ok {
+		return fmt.Errorf("%s is already defined as a type", name)
+	}
+	if pkg, ok := env.Pkgs[name]; ok {
+		return fmt.Errorf("%s is already defined as package \"%s\"", name,
+			pkg.Path)
+	}
+	return nil
+}
+
+// Define makes a variable "name" in the session holding a copy of
+// value. Use DefineVar to share a variable with the host program.
+func (s *Session) Define(name string, value interface{}) error {
+	if err := checkNewName(s.Env, name); err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("%s: can't define a variable from untyped nil", name)
+	}
+	v := reflect.ValueOf(value)
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	s.Env.Vars[name] = ptr
+	return nil
+}
+
+// SetVar sets variable "name" in the session to a copy of value, and
+// defines it as Define does if there is no such variable yet. An
+// existing variable must have the same type as value.
+func (s *Session) SetVar(name string, value interface{}) error {
+	ptr, ok := s.Env.Vars[name]
+	if !ok {
+		return s.Define(name, value)
+	}
+	if value == nil {
+		return fmt.Errorf("%s: can't set a variable to untyped nil", name)
+	}
+	v := reflect.ValueOf(value)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Type() != v.Type() {
+		return fmt.Errorf("%s is a %s, not a %s", name, ptr.Type(), v.Type())
+	}
+	ptr.Elem().Set(v)
+	return nil
+}
+
+// DefineVar makes variable "name" in the session refer to what ptr
+// points to, so changes made by either the host program or at the
+// prompt are seen by both.
+func (s *Session) DefineVar(name string, ptr interface{}) error {
+	if err := checkNewName(s.Env, name); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%s: expecting a non-nil pointer to a variable; got %T",
+			name, ptr)
+	}
+	s.Env.Vars[name] = v
+	return nil
+}
+
+// DefineFunc makes function fn callable as "name" in the session.
+func (s *Session) DefineFunc(name string, fn interface{}) error {
+	if err := checkNewName(s.Env, name); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("%s: expecting a function; got %T", name, fn)
+	}
+	s.Env.Funcs[name] = v
+	return nil
+}
+
+// DefineType makes the type of "sample" available as "name" in the
+// session. To give an interface type, pass a pointer to it, e.g.
+// (*io.Reader)(nil); a reflect.Type can be given too.
+func (s *Session) DefineType(name string, sample interface{}) error {
+	if err := checkNewName(s.Env, name); err != nil {
+		return err
+	}
+	t, err := sampleType(sample)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	s.Env.Types[name] = t
+	return nil
+}
+
+// sampleType gives the type that DefineType should define for sample.
+func sampleType(sample interface{}) (reflect.Type, error) {
+	if t, ok := sample.(reflect.Type); ok {
+		return t, nil
+	}
+	if sample == nil {
+		return nil, fmt.Errorf("can't get a type from untyped nil")
+	}
+	t := reflect.TypeOf(sample)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+		return t.Elem(), nil
+	}
+	return t, nil
+}
+
+// DefinePackage makes a package with import path "path" available
+// as "name" in the session. Each member is filed by what it is:
+//
+//   - a reflect.Type is a type
+//   - a function is a function
+//   - a pointer is a variable referring to what it points to
+//   - anything else is a constant
+//
+// For example:
+//    s.DefinePackage("srv", "example.com/server", map[string]interface{}{
+//        "Config":  &config,
+//        "Restart": restart,
+//        "Handler": reflect.TypeOf(new(server.Handler)).Elem(),
+//        "Version": server.Version,
+//    })
+func (s *Session) DefinePackage(name, pkgPath string,
+	members map[string]interface{}) error {
+	if err := checkNewName(s.Env, name); err != nil {
+		return err
+	}
+	if old, ok := Packages[pkgPath]; ok {
+		return fmt.Errorf("package \"%s\" is already defined as %s", pkgPath,
+			old.Name)
+	}
+	pkg := &eval.Env{
+		Name:   path.Base(pkgPath),
+		Path:   pkgPath,
+		Vars:   make(map[string]reflect.Value),
+		Consts: make(map[string]reflect.Value),
+		Funcs:  make(map[string]reflect.Value),
+		Types:  make(map[string]reflect.Type),
+		Pkgs:   s.Env.Pkgs,
+	}
+	for member, value := range members {
+		if !token.IsIdentifier(member) {
+			return fmt.Errorf("%q is not a Go identifier", member)
+		}
+		if t, ok := value.(reflect.Type); ok {
+			pkg.Types[member] = t
+			continue
+		}
+		if value == nil {
+			return fmt.Errorf("%s.%s: can't define from untyped nil", name,
+				member)
+		}
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Func:
+			pkg.Funcs[member] = v
+		case reflect.Ptr:
+			pkg.Vars[member] = v
+		default:
+			pkg.Consts[member] = v
+		}
+	}
+	Packages[pkgPath] = pkg
+	if name != pkg.Name {
+		PkgAliases[pkgPath] = name
+	}
+	s.Env.Pkgs[name] = pkg
+	return nil
+}
@@ -0,0 +1,66 @@
+package repl_test
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/rocky/go-fish"
+)
+
+func TestDefine(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+
+	count := 1
+	if err := s.DefineVar("count", &count); err != nil {
+		t.Fatal(err)
+	}
+	s.Env.Vars["count"].Elem().SetInt(5)
+	if count != 5 {
+		t.Errorf("DefineVar: expecting count to be shared; got %d", count)
+	}
+
+	if err := s.Define("answer", 42); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Env.Vars["answer"].Elem().Interface(); got != 42 {
+		t.Errorf("Define: expecting 42; got %v", got)
+	}
+
+	clashes := []error{
+		s.Define("count", 1),
+		s.DefineFunc("answer", func() {}),
+		s.DefineType("results", 0),
+		s.DefinePackage("answer", "example.com/answer", nil),
+		s.Define("not an identifier", 1),
+		s.DefineVar("notPointer", count),
+	}
+	for i, err := range clashes {
+		if err == nil {
+			t.Errorf("case %d: expecting an error", i)
+		}
+	}
+
+	if err := s.DefineType("Reader", (*io.Reader)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if kind := s.Env.Types["Reader"].Kind(); kind != reflect.Interface {
+		t.Errorf("DefineType: expecting an interface type; got %s", kind)
+	}
+
+	err := s.DefinePackage("answers", "example.com/answers",
+		map[string]interface{}{
+			"Count":    &count,
+			"Double":   func(i int) int { return 2 * i },
+			"Universe": 42,
+			"Reader":   reflect.TypeOf(new(io.Reader)).Elem(),
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := s.Env.Pkgs["answers"]
+	if len(pkg.Vars) != 1 || len(pkg.Funcs) != 1 || len(pkg.Consts) != 1 ||
+		len(pkg.Types) != 1 {
+		t.Errorf("DefinePackage: members filed wrong: %v", pkg)
+	}
+}
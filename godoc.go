@@ -0,0 +1,336 @@
+// Copyright 2014 Rocky Bernstein.
+// Go documentation lookup
+
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/0xfaded/eval"
+)
+
+// Docs holds documentation embedded at build time by "make_env -docs"
+// so that we have something to show when package source is not
+// around. Keys are an import path, e.g. "strings"; an import path and
+// member, e.g. "strings.Fields"; or an import path, type name and
+// method, e.g. "strings.Reader.Len". Values are a declaration, a
+// blank line, and then the doc comment text.
+var Docs map[string]string = make(map[string]string)
+
+// DocExample is a runnable example found in a package's test files.
+type DocExample struct {
+	Name   string
+	Code   string
+	Output string
+}
+
+// DocInfo is the documentation for a package or one of its members.
+type DocInfo struct {
+	Decl     string // declaration, e.g. "func Fields(s string) []string"
+	Doc      string // doc comment text
+	Examples []DocExample
+}
+
+// srcDoc is the documentation extracted from a package's Go source.
+type srcDoc struct {
+	pkg      *doc.Package
+	fset     *token.FileSet
+	examples []*doc.Example
+}
+
+// srcDocs caches srcDoc's by import path.
+var srcDocs map[string]*srcDoc = make(map[string]*srcDoc)
+
+// loadSrcDoc finds the source for the package with import path "path"
+// in GOROOT, GOPATH or the module cache, and extracts its
+// documentation.
+func loadSrcDoc(path string) (*srcDoc, error) {
+	if d, ok := srcDocs[path]; ok {
+		return d, nil
+	}
+	bpkg, err := build.Import(path, initial_cwd, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := make(map[string]*ast.File)
+	for _, name := range bpkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, name),
+			nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = file
+	}
+	testNames := []string{}
+	testNames = append(testNames, bpkg.TestGoFiles...)
+	testNames = append(testNames, bpkg.XTestGoFiles...)
+	testFiles := []*ast.File{}
+	for _, name := range testNames {
+		file, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, name),
+			nil, parser.ParseComments)
+		if err == nil {
+			testFiles = append(testFiles, file)
+		}
+	}
+	astPkg := &ast.Package{Name: bpkg.Name, Files: files}
+	d := &srcDoc{
+		pkg:      doc.New(astPkg, path, 0),
+		fset:     fset,
+		examples: doc.Examples(testFiles...),
+	}
+	srcDocs[path] = d
+	return d, nil
+}
+
+// LookupDoc returns the documentation for the package with import
+// path "path" when no names are given, for member names[0] of that
+// package, or for method names[1] of type names[0]. Package source is
+// consulted first and then the documentation embedded in Docs.
+func LookupDoc(path string, names ...string) (*DocInfo, error) {
+	if len(names) > 2 {
+		return nil, fmt.Errorf("too many names in %s.%s", path,
+			strings.Join(names, "."))
+	}
+	if d, err := loadSrcDoc(path); err == nil {
+		if info := d.lookup(names); info != nil {
+			return info, nil
+		}
+	}
+	key := strings.Join(append([]string{path}, names...), ".")
+	if text, ok := Docs[key]; ok {
+		info := &DocInfo{Decl: text}
+		if i := strings.Index(text, "\n\n"); i >= 0 {
+			info.Decl, info.Doc = text[:i], text[i+2:]
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("no documentation found for %s", key)
+}
+
+// lookup finds the documentation for names in d. nil is returned if
+// there is no such package member.
+func (d *srcDoc) lookup(names []string) *DocInfo {
+	if len(names) == 0 {
+		return &DocInfo{
+			Decl: fmt.Sprintf("package %s // import \"%s\"",
+				d.pkg.Name, d.pkg.ImportPath),
+			Doc:      d.pkg.Doc,
+			Examples: d.findExamples(""),
+		}
+	}
+	name := names[0]
+	if len(names) == 2 {
+		for _, t := range d.pkg.Types {
+			if t.Name != name {
+				continue
+			}
+			for _, m := range t.Methods {
+				if m.Name == names[1] {
+					return d.funcInfo(m, name+"_"+m.Name)
+				}
+			}
+		}
+		return nil
+	}
+	for _, f := range d.pkg.Funcs {
+		if f.Name == name {
+			return d.funcInfo(f, name)
+		}
+	}
+	if info := d.valueInfo(d.pkg.Consts, name); info != nil {
+		return info
+	}
+	if info := d.valueInfo(d.pkg.Vars, name); info != nil {
+		return info
+	}
+	for _, t := range d.pkg.Types {
+		if t.Name == name {
+			decl := *t.Decl
+			decl.Doc = nil
+			return &DocInfo{
+				Decl:     d.nodeString(&decl),
+				Doc:      t.Doc,
+				Examples: d.findExamples(name),
+			}
+		}
+		// go/doc files constructors, and constants and variables
+		// of type t, under t.
+		for _, f := range t.Funcs {
+			if f.Name == name {
+				return d.funcInfo(f, name)
+			}
+		}
+		if info := d.valueInfo(t.Consts, name); info != nil {
+			return info
+		}
+		if info := d.valueInfo(t.Vars, name); info != nil {
+			return info
+		}
+	}
+	return nil
+}
+
+func (d *srcDoc) funcInfo(f *doc.Func, exampleName string) *DocInfo {
+	decl := *f.Decl
+	decl.Doc = nil
+	decl.Body = nil
+	return &DocInfo{
+		Decl:     d.nodeString(&decl),
+		Doc:      f.Doc,
+		Examples: d.findExamples(exampleName),
+	}
+}
+
+func (d *srcDoc) valueInfo(values []*doc.Value, name string) *DocInfo {
+	for _, v := range values {
+		for _, n := range v.Names {
+			if n == name {
+				decl := *v.Decl
+				decl.Doc = nil
+				return &DocInfo{Decl: d.nodeString(&decl), Doc: v.Doc}
+			}
+		}
+	}
+	return nil
+}
+
+// findExamples returns the examples for name, which is "" for package
+// examples, "F" for function or type F, and "T_M" for method M of
+// type T. Examples named with a lower-case suffix, e.g. "F_second",
+// are included too.
+func (d *srcDoc) findExamples(name string) []DocExample {
+	examples := []DocExample{}
+	for _, ex := range d.examples {
+		if ex.Name != name {
+			suffix := strings.TrimPrefix(ex.Name, name+"_")
+			if name == "" {
+				suffix = strings.TrimPrefix(ex.Name, "_")
+			}
+			if suffix == ex.Name || suffix == "" {
+				continue
+			}
+			if r, _ := utf8.DecodeRuneInString(suffix); !unicode.IsLower(r) {
+				continue
+			}
+		}
+		examples = append(examples, DocExample{
+			Name:   ex.Name,
+			Code:   d.nodeString(ex.Code),
+			Output: ex.Output,
+		})
+	}
+	return examples
+}
+
+func (d *srcDoc) nodeString(node interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, d.fset, node); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// ResolveDocName turns what was given to the "doc" command,
+//    package | package.symbol | package.Type.method | expression.method
+// into an import path and the names to give LookupDoc. A package is
+// either a package name known in env or the import path of a known package.
+func ResolveDocName(env *eval.Env, what string) (string, []string, error) {
+	if pkg, ok := env.Pkgs[what]; ok {
+		return pkg.Path, nil, nil
+	}
+	if isImportPath(env, what) {
+		return what, nil, nil
+	}
+	dot := strings.LastIndex(what, ".")
+	if dot < 0 {
+		if v, ok := env.Vars[what]; ok {
+			return typeDocName(what, v.Type().Elem())
+		}
+		return "", nil, fmt.Errorf("%s is not a package or a variable", what)
+	}
+	prefix, name := what[:dot], what[dot+1:]
+	if pkg, ok := env.Pkgs[prefix]; ok {
+		return pkg.Path, []string{name}, nil
+	}
+	if isImportPath(env, prefix) {
+		return prefix, []string{name}, nil
+	}
+	if pkgDot := strings.Index(prefix, "."); pkgDot > 0 {
+		if pkg, ok := env.Pkgs[prefix[:pkgDot]]; ok {
+			if _, ok := pkg.Types[prefix[pkgDot+1:]]; ok {
+				return pkg.Path, []string{prefix[pkgDot+1:], name}, nil
+			}
+		}
+	}
+	var t reflect.Type
+	if v, ok := env.Vars[prefix]; ok {
+		t = v.Type().Elem()
+	} else {
+		var err error
+		if t, err = TypeOfExpr(env, prefix); err != nil {
+			return "", nil, err
+		}
+	}
+	path, names, err := typeDocName(prefix, t)
+	return path, append(names, name), err
+}
+
+// isImportPath reports whether path is the import path of a package
+// compiled into go-fish or known in env.
+func isImportPath(env *eval.Env, path string) bool {
+	if _, ok := Packages[path]; ok {
+		return true
+	}
+	for _, pkg := range env.Pkgs {
+		if pkg.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeOfExpr returns the type the type checker gives to expression
+// expr in env. An error is returned if expr doesn't check or doesn't
+// have exactly one type.
+func TypeOfExpr(env *eval.Env, expr string) (reflect.Type, error) {
+	ctx := &eval.Ctx{expr}
+	pexpr, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %s", err)
+	}
+	cexpr, errs := eval.CheckExpr(ctx, pexpr, env)
+	if len(errs) != 0 {
+		return nil, errs[0]
+	}
+	knownTypes := cexpr.KnownType()
+	if len(knownTypes) != 1 {
+		return nil, fmt.Errorf("%s does not have a single type", expr)
+	}
+	return knownTypes[0], nil
+}
+
+// typeDocName gives the import path and type name under which
+// documentation for type t, the type of expr, can be found.
+func typeDocName(expr string, t reflect.Type) (string, []string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" || t.Name() == "" {
+		return "", nil, fmt.Errorf(
+			"%s has type %s which is not a named package type", expr, t)
+	}
+	return t.PkgPath(), []string{t.Name()}, nil
+}
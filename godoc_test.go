@@ -0,0 +1,59 @@
+package repl_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func TestResolveDocName(t *testing.T) {
+	html := &eval.Env{Name: "html", Path: "golang.org/x/net/html"}
+	env := &eval.Env{Pkgs: map[string]eval.Pkg{"html": html}}
+	tests := []struct {
+		what, path string
+		names      []string
+	}{
+		{"html", "golang.org/x/net/html", nil},
+		{"golang.org/x/net/html", "golang.org/x/net/html", nil},
+		{"golang.org/x/net/html.Parse", "golang.org/x/net/html", []string{"Parse"}},
+	}
+	for _, test := range tests {
+		path, names, err := repl.ResolveDocName(env, test.what)
+		if err != nil || path != test.path || !reflect.DeepEqual(names, test.names) {
+			t.Errorf("%s: expecting %s %v; got %s %v, %v", test.what, test.path,
+				test.names, path, names, err)
+		}
+	}
+	if _, _, err := repl.ResolveDocName(env, "no/such/package"); err == nil {
+		t.Errorf("expecting an error for an unknown import path")
+	}
+}
+
+func TestLookupDoc(t *testing.T) {
+	info, err := repl.LookupDoc("strings", "Fields")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Decl != "func Fields(s string) []string" ||
+		!strings.Contains(info.Doc, "white space") {
+		t.Errorf("strings.Fields: unexpected documentation %+v", info)
+	}
+	if _, err := repl.LookupDoc("strings", "NoSuchFunc"); err == nil {
+		t.Errorf("strings.NoSuchFunc: expecting an error")
+	}
+
+	// What "make_env -docs" embeds is used when there is no source.
+	repl.Docs["example.com/nosource.F"] = "func F()\n\nF does nothing."
+	defer delete(repl.Docs, "example.com/nosource.F")
+	info, err = repl.LookupDoc("example.com/nosource", "F")
+	if err != nil || info.Decl != "func F()" || info.Doc != "F does nothing." {
+		t.Errorf("example.com/nosource.F: unexpected documentation %+v, %v",
+			info, err)
+	}
+	if _, err := repl.LookupDoc("example.com/nosource", "G"); err == nil {
+		t.Errorf("example.com/nosource.G: expecting an error")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 Rocky Bernstein.
+// Showing help for commands
+
+package repl
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// CategoryHelp says what the commands in each category are for.
+var CategoryHelp = map[string]string{
+	"data":    "Looking at packages, types and values",
+	"running": "Measuring and controlling go-fish as it runs",
+	"support": "Working with go-fish itself",
+}
+
+// CategoryNames returns the names of the categories of commands in
+// order.
+func CategoryNames() []string {
+	names := []string{}
+	for name := range Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// emphasis matches a word marked for emphasis in help text.
+var emphasis = regexp.MustCompile(`\*([\pL_][\pL\pN_.-]*)\*`)
+
+// Markup renders help text for the terminal. Text is in paragraphs
+// separated by blank lines. A paragraph with a line longer than
+// Maxwidth is filled again to fit, unless it has lines that start
+// with a space, such as examples and tables, which are kept as they
+// are. *Emphasized* words are underlined when highlighting is on, and
+// keep their asterisks otherwise.
+func Markup(text string) string {
+	paragraphs := strings.Split(strings.Trim(text, "\n"), "\n\n")
+	for i, p := range paragraphs {
+		if needsFill(p) {
+			paragraphs[i] = fill(p, Maxwidth)
+		}
+	}
+	return emphasize(strings.Join(paragraphs, "\n\n"))
+}
+
+// emphasize underlines the *emphasized* words in text when
+// highlighting is on.
+func emphasize(text string) string {
+	if !*Highlight {
+		return text
+	}
+	underline := ansi.ColorCode("+u")
+	return emphasis.ReplaceAllString(text, underline+"$1"+termReset)
+}
+
+// needsFill reports whether paragraph p should be filled to fit
+// Maxwidth.
+func needsFill(p string) bool {
+	long := false
+	for _, line := range strings.Split(p, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			return false
+		}
+		if len(line) > Maxwidth {
+			long = true
+		}
+	}
+	return long
+}
+
+// fill joins the words of p into lines at most width long, unless a
+// word is longer than that.
+func fill(p string, width int) string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(p) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+// HelpText returns the help for command "name": its synopsis,
+// description, examples, related commands and aliases, rendered with
+// Markup, except that lines of the synopsis aren't filled.
+func (cmd *CmdInfo) HelpText(name string) string {
+	sections := []string{}
+	if help := strings.Trim(cmd.Help, "\n"); help != "" {
+		sections = append(sections, help)
+	}
+	if len(cmd.Examples) > 0 {
+		sections = append(sections,
+			"Examples:\n   "+strings.Join(cmd.Examples, "\n   "))
+	}
+	if len(cmd.SeeAlso) > 0 {
+		sections = append(sections,
+			"See also: "+strings.Join(cmd.SeeAlso, ", ")+".")
+	}
+	if len(cmd.Aliases) > 0 {
+		sections = append(sections,
+			"Aliases: "+strings.Join(cmd.Aliases, ", "))
+	}
+	text := Markup(strings.Join(sections, "\n\n"))
+	// Each form of the command is kept on a line of its own.
+	if usage := emphasize(cmd.Usage(name)); text == "" {
+		text = usage
+	} else if usage != "" {
+		text = usage + "\n\n" + text
+	}
+	return text
+}
+
+// SearchHelp returns the sorted names of the commands whose name or
+// help mentions term, ignoring case.
+func SearchHelp(term string) []string {
+	term = strings.ToLower(term)
+	names := []string{}
+	for name, cmd := range Cmds {
+		text := strings.Join(append([]string{name, cmd.Summary,
+			cmd.Usage(name), cmd.Help}, cmd.Examples...), "\n")
+		if strings.Contains(strings.ToLower(text), term) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,49 @@
+package repl_test
+
+import (
+	"testing"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestHelpText(t *testing.T) {
+	saveWidth, saveHighlight := repl.Maxwidth, *repl.Highlight
+	defer func() { repl.Maxwidth, *repl.Highlight = saveWidth, saveHighlight }()
+	repl.Maxwidth, *repl.Highlight = 20, false
+
+	text := "Short lines\nstay as they are.\n\n" +
+		"A line that is longer than twenty columns is filled.\n\n" +
+		"Tables:\n   are kept as they are, however long\n"
+	want := "Short lines\nstay as they are.\n\n" +
+		"A line that is\nlonger than twenty\ncolumns is filled.\n\n" +
+		"Tables:\n   are kept as they are, however long"
+	if got := repl.Markup(text); got != want {
+		t.Errorf("Markup: expecting\n%s\ngot\n%s", want, got)
+	}
+
+	cmd := &repl.CmdInfo{
+		Synopsis: "frob [*count*] *a-very-long-argument-name*",
+		Help:     "Frobs *count* times.\n",
+		Examples: []string{"frob 3 x"},
+		SeeAlso:  []string{"help"},
+		Aliases:  []string{"f"},
+	}
+	want = "frob [*count*] *a-very-long-argument-name*\n\n" +
+		"Frobs *count* times.\n\nExamples:\n   frob 3 x\n\n" +
+		"See also: help.\n\nAliases: f"
+	if got := cmd.HelpText("frob"); got != want {
+		t.Errorf("HelpText: expecting\n%s\ngot\n%s", want, got)
+	}
+
+	if got := repl.Cmds["quit"].Category; got != "support" {
+		t.Errorf("expecting quit to be in category support; got %q", got)
+	}
+	found := false
+	for _, name := range repl.SearchHelp("GOMAXPROCS") {
+		found = found || name == "gomaxprocs"
+	}
+	if !found {
+		t.Errorf("SearchHelp: expecting gomaxprocs among the commands found")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2014 Rocky Bernstein.
+// Hooks called as lines are entered and evaluated
+
+package repl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Hook is a set of functions that a session calls as lines are
+// entered and evaluated. Any of them can be nil. Hooks are called in
+// the order they were added, with the session running, so they can
+// use Msg and the like; hooks aren't called for lines that hooks
+// themselves enter.
+type Hook struct {
+	// Name identifies the hook, for RemoveHook.
+	Name string
+
+	// Description says what the hook does, for the "hook" command.
+	Description string
+
+	// OnInput is called with each line entered, before it is run
+	// as a command or evaluated, and returns the line to use
+	// instead; "" skips it.
+	OnInput func(line string) string
+
+	// BeforeEval is called with each expression before it is
+	// evaluated.
+	BeforeEval func(expr string)
+
+	// AfterEval is called after each expression is evaluated and its
+	// result shown, with the value saved in results, if any, the
+	// first error, if any, and how long the evaluation took.
+	AfterEval func(value interface{}, err error, elapsed time.Duration)
+
+	// Format, if it returns true, gives the text to show for value
+	// instead of what the session's InspectFn gives.
+	Format func(value interface{}) (string, bool)
+
+	// OnExit is called when Run returns.
+	OnExit func()
+}
+
+// BuiltinHooks are hooks that can be added with the "hook" command by
+// name.
+var BuiltinHooks = map[string]*Hook{
+	"elapsed": {
+		Name:        "elapsed",
+		Description: "show how long each evaluation takes",
+		AfterEval: func(value interface{}, err error, elapsed time.Duration) {
+			Msg("(%s)", elapsed)
+		},
+	},
+	"json": {
+		Name:        "json",
+		Description: "show strings of JSON objects and arrays indented",
+		Format:      formatJSON,
+	},
+}
+
+// formatJSON shows a string or []byte holding a JSON object or array
+// indented.
+func formatJSON(value interface{}) (string, bool) {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return "", false
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return "", false
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return "", false
+	}
+	return out.String(), true
+}
+
+// AddHook adds hook h to session s. It is an error if s already has
+// a hook with the same name.
+func (s *Session) AddHook(h *Hook) error {
+	if h.Name == "" {
+		return fmt.Errorf("a hook needs a name")
+	}
+	for _, old := range s.Hooks {
+		if old.Name == h.Name {
+			return fmt.Errorf("there is already a hook named %s", h.Name)
+		}
+	}
+	s.Hooks = append(s.Hooks, h)
+	return nil
+}
+
+// RemoveHook removes the hook named "name" from session s.
+func (s *Session) RemoveHook(name string) error {
+	for i, h := range s.Hooks {
+		if h.Name == name {
+			s.Hooks = append(s.Hooks[:i:i], s.Hooks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("there is no hook named %s", name)
+}
+
+// callHooks calls fn with each hook of s, unless hooks are already
+// being called.
+func (s *Session) callHooks(fn func(h *Hook)) {
+	if s.inHook {
+		return
+	}
+	s.inHook = true
+	defer func() { s.inHook = false }()
+	for _, h := range append([]*Hook(nil), s.Hooks...) {
+		fn(h)
+	}
+}
+
+// inputHooks passes line through the OnInput hooks, returning ""
+// if one of them says to skip it.
+func (s *Session) inputHooks(line string) string {
+	s.callHooks(func(h *Hook) {
+		if h.OnInput != nil && line != "" {
+			line = h.OnInput(line)
+		}
+	})
+	return line
+}
+
+// afterEvalHooks calls the AfterEval hooks with the outcome of an
+// evaluation.
+func (s *Session) afterEvalHooks(result *EvalResult, elapsed time.Duration) {
+	var value interface{}
+	if result.ResultIndex >= 0 {
+		value = s.Results[result.ResultIndex]
+	}
+	var err error
+	if len(result.Errors) > 0 {
+		err = result.Errors[0]
+	}
+	s.callHooks(func(h *Hook) {
+		if h.AfterEval != nil {
+			h.AfterEval(value, err, elapsed)
+		}
+	})
+}
+
+// exitHooks calls the OnExit hooks.
+func (s *Session) exitHooks() {
+	s.callHooks(func(h *Hook) {
+		if h.OnExit != nil {
+			h.OnExit()
+		}
+	})
+}
+
+// inspect shows value the way the first Format hook that wants to
+// does, or else with s.Inspect.
+func (s *Session) inspect(value reflect.Value) string {
+	text, found := "", false
+	if value.IsValid() && value.CanInterface() {
+		s.callHooks(func(h *Hook) {
+			if h.Format != nil && !found {
+				text, found = h.Format(value.Interface())
+			}
+		})
+	}
+	if found {
+		return text
+	}
+	return s.Inspect(value)
+}
+
+// LineHook returns a hook named "name" that enters "line" as if at
+// the prompt when "when" happens: "input", "before", "after" or
+// "exit". This is how the "hook" command makes hooks.
+func LineHook(name, when, line string) (*Hook, error) {
+	h := &Hook{Name: name, Description: when + ": " + line}
+	enter := func() { Current.Enter(line) }
+	switch when {
+	case "input":
+		h.OnInput = func(input string) string {
+			enter()
+			return input
+		}
+	case "before":
+		h.BeforeEval = func(string) { enter() }
+	case "after":
+		h.AfterEval = func(interface{}, error, time.Duration) { enter() }
+	case "exit":
+		h.OnExit = enter
+	default:
+		return nil, fmt.Errorf("expecting input, before, after or exit; got %s",
+			when)
+	}
+	return h, nil
+}
@@ -0,0 +1,79 @@
+package repl_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestHooks(t *testing.T) {
+	lines := []string{"1+2", "skip me", "history 1"}
+	readLine := func(prompt string, add ...bool) (string, error) {
+		if len(lines) == 0 {
+			return "", io.EOF
+		}
+		line := lines[0]
+		lines = lines[1:]
+		return line, nil
+	}
+	s := repl.NewSession(nil, readLine, nil)
+
+	events := []string{}
+	err := s.AddHook(&repl.Hook{
+		Name: "log",
+		OnInput: func(line string) string {
+			events = append(events, "input "+line)
+			if line == "skip me" {
+				return ""
+			}
+			return line
+		},
+		BeforeEval: func(expr string) { events = append(events, "before "+expr) },
+		AfterEval: func(value interface{}, err error, elapsed time.Duration) {
+			events = append(events, "after")
+		},
+		OnExit: func() { events = append(events, "exit") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddHook(&repl.Hook{Name: "log"}); err == nil {
+		t.Errorf("expecting an error adding a second hook named log")
+	}
+	if out := s.Capture("hook after history 1", true).Output; !strings.Contains(out, "after1") {
+		t.Errorf("expecting hook after1 to be added; got %q", out)
+	}
+	if err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"input hook after history 1", "input 1+2", "before 1+2",
+		"after", "input skip me", "input history 1", "exit"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expecting hooks called for\n%q\ngot\n%q", want, events)
+	}
+	if history := strings.Join(s.History, "\n"); strings.Contains(history, "skip me") {
+		t.Errorf("expecting a skipped line to be left out of history; got %q", history)
+	}
+
+	if err := s.RemoveHook("log"); err != nil {
+		t.Error(err)
+	}
+	if err := s.RemoveHook("log"); err == nil {
+		t.Errorf("expecting an error removing hook log twice")
+	}
+
+	format := repl.BuiltinHooks["json"].Format
+	if text, ok := format(`{"a": [1, 2]}`); !ok || text != "{\n  \"a\": [\n    1,\n    2\n  ]\n}" {
+		t.Errorf("expecting JSON to be indented; got %q, %v", text, ok)
+	}
+	for _, value := range []interface{}{"not json", `{"a":`, 42} {
+		if _, ok := format(value); ok {
+			t.Errorf("expecting %v not to be formatted", value)
+		}
+	}
+}
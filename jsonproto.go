@@ -0,0 +1,208 @@
+// Copyright 2014 Rocky Bernstein.
+// JSON-over-stdio protocol for editors and other programs
+
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"io"
+	"sort"
+)
+
+// JSONRequest is a request in the JSON protocol. Each request is a
+// single JSON object. Op is one of:
+//
+//    eval           evaluate Code, or run it if it is a REPL command
+//    complete       complete the word ending at Pos in Code
+//    whatis         give the type checker's information for Code
+//    doc            give documentation for Code, as in the doc command
+//    list-packages  list the imported packages
+//
+// ID is passed back in the response so clients can match them up.
+type JSONRequest struct {
+	ID   interface{} `json:"id,omitempty"`
+	Op   string      `json:"op"`
+	Code string      `json:"code,omitempty"`
+	Pos  *int        `json:"pos,omitempty"`
+}
+
+// JSONValue is one value of an evaluated expression.
+type JSONValue struct {
+	Text string `json:"text"` // as shown by the session's inspect function
+	Kind string `json:"kind"`
+	Type string `json:"type"`
+}
+
+// JSONError is an error with the position in Code it refers to, if
+// known. Lines and columns start at 1. Kind is that of an EvalError,
+// or "request" for a bad request.
+type JSONError struct {
+	Kind   string `json:"kind"`
+	Msg    string `json:"msg"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// JSONPackage describes an imported package.
+type JSONPackage struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// JSONResponse is the response to a JSONRequest. Only the fields that
+// make sense for the request's Op are filled in.
+type JSONResponse struct {
+	ID     interface{} `json:"id,omitempty"`
+	Op     string      `json:"op"`
+	OK     bool        `json:"ok"`
+	Errors []JSONError `json:"errors,omitempty"`
+
+	// eval
+	Values      []JSONValue `json:"values,omitempty"`
+	ResultIndex *int        `json:"result_index,omitempty"`
+	Stdout      string      `json:"stdout,omitempty"`
+	Stderr      string      `json:"stderr,omitempty"`
+	Output      string      `json:"output,omitempty"` // from REPL commands
+	Leave       bool        `json:"leave,omitempty"`  // the session has ended
+
+	// complete
+	Start       *int     `json:"start,omitempty"`
+	Completions []string `json:"completions,omitempty"`
+
+	// whatis; Output has what the whatis command shows, and Types
+	// is empty when Code is a type name
+	Types []string `json:"types,omitempty"`
+
+	// doc
+	Doc *DocInfo `json:"doc,omitempty"`
+
+	// list-packages
+	Packages []JSONPackage `json:"packages,omitempty"`
+}
+
+// ServeJSON reads JSON requests from "in" and writes a JSON response
+// for each to "out", one object per line, until end of file or until
+// the session is asked to leave. A line that isn't a request gets a
+// response with an error of kind "request", and reading goes on.
+func (s *Session) ServeJSON(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for !s.LeaveREPL {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var req JSONRequest
+			var resp *JSONResponse
+			if jerr := json.Unmarshal(line, &req); jerr != nil {
+				resp = &JSONResponse{Errors: []JSONError{{Kind: "request",
+					Msg: jerr.Error()}}}
+			} else {
+				resp = s.HandleJSON(&req)
+			}
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleJSON carries out a JSON request.
+func (s *Session) HandleJSON(req *JSONRequest) *JSONResponse {
+	resp := &JSONResponse{ID: req.ID, Op: req.Op, OK: true}
+	fail := func(kind string, err error) {
+		resp.OK = false
+		resp.Errors = append(resp.Errors, JSONError{Kind: kind, Msg: err.Error()})
+	}
+	switch req.Op {
+	case "eval":
+		c := s.Capture(req.Code, false)
+		resp.Output, resp.Stdout, resp.Stderr = c.Output, c.Stdout, c.Stderr
+		resp.Leave = s.LeaveREPL
+		if c.Result == nil {
+			break
+		}
+		for _, e := range c.Result.Errors {
+			resp.OK = false
+			resp.Errors = append(resp.Errors, JSONError{Kind: e.Kind,
+				Msg: e.Msg, Line: e.Line, Column: e.Column})
+		}
+		for _, v := range c.Result.Values {
+			jv := JSONValue{Text: "nil", Kind: "invalid"}
+			if v.IsValid() {
+				jv = JSONValue{Text: s.Inspect(v), Kind: v.Kind().String(),
+					Type: v.Type().String()}
+			}
+			resp.Values = append(resp.Values, jv)
+		}
+		if c.Result.ResultIndex >= 0 {
+			resp.ResultIndex = &c.Result.ResultIndex
+		}
+	case "complete":
+		pos := len(req.Code)
+		if req.Pos != nil {
+			pos = *req.Pos
+		}
+		start, completions := s.Complete(req.Code, pos)
+		resp.Start, resp.Completions = &start, completions
+	case "whatis":
+		s.run(func() { s.whatis(req.Code, resp, fail) })
+	case "doc":
+		path, names, err := ResolveDocName(s.Env, req.Code)
+		if err != nil {
+			fail("request", err)
+			break
+		}
+		if resp.Doc, err = LookupDoc(path, names...); err != nil {
+			fail("request", err)
+		}
+	case "list-packages":
+		for name, pkg := range s.Env.Pkgs {
+			resp.Packages = append(resp.Packages,
+				JSONPackage{Name: name, Path: pkg.Path})
+		}
+		sort.Sort(byPackageName(resp.Packages))
+	default:
+		fail("request", fmt.Errorf("unknown op %q; expecting one of: eval, "+
+			"complete, whatis, doc, list-packages", req.Op))
+	}
+	return resp
+}
+
+// whatis carries out the "whatis" op for code in resp; fail reports
+// an error. Type names have no type of their own, so for them there
+// is only what the whatis command shows.
+func (s *Session) whatis(code string, resp *JSONResponse,
+	fail func(string, error)) {
+	if expr, err := parser.ParseExpr(code); err != nil || !isTypeExpr(expr, s.Env) {
+		t, err := TypeOfExpr(s.Env, code)
+		if err != nil {
+			fail("check", err)
+			return
+		}
+		resp.Types = []string{t.String()}
+	}
+	if Cmds["whatis"] != nil {
+		var buf bytes.Buffer
+		saveOut := Out
+		Out = &buf
+		defer func() { Out = saveOut }()
+		wasProcessed(CmdPrefix + "whatis " + code)
+		resp.Output = buf.String()
+	}
+}
+
+type byPackageName []JSONPackage
+
+func (p byPackageName) Len() int           { return len(p) }
+func (p byPackageName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p byPackageName) Less(i, j int) bool { return p[i].Name < p[j].Name }
@@ -0,0 +1,54 @@
+package repl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestServeJSON(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	in := strings.NewReader(`{"id": 1, "op": "nosuch"}
+{"id": 2, "op":
+{"id": 3, "op": "list-packages"}
+`)
+	var out bytes.Buffer
+	if err := s.ServeJSON(in, &out); err != nil {
+		t.Fatalf("expecting no error; got %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expecting 3 responses; got %q", out.String())
+	}
+	for i, want := range []string{"request", "request", ""} {
+		var resp repl.JSONResponse
+		if err := json.Unmarshal([]byte(lines[i]), &resp); err != nil {
+			t.Fatalf("response %d: %s", i+1, err)
+		}
+		kind := ""
+		if len(resp.Errors) > 0 {
+			kind = resp.Errors[0].Kind
+		}
+		if kind != want {
+			t.Errorf("response %d: expecting error kind %q; got %q", i+1,
+				want, kind)
+		}
+	}
+}
+
+func TestJSONWhatis(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	resp := s.HandleJSON(&repl.JSONRequest{Op: "whatis", Code: "strings.Builder"})
+	if !resp.OK || !strings.Contains(resp.Output, "is a type") {
+		t.Errorf("whatis strings.Builder: expecting the whatis command's "+
+			"output; got %+v", resp)
+	}
+	resp = s.HandleJSON(&repl.JSONRequest{Op: "whatis", Code: "1 +"})
+	if resp.OK || len(resp.Errors) != 1 || resp.Errors[0].Kind != "check" {
+		t.Errorf("whatis 1 +: expecting a check error; got %+v", resp)
+	}
+}
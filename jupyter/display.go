@@ -0,0 +1,98 @@
+// Copyright 2014 Rocky Bernstein.
+// Rich display of values in notebooks
+
+package jupyter
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+
+	"github.com/rocky/go-fish"
+)
+
+// MaxTableRows is the most rows shown in an HTML table.
+var MaxTableRows = 100
+
+// DisplayData returns the MIME bundle for the values of an
+// expression: always "text/plain", as shown by inspect, and
+// "text/html" too when there is a single value that is a slice or
+// array of structs or of pointers to structs, shown as a table.
+func DisplayData(values []reflect.Value, inspect repl.InspectFnType) map[string]string {
+	texts := []string{}
+	for _, v := range values {
+		if v.IsValid() {
+			texts = append(texts, inspect(v))
+		} else {
+			texts = append(texts, "nil")
+		}
+	}
+	data := map[string]string{"text/plain": strings.Join(texts, ", ")}
+	if len(values) == 0 {
+		data["text/plain"] = "void"
+	} else if len(values) == 1 {
+		if table, ok := HTMLTable(values[0]); ok {
+			data["text/html"] = table
+		}
+	}
+	return data
+}
+
+// HTMLTable shows v as an HTML table with a row for each element and
+// a column for each exported field, if v is a slice or array of
+// structs or of pointers to structs.
+func HTMLTable(v reflect.Value) (string, bool) {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", false
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return "", false
+	}
+	fields := []int{}
+	for i := 0; i < elemType.NumField(); i++ {
+		if elemType.Field(i).PkgPath == "" {
+			fields = append(fields, i)
+		}
+	}
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<table>\n<thead><tr><th></th>")
+	for _, i := range fields {
+		fmt.Fprintf(&buf, "<th>%s</th>", html.EscapeString(elemType.Field(i).Name))
+	}
+	buf.WriteString("</tr></thead>\n<tbody>\n")
+	n := v.Len()
+	for row := 0; row < n && row < MaxTableRows; row++ {
+		fmt.Fprintf(&buf, "<tr><th>%d</th>", row)
+		elem := v.Index(row)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				fmt.Fprintf(&buf, "<td colspan=\"%d\">nil</td></tr>\n", len(fields))
+				continue
+			}
+			elem = elem.Elem()
+		}
+		for _, i := range fields {
+			fmt.Fprintf(&buf, "<td>%s</td>",
+				html.EscapeString(fmt.Sprint(elem.Field(i).Interface())))
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</tbody>\n</table>\n")
+	if n > MaxTableRows {
+		fmt.Fprintf(&buf, "<p>%d of %d rows shown</p>\n", MaxTableRows, n)
+	}
+	return buf.String(), true
+}
@@ -0,0 +1,537 @@
+// Copyright 2014 Rocky Bernstein.
+// Jupyter kernel for go-fish
+
+// Package jupyter lets Jupyter notebooks and consoles use a go-fish
+// REPL session as a Go kernel. It speaks the Jupyter messaging
+// protocol, version 5.3, over its own small implementation of the
+// ZeroMQ wire protocol, so nothing outside of the standard library is
+// needed.
+//
+// To make go-fish available to Jupyter, put a kernel.json like this
+// one in a directory named, say, go-fish under the "kernels" directory
+// that "jupyter --paths" lists:
+//
+//	{
+//	  "argv": ["go-fish", "-kernel", "{connection_file}"],
+//	  "display_name": "Go (go-fish)",
+//	  "language": "go"
+//	}
+package jupyter
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+// ProtocolVersion is the version of the Jupyter messaging protocol
+// we speak.
+const ProtocolVersion = "5.3"
+
+// delimiter separates routing identities from the rest of a message.
+const delimiter = "<IDS|MSG>"
+
+// ConnectionInfo is what Jupyter puts in the connection file it gives
+// a kernel when starting it.
+type ConnectionInfo struct {
+	Transport       string `json:"transport"`
+	IP              string `json:"ip"`
+	ShellPort       int    `json:"shell_port"`
+	IOPubPort       int    `json:"iopub_port"`
+	StdinPort       int    `json:"stdin_port"`
+	ControlPort     int    `json:"control_port"`
+	HBPort          int    `json:"hb_port"`
+	Key             string `json:"key"`
+	SignatureScheme string `json:"signature_scheme"`
+	KernelName      string `json:"kernel_name,omitempty"`
+}
+
+// ReadConnectionFile reads the connection file at path.
+func ReadConnectionFile(path string) (*ConnectionInfo, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info := &ConnectionInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return info, nil
+}
+
+// Header is a Jupyter message header. A message without a parent has
+// an empty parent header, which is sent as {}.
+type Header struct {
+	MsgID    string `json:"msg_id,omitempty"`
+	Session  string `json:"session,omitempty"`
+	Username string `json:"username,omitempty"`
+	Date     string `json:"date,omitempty"`
+	MsgType  string `json:"msg_type,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+// Message is a Jupyter message.
+type Message struct {
+	// Identities route replies on ROUTER sockets, or give the topic
+	// on the IOPub socket.
+	Identities [][]byte
+
+	Header       Header
+	ParentHeader Header
+	Metadata     map[string]interface{}
+	Content      json.RawMessage
+}
+
+// Kernel serves a REPL session to Jupyter.
+type Kernel struct {
+	Session *repl.Session
+
+	// Info is the connection information. Ports that were given as
+	// 0 are filled in once the kernel has its sockets.
+	Info ConnectionInfo
+
+	shell, control, stdin, iopub, hb *Socket
+
+	key     []byte // for signing; nil if messages aren't signed
+	id      string
+	count   int
+	stopped bool
+}
+
+// NewKernel makes a kernel for session s and opens the sockets that
+// info describes.
+func NewKernel(s *repl.Session, info *ConnectionInfo) (*Kernel, error) {
+	if info.Transport != "tcp" {
+		return nil, fmt.Errorf("unsupported transport %q", info.Transport)
+	}
+	k := &Kernel{Session: s, Info: *info, id: newID()}
+	if info.Key != "" {
+		if info.SignatureScheme != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported signature scheme %q",
+				info.SignatureScheme)
+		}
+		k.key = []byte(info.Key)
+	}
+	sockets := []struct {
+		sock       **Socket
+		socketType string
+		port       *int
+	}{
+		{&k.shell, "ROUTER", &k.Info.ShellPort},
+		{&k.control, "ROUTER", &k.Info.ControlPort},
+		{&k.stdin, "ROUTER", &k.Info.StdinPort},
+		{&k.iopub, "PUB", &k.Info.IOPubPort},
+		{&k.hb, "REP", &k.Info.HBPort},
+	}
+	for _, sock := range sockets {
+		addr := net.JoinHostPort(info.IP, strconv.Itoa(*sock.port))
+		l, err := Listen(sock.socketType, "tcp", addr)
+		if err != nil {
+			k.Close()
+			return nil, err
+		}
+		*sock.sock = l
+		*sock.port = l.Addr().(*net.TCPAddr).Port
+	}
+	return k, nil
+}
+
+// Close closes the kernel's sockets.
+func (k *Kernel) Close() {
+	for _, sock := range []*Socket{k.shell, k.control, k.stdin, k.iopub, k.hb} {
+		if sock != nil {
+			sock.Close()
+		}
+	}
+}
+
+// request is a message along with the socket it came in on.
+type request struct {
+	sock *Socket
+	msg  *Message
+}
+
+// Run handles requests until the kernel is asked to shut down, or the
+// session is left with "quit".
+func (k *Kernel) Run() error {
+	go func() {
+		for {
+			msg, err := k.hb.Recv()
+			if err != nil {
+				return
+			}
+			k.hb.Send(msg)
+		}
+	}()
+	requests := make(chan request)
+	errs := make(chan error, 2)
+	for _, sock := range []*Socket{k.shell, k.control} {
+		go func(sock *Socket) {
+			for {
+				frames, err := sock.Recv()
+				if err != nil {
+					errs <- err
+					return
+				}
+				msg, err := k.parse(frames)
+				if err != nil {
+					// Unsigned or garbled messages are dropped,
+					// as the protocol says.
+					continue
+				}
+				requests <- request{sock, msg}
+			}
+		}(sock)
+	}
+	k.publish(nil, "status", map[string]string{"execution_state": "starting"})
+	for !k.stopped {
+		select {
+		case req := <-requests:
+			k.handle(req.sock, req.msg)
+		case err := <-errs:
+			if err == ErrClosed {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// handle carries out request msg which came in on sock.
+func (k *Kernel) handle(sock *Socket, msg *Message) {
+	k.publish(msg, "status", map[string]string{"execution_state": "busy"})
+	defer k.publish(msg, "status", map[string]string{"execution_state": "idle"})
+	switch msg.Header.MsgType {
+	case "kernel_info_request":
+		k.reply(sock, msg, "kernel_info_reply", k.kernelInfo())
+	case "execute_request":
+		k.execute(sock, msg)
+	case "complete_request":
+		k.complete(sock, msg)
+	case "inspect_request":
+		k.inspect(sock, msg)
+	case "is_complete_request":
+		// Each line is an expression or a command on its own.
+		k.reply(sock, msg, "is_complete_reply", map[string]string{
+			"status": "complete"})
+	case "history_request":
+		history := [][]interface{}{}
+		for i, line := range k.Session.History {
+			history = append(history, []interface{}{0, i + 1, line})
+		}
+		k.reply(sock, msg, "history_reply", map[string]interface{}{
+			"status": "ok", "history": history})
+	case "comm_info_request":
+		k.reply(sock, msg, "comm_info_reply", map[string]interface{}{
+			"status": "ok", "comms": map[string]interface{}{}})
+	case "interrupt_request":
+		// By the time we see this, whatever was running is done.
+		k.reply(sock, msg, "interrupt_reply", map[string]string{"status": "ok"})
+	case "shutdown_request":
+		var req struct {
+			Restart bool `json:"restart"`
+		}
+		json.Unmarshal(msg.Content, &req)
+		k.reply(sock, msg, "shutdown_reply", map[string]interface{}{
+			"status": "ok", "restart": req.Restart})
+		k.stopped = true
+	}
+}
+
+func (k *Kernel) kernelInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"status":                 "ok",
+		"protocol_version":       ProtocolVersion,
+		"implementation":         "go-fish",
+		"implementation_version": "0.1",
+		"language_info": map[string]interface{}{
+			"name":           "go",
+			"version":        strings.TrimPrefix(runtime.Version(), "go"),
+			"mimetype":       "text/x-go",
+			"file_extension": ".go",
+		},
+		"banner": "go-fish: a Go REPL. Enter expressions to be evaluated, " +
+			"or REPL commands; \"help\" lists them.",
+		"help_links": []map[string]string{
+			{"text": "go-fish", "url": "https://github.com/rocky/go-fish"},
+		},
+	}
+}
+
+// execute runs each line of the cell in turn, stopping at the first
+// error. Output is sent as it comes, and the value of the last line,
+// if it has one, is the cell's result.
+func (k *Kernel) execute(sock *Socket, msg *Message) {
+	var req struct {
+		Code         string `json:"code"`
+		Silent       bool   `json:"silent"`
+		StoreHistory *bool  `json:"store_history"`
+	}
+	json.Unmarshal(msg.Content, &req)
+	if !req.Silent && (req.StoreHistory == nil || *req.StoreHistory) {
+		k.count++
+	}
+	if !req.Silent {
+		k.publish(msg, "execute_input", map[string]interface{}{
+			"code": req.Code, "execution_count": k.count})
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(req.Code, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	reply := map[string]interface{}{
+		"status":           "ok",
+		"execution_count":  k.count,
+		"user_expressions": map[string]interface{}{},
+		"payload":          []interface{}{},
+	}
+	for i, line := range lines {
+		c := k.Session.Capture(line, false)
+		if !req.Silent {
+			k.stream(msg, "stdout", c.Output+c.Stdout)
+			k.stream(msg, "stderr", c.Stderr)
+		}
+		if k.Session.LeaveREPL {
+			k.stopped = true
+			break
+		}
+		if c.Result == nil {
+			continue
+		}
+		if len(c.Result.Errors) > 0 {
+			e := c.Result.Errors[0]
+			traceback := []string{}
+			for _, e := range c.Result.Errors {
+				traceback = append(traceback, e.Msg)
+			}
+			content := map[string]interface{}{
+				"ename":     e.Kind + " error",
+				"evalue":    e.Msg,
+				"traceback": traceback,
+			}
+			if !req.Silent {
+				k.publish(msg, "error", content)
+			}
+			content["status"] = "error"
+			content["execution_count"] = k.count
+			reply = content
+			break
+		}
+		if i == len(lines)-1 && c.Result.Values != nil && !req.Silent {
+			k.publish(msg, "execute_result", map[string]interface{}{
+				"execution_count": k.count,
+				"data":            DisplayData(c.Result.Values, k.Session.Inspect),
+				"metadata":        map[string]interface{}{},
+			})
+		}
+	}
+	k.reply(sock, msg, "execute_reply", reply)
+}
+
+// stream sends text as output on stream "name", "stdout" or "stderr".
+func (k *Kernel) stream(parent *Message, name, text string) {
+	if text != "" {
+		k.publish(parent, "stream", map[string]string{"name": name, "text": text})
+	}
+}
+
+// complete uses Session.Complete. Jupyter counts cursor positions in
+// Unicode code points rather than bytes.
+func (k *Kernel) complete(sock *Socket, msg *Message) {
+	var req struct {
+		Code      string `json:"code"`
+		CursorPos int    `json:"cursor_pos"`
+	}
+	json.Unmarshal(msg.Content, &req)
+	pos := byteOffset(req.Code, req.CursorPos)
+	start, matches := k.Session.Complete(req.Code, pos)
+	if matches == nil {
+		matches = []string{}
+	}
+	k.reply(sock, msg, "complete_reply", map[string]interface{}{
+		"status":       "ok",
+		"matches":      matches,
+		"cursor_start": len([]rune(req.Code[:start])),
+		"cursor_end":   req.CursorPos,
+		"metadata":     map[string]interface{}{},
+	})
+}
+
+// inspect gives what "whatis" and "doc" have to say about the
+// expression at the cursor.
+func (k *Kernel) inspect(sock *Socket, msg *Message) {
+	var req struct {
+		Code      string `json:"code"`
+		CursorPos int    `json:"cursor_pos"`
+	}
+	json.Unmarshal(msg.Content, &req)
+	expr := exprAt(req.Code, byteOffset(req.Code, req.CursorPos))
+
+	var text bytes.Buffer
+	if expr != "" {
+		whatis := k.Session.HandleJSON(&repl.JSONRequest{Op: "whatis", Code: expr})
+		if whatis.OK {
+			if whatis.Output != "" {
+				text.WriteString(whatis.Output)
+			} else {
+				fmt.Fprintf(&text, "%s: %s\n", expr, strings.Join(whatis.Types, ", "))
+			}
+		}
+		doc := k.Session.HandleJSON(&repl.JSONRequest{Op: "doc", Code: expr})
+		if doc.OK && doc.Doc != nil {
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			fmt.Fprintf(&text, "%s\n\n%s", doc.Doc.Decl, doc.Doc.Doc)
+		}
+	}
+	content := map[string]interface{}{
+		"status":   "ok",
+		"found":    text.Len() > 0,
+		"data":     map[string]string{},
+		"metadata": map[string]interface{}{},
+	}
+	if text.Len() > 0 {
+		content["data"] = map[string]string{"text/plain": text.String()}
+	}
+	k.reply(sock, msg, "inspect_reply", content)
+}
+
+// byteOffset converts a position in code points to one in bytes.
+func byteOffset(s string, pos int) int {
+	i := 0
+	for offset := range s {
+		if i == pos {
+			return offset
+		}
+		i++
+	}
+	return len(s)
+}
+
+// exprAt returns the dotted name around byte offset pos in code, e.g.
+// "strings.Fields" for a cursor anywhere in it.
+func exprAt(code string, pos int) string {
+	isNameByte := func(c byte) bool {
+		return c == '_' || c == '.' || c >= 0x80 ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+	}
+	if pos > len(code) {
+		pos = len(code)
+	}
+	start, end := pos, pos
+	for start > 0 && isNameByte(code[start-1]) {
+		start--
+	}
+	for end < len(code) && isNameByte(code[end]) {
+		end++
+	}
+	return strings.Trim(code[start:end], ".")
+}
+
+// parse turns the frames of a message received on a ROUTER socket
+// into a Message, checking its signature.
+func (k *Kernel) parse(frames [][]byte) (*Message, error) {
+	i := 0
+	for i < len(frames) && string(frames[i]) != delimiter {
+		i++
+	}
+	if len(frames)-i < 6 {
+		return nil, errors.New("malformed message")
+	}
+	msg := &Message{Identities: frames[:i]}
+	parts := frames[i+2 : i+6]
+	if k.key != nil {
+		got, err := hex.DecodeString(string(frames[i+1]))
+		if err != nil || !hmac.Equal(got, k.sign(parts)) {
+			return nil, errors.New("bad message signature")
+		}
+	}
+	if err := json.Unmarshal(parts[0], &msg.Header); err != nil {
+		return nil, err
+	}
+	json.Unmarshal(parts[1], &msg.ParentHeader)
+	json.Unmarshal(parts[2], &msg.Metadata)
+	msg.Content = parts[3]
+	return msg, nil
+}
+
+func (k *Kernel) sign(parts [][]byte) []byte {
+	mac := hmac.New(sha256.New, k.key)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+// send sends a message of type msgType with content on sock, in reply
+// to parent if that isn't nil.
+func (k *Kernel) send(sock *Socket, identities [][]byte, parent *Message,
+	msgType string, content interface{}) error {
+	header := Header{
+		MsgID:    newID(),
+		Session:  k.id,
+		Username: "go-fish",
+		Date:     time.Now().UTC().Format(time.RFC3339Nano),
+		MsgType:  msgType,
+		Version:  ProtocolVersion,
+	}
+	var parentHeader Header
+	if parent != nil {
+		parentHeader = parent.Header
+		header.Session = parent.Header.Session
+	}
+	parts := make([][]byte, 4)
+	var err error
+	for i, v := range []interface{}{header, parentHeader,
+		map[string]interface{}{}, content} {
+		if parts[i], err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	signature := []byte{}
+	if k.key != nil {
+		signature = []byte(hex.EncodeToString(k.sign(parts)))
+	}
+	frames := append([][]byte{}, identities...)
+	frames = append(frames, []byte(delimiter), signature)
+	return sock.Send(append(frames, parts...))
+}
+
+// reply sends a reply to request msg on sock.
+func (k *Kernel) reply(sock *Socket, msg *Message, msgType string,
+	content interface{}) error {
+	return k.send(sock, msg.Identities, msg, msgType, content)
+}
+
+// publish sends a message on the IOPub socket.
+func (k *Kernel) publish(parent *Message, msgType string, content interface{}) error {
+	topic := []byte("kernel." + k.id + "." + msgType)
+	return k.send(k.iopub, [][]byte{topic}, parent, msgType, content)
+}
+
+// newID returns a random UUID.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
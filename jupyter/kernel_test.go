@@ -0,0 +1,249 @@
+package jupyter_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+	"github.com/rocky/go-fish/jupyter"
+)
+
+const key = "test-key"
+
+// client is a stand-in for a Jupyter front-end.
+type client struct {
+	t              *testing.T
+	shell          *jupyter.Socket
+	replies, iopub chan [][]byte
+	session        string
+	n              int
+}
+
+func dial(t *testing.T, socketType string, port int) *jupyter.Socket {
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	s, err := jupyter.Dial(socketType, "tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func sign(parts [][]byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send sends a request and returns its message id.
+func (c *client) send(msgType string, content interface{}) string {
+	c.n++
+	id := "msg" + strconv.Itoa(c.n)
+	header, _ := json.Marshal(map[string]string{"msg_id": id,
+		"session": c.session, "username": "test", "msg_type": msgType,
+		"version": jupyter.ProtocolVersion})
+	body, _ := json.Marshal(content)
+	parts := [][]byte{header, []byte("{}"), []byte("{}"), body}
+	frames := [][]byte{[]byte("<IDS|MSG>"), []byte(sign(parts))}
+	if err := c.shell.Send(append(frames, parts...)); err != nil {
+		c.t.Fatal(err)
+	}
+	return id
+}
+
+type reply struct {
+	Header       jupyter.Header
+	ParentHeader jupyter.Header
+	Content      map[string]interface{}
+}
+
+// pump passes on what is received on sock.
+func pump(sock *jupyter.Socket) chan [][]byte {
+	c := make(chan [][]byte, 100)
+	go func() {
+		for {
+			frames, err := sock.Recv()
+			if err != nil {
+				close(c)
+				return
+			}
+			c <- frames
+		}
+	}()
+	return c
+}
+
+// recv reads a message from "from", checking how it is signed.
+func (c *client) recv(from chan [][]byte) reply {
+	var frames [][]byte
+	select {
+	case frames = <-from:
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+	}
+	i := 0
+	for i < len(frames) && string(frames[i]) != "<IDS|MSG>" {
+		i++
+	}
+	if len(frames)-i < 6 {
+		c.t.Fatalf("malformed message %q", frames)
+	}
+	if got := string(frames[i+1]); got != sign(frames[i+2:i+6]) {
+		c.t.Fatalf("bad signature %q", got)
+	}
+	var m reply
+	json.Unmarshal(frames[i+2], &m.Header)
+	json.Unmarshal(frames[i+3], &m.ParentHeader)
+	json.Unmarshal(frames[i+5], &m.Content)
+	return m
+}
+
+// iopubFor returns the IOPub messages for request id up to the kernel
+// going idle.
+func (c *client) iopubFor(id string) []reply {
+	msgs := []reply{}
+	for {
+		m := c.recv(c.iopub)
+		if m.ParentHeader.MsgID != id {
+			continue
+		}
+		if m.Header.MsgType == "status" && m.Content["execution_state"] == "idle" {
+			return msgs
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+func TestKernel(t *testing.T) {
+	*repl.Highlight = false
+	s := repl.NewSession(nil, nil, nil)
+	k, err := jupyter.NewKernel(s, &jupyter.ConnectionInfo{
+		Transport: "tcp", IP: "127.0.0.1",
+		Key: key, SignatureScheme: "hmac-sha256",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	done := make(chan error, 1)
+	go func() { done <- k.Run() }()
+
+	c := &client{t: t, session: "test-session"}
+	c.shell = dial(t, "DEALER", k.Info.ShellPort)
+	defer c.shell.Close()
+	c.replies = pump(c.shell)
+	iopub := dial(t, "SUB", k.Info.IOPubPort)
+	defer iopub.Close()
+	c.iopub = pump(iopub)
+	if err := iopub.Subscribe(""); err != nil {
+		t.Fatal(err)
+	}
+
+	hb := dial(t, "REQ", k.Info.HBPort)
+	defer hb.Close()
+	hb.Send([][]byte{[]byte("ping")})
+	if frames, err := hb.Recv(); err != nil || len(frames) != 1 ||
+		string(frames[0]) != "ping" {
+		t.Errorf("heartbeat: expecting ping back; got %q, %v", frames, err)
+	}
+
+	// The subscription may take a moment to get to the kernel, so
+	// ask until IOPub messages come through.
+	var id string
+	for {
+		id = c.send("kernel_info_request", map[string]interface{}{})
+		m := c.recv(c.replies)
+		if m.Header.MsgType != "kernel_info_reply" ||
+			m.Content["implementation"] != "go-fish" {
+			t.Fatalf("unexpected kernel info reply %v", m)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if len(c.iopub) > 0 {
+			break
+		}
+	}
+	c.iopubFor(id)
+
+	id = c.send("execute_request", map[string]interface{}{
+		"code": "help quit", "silent": false})
+	m := c.recv(c.replies)
+	if m.Header.MsgType != "execute_reply" || m.Content["status"] != "ok" ||
+		m.Content["execution_count"] != 1.0 || m.ParentHeader.MsgID != id {
+		t.Errorf("unexpected execute reply %v", m)
+	}
+	stdout := ""
+	for _, m := range c.iopubFor(id) {
+		if m.Header.MsgType == "stream" && m.Content["name"] == "stdout" {
+			stdout += m.Content["text"].(string)
+		}
+	}
+	if !strings.Contains(stdout, "quit") {
+		t.Errorf("expecting help for quit on stdout; got %q", stdout)
+	}
+
+	id = c.send("execute_request", map[string]interface{}{"code": "1 +"})
+	m = c.recv(c.replies)
+	if m.Content["status"] != "error" || m.Content["ename"] != "parse error" {
+		t.Errorf("expecting a parse error; got %v", m)
+	}
+	c.iopubFor(id)
+
+	id = c.send("complete_request", map[string]interface{}{
+		"code": "x := strin", "cursor_pos": 10})
+	m = c.recv(c.replies)
+	matches, _ := m.Content["matches"].([]interface{})
+	if m.Content["cursor_start"] != 5.0 || len(matches) == 0 ||
+		matches[0] != "strings" {
+		t.Errorf("unexpected complete reply %v", m)
+	}
+	c.iopubFor(id)
+
+	id = c.send("shutdown_request", map[string]interface{}{"restart": false})
+	if m = c.recv(c.replies); m.Header.MsgType != "shutdown_reply" {
+		t.Errorf("expecting shutdown reply; got %v", m)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("kernel did not shut down")
+	}
+}
+
+type row struct {
+	Name  string
+	Count int
+	note  string
+}
+
+func TestHTMLTable(t *testing.T) {
+	rows := []*row{{"a<b", 1, "x"}, nil}
+	table, ok := jupyter.HTMLTable(reflect.ValueOf(rows))
+	if !ok {
+		t.Fatal("expecting a table")
+	}
+	for _, want := range []string{"<th>Name</th><th>Count</th></tr>",
+		"<td>a&lt;b</td><td>1</td>", "<td colspan=\"2\">nil</td>"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("expecting %q in table:\n%s", want, table)
+		}
+	}
+	if strings.Contains(table, "note") {
+		t.Errorf("unexported field in table:\n%s", table)
+	}
+	if _, ok := jupyter.HTMLTable(reflect.ValueOf([]int{1})); ok {
+		t.Error("[]int should not be shown as a table")
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright 2014 Rocky Bernstein.
+// Minimal ZeroMQ (ZMTP 3.0) sockets for talking to Jupyter
+
+package jupyter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// This is just enough of ZMTP 3.0, https://rfc.zeromq.org/spec/23/,
+// to talk to Jupyter front-ends: the NULL security mechanism, and
+// ROUTER, PUB and REP sockets that are bound, and DEALER, SUB and REQ
+// sockets that connect, which is what a client (or a test) needs.
+
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// maxFrameSize limits the frames we accept from peers.
+const maxFrameSize = 64 << 20
+
+// ErrClosed is returned by Recv when the socket has been closed.
+var ErrClosed = errors.New("socket closed")
+
+// greeting returns the 64-byte ZMTP 3.0 greeting for the NULL
+// mechanism.
+func greeting() []byte {
+	g := make([]byte, 64)
+	g[0], g[9] = 0xff, 0x7f
+	g[10], g[11] = 3, 0
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// zconn is a connection to a single peer.
+type zconn struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	wlock    sync.Mutex
+	identity string
+
+	// subs are the prefixes a SUB peer subscribed to.
+	subs [][]byte
+}
+
+// handshake exchanges greetings and READY commands with the peer on
+// conn, telling it that we are a socketType socket.
+func handshake(conn net.Conn, socketType string) (*zconn, error) {
+	z := &zconn{conn: conn, r: bufio.NewReader(conn)}
+	if _, err := conn.Write(greeting()); err != nil {
+		return nil, err
+	}
+	g := make([]byte, 64)
+	if _, err := io.ReadFull(z.r, g); err != nil {
+		return nil, err
+	}
+	if g[0] != 0xff || g[9] != 0x7f {
+		return nil, errors.New("peer does not speak ZMTP")
+	}
+	if g[10] < 3 {
+		return nil, fmt.Errorf("peer speaks ZMTP %d.%d; need 3.0 or later",
+			g[10], g[11])
+	}
+	if mech := string(bytes.TrimRight(g[12:32], "\x00")); mech != "NULL" {
+		return nil, fmt.Errorf("unsupported security mechanism %s", mech)
+	}
+
+	var ready bytes.Buffer
+	ready.WriteByte(5)
+	ready.WriteString("READY")
+	writeProperty(&ready, "Socket-Type", socketType)
+	if err := z.writeFrame(flagCommand, ready.Bytes()); err != nil {
+		return nil, err
+	}
+
+	flags, body, err := z.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	if flags&flagCommand == 0 || len(body) < 6 || string(body[1:6]) != "READY" {
+		return nil, errors.New("expecting a READY command from peer")
+	}
+	props, err := readProperties(body[6:])
+	if err != nil {
+		return nil, err
+	}
+	z.identity = props["Identity"]
+	return z, nil
+}
+
+func writeProperty(buf *bytes.Buffer, name, value string) {
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	binary.Write(buf, binary.BigEndian, uint32(len(value)))
+	buf.WriteString(value)
+}
+
+func readProperties(b []byte) (map[string]string, error) {
+	props := make(map[string]string)
+	for len(b) > 0 {
+		n := int(b[0])
+		if len(b) < 1+n+4 {
+			return nil, errors.New("malformed READY properties")
+		}
+		name := string(b[1 : 1+n])
+		b = b[1+n:]
+		size := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		if uint32(len(b)) < size {
+			return nil, errors.New("malformed READY properties")
+		}
+		props[name] = string(b[:size])
+		b = b[size:]
+	}
+	return props, nil
+}
+
+func (z *zconn) readFrame() (byte, []byte, error) {
+	flags, err := z.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		if err := binary.Read(z.r, binary.BigEndian, &size); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		b, err := z.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes is too big", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(z.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+func (z *zconn) writeFrame(flags byte, body []byte) error {
+	var hdr []byte
+	if len(body) > 255 {
+		hdr = make([]byte, 9)
+		hdr[0] = flags | flagLong
+		binary.BigEndian.PutUint64(hdr[1:], uint64(len(body)))
+	} else {
+		hdr = []byte{flags, byte(len(body))}
+	}
+	if _, err := z.conn.Write(hdr); err != nil {
+		return err
+	}
+	_, err := z.conn.Write(body)
+	return err
+}
+
+// readMessage reads the next multi-part message from the peer.
+// Commands are skipped, except that SUBSCRIBE and CANCEL (from ZMTP
+// 3.1 peers) are turned into the equivalent ZMTP 3.0 messages.
+func (z *zconn) readMessage() ([][]byte, error) {
+	msg := [][]byte{}
+	for {
+		flags, body, err := z.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if flags&flagCommand != 0 {
+			if len(body) > 0 && int(body[0]) < len(body) {
+				switch string(body[1 : 1+body[0]]) {
+				case "SUBSCRIBE":
+					return [][]byte{append([]byte{1}, body[1+body[0]:]...)}, nil
+				case "CANCEL":
+					return [][]byte{append([]byte{0}, body[1+body[0]:]...)}, nil
+				}
+			}
+			continue
+		}
+		msg = append(msg, body)
+		if flags&flagMore == 0 {
+			return msg, nil
+		}
+	}
+}
+
+func (z *zconn) writeMessage(msg [][]byte) error {
+	z.wlock.Lock()
+	defer z.wlock.Unlock()
+	for i, frame := range msg {
+		var flags byte
+		if i < len(msg)-1 {
+			flags = flagMore
+		}
+		if err := z.writeFrame(flags, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribed reports whether a SUB peer wants msg.
+func (z *zconn) subscribed(msg [][]byte) bool {
+	topic := []byte{}
+	if len(msg) > 0 {
+		topic = msg[0]
+	}
+	for _, prefix := range z.subs {
+		if bytes.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Socket is a bound ROUTER, PUB or REP socket, or a connected DEALER,
+// SUB or REQ socket.
+//
+// Messages received on a ROUTER socket start with a frame identifying
+// the peer, and messages sent must start with that frame. REP sockets
+// work the same way, so a reply has to carry the request's envelope;
+// echoing what was received is fine. PUB sockets send to all peers
+// that subscribed to a prefix of the message's first frame.
+type Socket struct {
+	Type string
+
+	listener net.Listener
+	in       chan [][]byte
+	closed   chan struct{}
+	once     sync.Once
+
+	lock   sync.Mutex
+	peers  map[string]*zconn
+	nextID uint32
+
+	// peer is the one connection of a DEALER, SUB or REQ socket.
+	peer *zconn
+}
+
+// Listen binds a socket of type socketType ("ROUTER", "PUB" or
+// "REP") to address addr on network, e.g. "tcp" and "127.0.0.1:0".
+func Listen(socketType, network, addr string) (*Socket, error) {
+	switch socketType {
+	case "ROUTER", "PUB", "REP":
+	default:
+		return nil, fmt.Errorf("can't listen with a %s socket", socketType)
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Socket{
+		Type:     socketType,
+		listener: l,
+		in:       make(chan [][]byte, 16),
+		closed:   make(chan struct{}),
+		peers:    make(map[string]*zconn),
+	}
+	go s.accept()
+	return s, nil
+}
+
+// Dial connects a socket of type socketType ("DEALER", "SUB" or
+// "REQ") to address addr on network.
+func Dial(socketType, network, addr string) (*Socket, error) {
+	switch socketType {
+	case "DEALER", "SUB", "REQ":
+	default:
+		return nil, fmt.Errorf("can't connect with a %s socket", socketType)
+	}
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	z, err := handshake(conn, socketType)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s := &Socket{
+		Type:   socketType,
+		in:     make(chan [][]byte, 16),
+		closed: make(chan struct{}),
+		peer:   z,
+	}
+	go s.read(z)
+	return s, nil
+}
+
+// Addr returns the address a bound socket is listening on.
+func (s *Socket) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Socket) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			z, err := handshake(conn, s.Type)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			s.lock.Lock()
+			if z.identity == "" || s.peers[z.identity] != nil {
+				// Like libzmq, make up an identity starting
+				// with a zero byte.
+				s.nextID++
+				id := make([]byte, 5)
+				binary.BigEndian.PutUint32(id[1:], s.nextID)
+				z.identity = string(id)
+			}
+			s.peers[z.identity] = z
+			s.lock.Unlock()
+			s.read(z)
+			s.lock.Lock()
+			delete(s.peers, z.identity)
+			s.lock.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// read passes on the messages from peer z until it goes away.
+func (s *Socket) read(z *zconn) {
+	for {
+		msg, err := z.readMessage()
+		if err != nil {
+			return
+		}
+		switch s.Type {
+		case "PUB":
+			// All a PUB socket gets is (un)subscriptions.
+			if len(msg) == 1 && len(msg[0]) > 0 {
+				s.lock.Lock()
+				if msg[0][0] == 1 {
+					z.subs = append(z.subs, msg[0][1:])
+				} else {
+					for i, prefix := range z.subs {
+						if bytes.Equal(prefix, msg[0][1:]) {
+							z.subs = append(z.subs[:i], z.subs[i+1:]...)
+							break
+						}
+					}
+				}
+				s.lock.Unlock()
+			}
+			continue
+		case "ROUTER", "REP":
+			msg = append([][]byte{[]byte(z.identity)}, msg...)
+		case "REQ":
+			if len(msg) > 0 && len(msg[0]) == 0 {
+				msg = msg[1:]
+			}
+		}
+		select {
+		case s.in <- msg:
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// Recv returns the next message received on s.
+func (s *Socket) Recv() ([][]byte, error) {
+	select {
+	case msg := <-s.in:
+		return msg, nil
+	case <-s.closed:
+		return nil, ErrClosed
+	}
+}
+
+// Send sends msg on s. On ROUTER and REP sockets, the first frame
+// picks the peer, which is dropped silently if it has gone away.
+func (s *Socket) Send(msg [][]byte) error {
+	switch s.Type {
+	case "ROUTER", "REP":
+		if len(msg) < 2 {
+			return errors.New("message has no peer identity")
+		}
+		s.lock.Lock()
+		z := s.peers[string(msg[0])]
+		s.lock.Unlock()
+		if z == nil {
+			return nil
+		}
+		return z.writeMessage(msg[1:])
+	case "PUB":
+		s.lock.Lock()
+		peers := []*zconn{}
+		for _, z := range s.peers {
+			if z.subscribed(msg) {
+				peers = append(peers, z)
+			}
+		}
+		s.lock.Unlock()
+		for _, z := range peers {
+			// A slow or broken subscriber shouldn't stop the
+			// others getting the message.
+			z.writeMessage(msg)
+		}
+		return nil
+	case "REQ":
+		return s.peer.writeMessage(append([][]byte{{}}, msg...))
+	case "SUB":
+		return errors.New("can't send on a SUB socket")
+	}
+	return s.peer.writeMessage(msg)
+}
+
+// Subscribe asks the PUB socket a SUB socket is connected to for
+// messages whose first frame starts with prefix. Use an empty prefix
+// to get everything.
+func (s *Socket) Subscribe(prefix string) error {
+	if s.Type != "SUB" {
+		return errors.New("only SUB sockets subscribe")
+	}
+	return s.peer.writeMessage([][]byte{append([]byte{1}, prefix...)})
+}
+
+// Close closes s and all its connections.
+func (s *Socket) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, z := range s.peers {
+		z.conn.Close()
+	}
+	if s.peer != nil {
+		s.peer.conn.Close()
+	}
+	return nil
+}
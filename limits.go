@@ -0,0 +1,287 @@
+// Copyright 2014 Rocky Bernstein.
+// Resource limits for evaluations
+
+package repl
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits are the most an evaluation may use before it is aborted. A
+// zero limit means there is none.
+//
+// The heap and goroutines are those of the whole process, which Go
+// doesn't keep track of by goroutine. So what other sessions allocate
+// and start while an evaluation runs counts against its limits too.
+type Limits struct {
+	// Timeout is how long an evaluation may take.
+	Timeout time.Duration
+
+	// MaxHeap is how much, in bytes, the heap may grow while an
+	// evaluation runs. It is sampled with runtime.ReadMemStats, and
+	// growth is counted from the smallest the heap has been, so that
+	// garbage collected along the way doesn't hide it.
+	MaxHeap int64
+
+	// MaxGoroutines is how many more goroutines there may be while an
+	// evaluation runs than when it started.
+	MaxGoroutines int
+}
+
+// DefaultLimits are the limits given to new sessions.
+var DefaultLimits Limits
+
+// MaxLimits are the most that sessions may set their limits to with
+// "set"; a session can lower its limits but not raise them past these.
+// A zero limit here puts no ceiling on that limit.
+var MaxLimits Limits
+
+// limitSampleInterval is how often heap size and goroutines are
+// checked against the limits.
+var limitSampleInterval = 10 * time.Millisecond
+
+// LimitError is the error for an evaluation aborted because it went
+// over one of its limits.
+type LimitError struct {
+	// Setting is the name of the setting for the limit, e.g.
+	// "timeout".
+	Setting string
+	Msg     string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("evaluation aborted: %s (see \"show %s\")", e.Msg,
+		e.Setting)
+}
+
+// outcome is how a function run by WithLimits finished.
+type outcome struct {
+	err      error
+	panicked bool
+	panicVal interface{}
+}
+
+// WithLimits calls fn, which evaluates something, and returns its
+// error, or a LimitError as soon as fn goes over one of the session's
+// Limits. A panic in fn is passed on. Other sessions can run while fn
+// does, so fn shouldn't use package variables like Env and Out.
+//
+// Go can't stop a goroutine from outside of it, so after a LimitError
+// fn goes on running in the background until it finishes, and what it
+// does is ignored. A runaway evaluation that never finishes keeps
+// using what it has, but the session can go on. So fn shouldn't set
+// anything that its caller looks at after a LimitError; it can hand
+// values over in a buffered channel instead.
+func (s *Session) WithLimits(fn func() error) (err error) {
+	s.unlocked(func() { err = s.withLimits(fn) })
+	return err
+}
+
+func (s *Session) withLimits(fn func() error) error {
+	limits := s.Limits
+	if limits == (Limits{}) {
+		return fn()
+	}
+	var base uint64
+	if limits.MaxHeap > 0 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		base = m.HeapAlloc
+	}
+	// One more goroutine is the one running fn.
+	goroutines := runtime.NumGoroutine() + 1
+
+	done := make(chan outcome, 1)
+	go func() {
+		panicked := true
+		defer func() {
+			if panicked {
+				done <- outcome{panicked: true, panicVal: recover()}
+			}
+		}()
+		err := fn()
+		panicked = false
+		done <- outcome{err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if limits.Timeout > 0 {
+		timer := time.NewTimer(limits.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	ticker := time.NewTicker(limitSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case out := <-done:
+			if out.panicked {
+				panic(out.panicVal)
+			}
+			return out.err
+		case <-timeout:
+			return &LimitError{"timeout",
+				fmt.Sprintf("it took longer than %s", limits.Timeout)}
+		case <-ticker.C:
+			if limits.MaxGoroutines > 0 {
+				started := runtime.NumGoroutine() - goroutines
+				if started > limits.MaxGoroutines {
+					return &LimitError{"maxgoroutines", fmt.Sprintf(
+						"it started %d goroutines, more than %d", started,
+						limits.MaxGoroutines)}
+				}
+			}
+			if limits.MaxHeap > 0 {
+				var m runtime.MemStats
+				runtime.ReadMemStats(&m)
+				if m.HeapAlloc < base {
+					base = m.HeapAlloc
+				}
+				growth := int64(m.HeapAlloc - base)
+				if growth > limits.MaxHeap {
+					return &LimitError{"maxheap", fmt.Sprintf(
+						"the heap grew by %s, more than %s",
+						FormatBytes(growth), FormatBytes(limits.MaxHeap))}
+				}
+			}
+		}
+	}
+}
+
+// FormatBytes shows a number of bytes using units of kB, MB and so on,
+// in powers of 1024.
+func FormatBytes(v int64) string {
+	const unit = 1024
+	if v < unit && v > -unit {
+		return fmt.Sprintf("%dB", v)
+	}
+	f, suffix := float64(v), "B"
+	for _, suffix = range []string{"kB", "MB", "GB", "TB"} {
+		f /= unit
+		if f < unit && f > -unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.2f%s", f, suffix)
+}
+
+// byteUnits are the units ParseBytes accepts, in powers of 1024.
+var byteUnits = map[string]int64{
+	"": 1, "B": 1,
+	"K": 1 << 10, "KB": 1 << 10,
+	"M": 1 << 20, "MB": 1 << 20,
+	"G": 1 << 30, "GB": 1 << 30,
+	"T": 1 << 40, "TB": 1 << 40,
+}
+
+// ParseBytes parses a number of bytes such as "512", "64k" or "1.5GB".
+// Units are in powers of 1024, and case doesn't matter.
+func ParseBytes(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	i := strings.IndexFunc(upper, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(upper)
+	}
+	unit, ok := byteUnits[strings.TrimSpace(upper[i:])]
+	n, err := strconv.ParseFloat(upper[:i], 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("expecting a size such as 512, 64k or 1.5GB; got %s", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// checkCeiling returns an error if limit n, where 0 means none, is over
+// max, the ceiling in MaxLimits. show formats a limit.
+func checkCeiling(n, max int64, show func(int64) string) error {
+	if max > 0 && (n == 0 || n > max) {
+		return fmt.Errorf("can't be more than %s, the limit for all sessions",
+			show(max))
+	}
+	return nil
+}
+
+func showDuration(n int64) string { return time.Duration(n).String() }
+
+func showInt(n int64) string { return strconv.FormatInt(n, 10) }
+
+func init() {
+	Settings["timeout"] = &Setting{
+		Help: "abort evaluations that take longer than this",
+		Get: func() string {
+			if Current.Limits.Timeout == 0 {
+				return "off"
+			}
+			return Current.Limits.Timeout.String()
+		},
+		Set: func(value string) error {
+			var d time.Duration
+			if value != "off" {
+				var err error
+				d, err = time.ParseDuration(value)
+				if err != nil || d <= 0 {
+					return fmt.Errorf("expecting a duration such as 5s; got %s",
+						value)
+				}
+			}
+			err := checkCeiling(int64(d), int64(MaxLimits.Timeout), showDuration)
+			if err == nil {
+				Current.Limits.Timeout = d
+			}
+			return err
+		},
+	}
+	Settings["maxheap"] = &Setting{
+		Help: "abort evaluations that grow the heap by more than this",
+		Get: func() string {
+			if Current.Limits.MaxHeap == 0 {
+				return "off"
+			}
+			return FormatBytes(Current.Limits.MaxHeap)
+		},
+		Set: func(value string) error {
+			var n int64
+			if value != "off" {
+				var err error
+				if n, err = ParseBytes(value); err != nil {
+					return err
+				}
+			}
+			err := checkCeiling(n, MaxLimits.MaxHeap, FormatBytes)
+			if err == nil {
+				Current.Limits.MaxHeap = n
+			}
+			return err
+		},
+	}
+	Settings["maxgoroutines"] = &Setting{
+		Help: "abort evaluations that start more goroutines than this",
+		Get: func() string {
+			if Current.Limits.MaxGoroutines == 0 {
+				return "off"
+			}
+			return strconv.Itoa(Current.Limits.MaxGoroutines)
+		},
+		Set: func(value string) error {
+			n := 0
+			if value != "off" {
+				var err error
+				n, err = strconv.Atoi(value)
+				if err != nil || n <= 0 {
+					return fmt.Errorf("expecting a number of goroutines; got %s",
+						value)
+				}
+			}
+			err := checkCeiling(int64(n), int64(MaxLimits.MaxGoroutines), showInt)
+			if err == nil {
+				Current.Limits.MaxGoroutines = n
+			}
+			return err
+		},
+	}
+}
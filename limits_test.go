@@ -0,0 +1,92 @@
+package repl_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+)
+
+func TestLimits(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	for _, line := range []string{"set timeout 100ms", "set maxheap 1MB",
+		"set maxgoroutines 5"} {
+		if out := s.Capture(line, true).Output; strings.Contains(out, "**") {
+			t.Fatalf("%s: %s", line, out)
+		}
+	}
+	want := repl.Limits{Timeout: 100 * time.Millisecond, MaxHeap: 1 << 20,
+		MaxGoroutines: 5}
+	if s.Limits != want {
+		t.Fatalf("expecting limits %+v; got %+v", want, s.Limits)
+	}
+
+	stop := make(chan bool)
+	defer close(stop)
+	held := make(chan []byte, 1)
+	tests := []struct {
+		setting string
+		fn      func() error
+	}{
+		{"timeout", func() error {
+			<-stop
+			return nil
+		}},
+		{"maxheap", func() error {
+			held <- make([]byte, 8<<20)
+			<-stop
+			return nil
+		}},
+		{"maxgoroutines", func() error {
+			for i := 0; i < 10; i++ {
+				go func() { <-stop }()
+			}
+			<-stop
+			return nil
+		}},
+	}
+	for _, test := range tests {
+		runtime.GC()
+		err := s.WithLimits(test.fn)
+		if lerr, ok := err.(*repl.LimitError); !ok || lerr.Setting != test.setting {
+			t.Errorf("%s: expecting a LimitError; got %v", test.setting, err)
+		}
+	}
+	if err := s.WithLimits(func() error { return nil }); err != nil {
+		t.Errorf("expecting no error; got %s", err)
+	}
+
+	repl.MaxLimits = want
+	defer func() { repl.MaxLimits = repl.Limits{} }()
+	for _, line := range []string{"set timeout 1s", "set maxheap off",
+		"set maxgoroutines 6"} {
+		if out := s.Capture(line, true).Output; !strings.Contains(out,
+			"the limit for all sessions") {
+			t.Errorf("%s: expecting to be refused; got %q", line, out)
+		}
+	}
+	if s.Capture("set timeout 50ms", true); s.Limits.Timeout != 50*time.Millisecond {
+		t.Errorf("expecting a lower timeout to be allowed; got %s",
+			s.Limits.Timeout)
+	}
+	if s.Limits.MaxHeap != want.MaxHeap {
+		t.Errorf("expecting maxheap to be left at %d; got %d", want.MaxHeap,
+			s.Limits.MaxHeap)
+	}
+
+	sizes := map[string]int64{"512": 512, "64k": 64 << 10, "1.5GB": 3 << 29,
+		"2 MB": 2 << 20}
+	for text, want := range sizes {
+		if got, err := repl.ParseBytes(text); err != nil || got != want {
+			t.Errorf("ParseBytes(%q): expecting %d; got %d, %v", text, want,
+				got, err)
+		}
+	}
+	for _, text := range []string{"", "lots", "-5", "5X"} {
+		if _, err := repl.ParseBytes(text); err == nil {
+			t.Errorf("ParseBytes(%q): expecting an error", text)
+		}
+	}
+}
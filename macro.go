@@ -0,0 +1,139 @@
+// Copyright 2014 Rocky Bernstein.
+// User-defined aliases and macros
+
+package repl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Macro is a name that, as the first word of a line, is replaced by
+// its body before the line is run as a command or evaluated. In the
+// body, $1 through $9 stand for the words after the name, and $* for
+// all of them. A body without these has whatever follows the name
+// added to its end.
+type Macro struct {
+	Name string
+	Body string
+
+	// Alias is set for macros defined with "alias" rather than
+	// "macro"; they are listed separately.
+	Alias bool
+}
+
+// maxMacroDepth limits how many times macros are expanded in a line,
+// in case a macro expands to itself.
+const maxMacroDepth = 10
+
+// macroParam matches a positional parameter in a macro body.
+var macroParam = regexp.MustCompile(`\$[1-9*]`)
+
+// DefineMacro defines or redefines macro "name" in session s. It is
+// an error if name is already a command or an alias for one.
+func (s *Session) DefineMacro(name, body string, alias bool) error {
+	if name == "" || strings.ContainsAny(name, " \t$") {
+		return fmt.Errorf("%q can't be the name of an alias or macro", name)
+	}
+	if Cmds[name] != nil {
+		return fmt.Errorf("%s is already a command", name)
+	}
+	if cmd := Aliases[name]; cmd != "" {
+		return fmt.Errorf("%s is already an alias for command %s", name, cmd)
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("expecting something for %s to stand for", name)
+	}
+	if s.Macros == nil {
+		s.Macros = make(map[string]*Macro)
+	}
+	s.Macros[name] = &Macro{Name: name, Body: strings.TrimSpace(body),
+		Alias: alias}
+	return nil
+}
+
+// RemoveMacro removes macro "name" from session s.
+func (s *Session) RemoveMacro(name string) error {
+	if s.Macros[name] == nil {
+		if cmd := Aliases[name]; cmd != "" {
+			return fmt.Errorf("%s is a built-in alias for command %s", name, cmd)
+		}
+		return fmt.Errorf("%s is not an alias or macro", name)
+	}
+	delete(s.Macros, name)
+	return nil
+}
+
+// ExpandMacros replaces a macro at the start of line with its body,
+// over and over until line doesn't start with one. The macro's name
+// may have CmdPrefix in front of it.
+func (s *Session) ExpandMacros(line string) (string, error) {
+	for depth := 0; ; depth++ {
+		line = strings.TrimSpace(line)
+		name := line
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			name = line[:i]
+		}
+		m := s.Macros[name]
+		if m == nil && CmdPrefix != "" && strings.HasPrefix(name, CmdPrefix) {
+			if m = s.Macros[name[len(CmdPrefix):]]; m != nil {
+				line = line[len(CmdPrefix):]
+				name = m.Name
+			}
+		}
+		if m == nil {
+			return line, nil
+		}
+		if depth == maxMacroDepth {
+			return "", fmt.Errorf("macros expanded more than %d times; "+
+				"does %s expand to itself?", maxMacroDepth, name)
+		}
+		var err error
+		if line, err = m.expand(line[len(name):]); err != nil {
+			return "", err
+		}
+	}
+}
+
+// expand returns the body of m with parameters replaced by the words
+// in rest.
+func (m *Macro) expand(rest string) (string, error) {
+	if !macroParam.MatchString(m.Body) {
+		return m.Body + rest, nil
+	}
+	args := strings.Fields(rest)
+	used, all := 0, false
+	body := macroParam.ReplaceAllStringFunc(m.Body, func(param string) string {
+		if param == "$*" {
+			all = true
+			return strings.Join(args, " ")
+		}
+		n := int(param[1] - '0')
+		if n > used {
+			used = n
+		}
+		if n > len(args) {
+			return ""
+		}
+		return args[n-1]
+	})
+	if len(args) < used || (len(args) > used && !all) {
+		plural := "s"
+		if used == 1 {
+			plural = ""
+		}
+		return "", fmt.Errorf("%s: expecting %d argument%s; got %d", m.Name,
+			used, plural, len(args))
+	}
+	return body, nil
+}
+
+// Definition is the command that defines m, as it is saved in an init
+// file.
+func (m *Macro) Definition() string {
+	if m.Alias {
+		return CmdPrefix + "alias " + m.Name + " " + m.Body
+	}
+	return CmdPrefix + "macro " + m.Name + " " + m.Body
+}
@@ -0,0 +1,120 @@
+package repl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish"
+)
+
+func TestMacros(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	definitions := []struct {
+		name, body string
+		alias      bool
+	}{
+		{"sq", "$1*$1", false},
+		{"add", "$1 + $2", false},
+		{"p", "fmt.Println", true},
+		{"pp", "p", true},
+		{"all", "f($*)", false},
+	}
+	for _, d := range definitions {
+		if err := s.DefineMacro(d.name, d.body, d.alias); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expansions := map[string]string{
+		"sq 3":            "3*3",
+		"add 1 2":         "1 + 2",
+		"p(\"a  b\")":     "p(\"a  b\")",
+		"p (\"a  b\")":    "fmt.Println (\"a  b\")",
+		"pp 1, 2":         "fmt.Println 1, 2",
+		"all":             "f()",
+		"all 1, 2":        "f(1, 2)",
+		"  sq   x  ":      "x*x",
+		"notamacro 1 2 3": "notamacro 1 2 3",
+	}
+	for line, want := range expansions {
+		if got, err := s.ExpandMacros(line); err != nil || got != want {
+			t.Errorf("%q: expecting %q; got %q, %v", line, want, got, err)
+		}
+	}
+	for _, line := range []string{"sq", "sq 1 2", "add 1"} {
+		if _, err := s.ExpandMacros(line); err == nil {
+			t.Errorf("%q: expecting an error", line)
+		}
+	}
+
+	for _, name := range []string{"help", "q", "two words", ""} {
+		if err := s.DefineMacro(name, "x", true); err == nil {
+			t.Errorf("%q: expecting an error defining it", name)
+		}
+	}
+	if err := repl.AddAlias("q", "help"); err == nil {
+		t.Errorf("AddAlias: expecting an error for an alias already taken")
+	}
+	if err := repl.AddAlias("nosuchalias", "nosuchcommand"); err == nil {
+		t.Errorf("AddAlias: expecting an error for an unknown command")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("MustAddAlias: expecting a panic for an alias already taken")
+			}
+		}()
+		repl.MustAddAlias("h", "quit")
+	}()
+
+	dir, err := ioutil.TempDir("", "go fish's")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s = repl.NewSession(nil, nil, nil)
+	s.InitFile = filepath.Join(dir, "gofishrc")
+	ioutil.WriteFile(s.InitFile, []byte("# my aliases\nalias ll history 1\n"), 0644)
+	s.Capture("source -q "+repl.QuoteArg(s.InitFile), true)
+	if s.Macros["ll"] == nil {
+		t.Fatalf("expecting alias ll from the init file")
+	}
+	s.Capture("macro sq $1*$1", true)
+	s.Capture("unalias ll", true)
+	data, err := ioutil.ReadFile(s.InitFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "# my aliases\n:macro sq $1*$1\n"; got != want {
+		t.Errorf("expecting init file %q; got %q", want, got)
+	}
+	if out := s.Capture("help aliases", true).Output; !strings.Contains(out,
+		"sq  $1*$1") {
+		t.Errorf("expecting help aliases to list sq; got %q", out)
+	}
+
+	// Definitions saved with another prefix are still replaced.
+	s.Capture(":set cmdprefix %", true)
+	s.Capture("%macro sq $1+$1", true)
+	data, err = ioutil.ReadFile(s.InitFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "# my aliases\n%macro sq $1+$1\n"; got != want {
+		t.Errorf("expecting init file %q; got %q", want, got)
+	}
+
+	// Another session sourcing a file doesn't keep this one from saving.
+	other := repl.NewSession(nil, nil, nil)
+	other.SourceDepth = 1
+	s.Capture("%alias ll history 1", true)
+	data, err = ioutil.ReadFile(s.InitFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "alias ll history 1") {
+		t.Errorf("expecting alias ll to be saved; got %q", data)
+	}
+}
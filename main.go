@@ -12,12 +12,21 @@ package main
 // See also main_gr.go for GNU readline code.
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 
+	"github.com/0xfaded/eval"
 	"github.com/rocky/go-fish"
 	"github.com/rocky/go-fish/cmd"
+	"github.com/rocky/go-fish/jupyter"
+	"github.com/rocky/go-fish/repltest"
+	"github.com/rocky/go-fish/web"
 )
 
 func intro_text() {
@@ -36,9 +45,163 @@ To get help, enter: "help".
 
 }
 
+var listenAddr = flag.String("listen", "",
+	`serve REPL sessions on this address instead of using the terminal; `+
+		`"unix:PATH" for a Unix-domain socket`)
+var secret = flag.String("secret", "",
+	"shared secret required by -listen and -http, and sent by connect")
+var readOnly = flag.Bool("readonly", false,
+	"don't allow calls to functions with side effects in -listen and -http sessions")
+var protocol = flag.String("protocol", "text",
+	`"text" for people, or "json" for one JSON request and response `+
+		`per line on standard input and output`)
+var httpAddr = flag.String("http", "",
+	`serve REPL sessions to web browsers on this address, e.g. ":8080"`)
+var kernel = flag.String("kernel", "",
+	"run as a Jupyter kernel using this connection file")
+var initFile = flag.String("init", defaultInitFile(),
+	`file of commands, such as aliases, to run when starting at the terminal; `+
+		`"" for none`)
+var sandbox = flag.String("sandbox", "",
+	`sandbox policy for all sessions: "pure", "no-exec", "no-fs", `+
+		`a policy file, or several of these separated by commas`)
+var timeout = flag.Duration("timeout", 0,
+	`abort evaluations that take longer than this, e.g. "10s"`)
+var maxHeap = flag.String("maxheap", "",
+	`abort evaluations that grow the heap by more than this, e.g. "512MB"`)
+var maxGoroutines = flag.Int("maxgoroutines", 0,
+	"abort evaluations that start more than this many goroutines")
+var cmdPrefix = flag.String("cmdprefix", repl.CmdPrefix,
+	`what starts a line that is a REPL command, as in ":help"; "off" for nothing`)
+var legacy = flag.Bool("legacy", repl.LegacyCommands,
+	"allow REPL commands without the command prefix")
+
+// defaultInitFile returns ~/.gofishrc, or "" if $HOME isn't set.
+func defaultInitFile() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".gofishrc")
+}
+
+// policy is the policy given by -sandbox.
+var policy *repl.Policy
+
+// newSession creates a session with the -sandbox policy.
+func newSession(env *eval.Env, readLineFn repl.ReadLineFnType,
+	inspectFn repl.InspectFnType) *repl.Session {
+	s := repl.NewSession(env, readLineFn, inspectFn)
+	s.Policy = policy
+	return s
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage:
+  %s [options]
+  %s [options] connect ADDR
+  %s [options] test TRANSCRIPT...
+options:
+`, os.Args[0], os.Args[0], os.Args[0])
+	flag.PrintDefaults()
+}
+
+// serve runs REPL sessions for connections to listenAddr.
+func serve(env *eval.Env) {
+	l, err := repl.Listen(*listenAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("go-fish: serving REPL sessions on %s\n", l.Addr())
+	srv := &repl.Server{Env: env, Secret: *secret, ReadOnly: *readOnly,
+		Policy: policy}
+	if err := srv.Serve(l); err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// serveHTTP serves REPL sessions to web browsers on httpAddr.
+func serveHTTP(env *eval.Env) {
+	l, err := net.Listen("tcp", *httpAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+	query := ""
+	if *secret != "" {
+		query = "/?secret=" + url.QueryEscape(*secret)
+	}
+	fmt.Printf("go-fish: serving REPL sessions at http://%s%s\n", l.Addr(), query)
+	srv := &web.Server{Env: env, Secret: *secret, ReadOnly: *readOnly,
+		Policy: policy}
+	if err := http.Serve(l, srv); err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// runTranscripts checks the session transcripts in files; see
+// package repltest.
+func runTranscripts(env *eval.Env, files []string) {
+	newTestSession := func() *repl.Session {
+		return newSession(repl.CopyEnv(env), nil, nil)
+	}
+	failed := 0
+	for _, file := range files {
+		n, err := repltest.CheckFile(file, newTestSession, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+			os.Exit(1)
+		}
+		if n > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d transcripts failed\n", failed, len(files))
+		os.Exit(1)
+	}
+	fmt.Printf("%d transcripts ok\n", len(files))
+	os.Exit(0)
+}
+
+// runKernel runs a Jupyter kernel with the connection file given by
+// -kernel.
+func runKernel(env *eval.Env) {
+	info, err := jupyter.ReadConnectionFile(*kernel)
+	if err == nil {
+		var k *jupyter.Kernel
+		if k, err = jupyter.NewKernel(newSession(env, nil, nil), info); err == nil {
+			err = k.Run()
+			k.Close()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
 // Set up the Go package, function, constant, variable environment; then REPL
 // (Read, Eval, Print, and Loop).
 func main() {
+	flag.Usage = usage
+	flag.Parse()
+	testMode := flag.NArg() > 1 && flag.Arg(0) == "test"
+	if flag.NArg() > 0 && !testMode {
+		if flag.NArg() != 2 || flag.Arg(0) != "connect" {
+			usage()
+			os.Exit(1)
+		}
+		if err := repl.Connect(flag.Arg(1), *secret, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	// A place to store result values of expressions entered
 	// interactively
@@ -47,13 +210,87 @@ func main() {
 	// Make this truly self-referential
 	env.Vars["env"] = reflect.ValueOf(&env)
 
+	// Initialize REPL commands
+	fishcmd.Init()
+
+	if *sandbox != "" {
+		var err error
+		if policy, err = repl.LoadPolicy(*sandbox); err != nil {
+			fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	repl.DefaultLimits.Timeout = *timeout
+	repl.DefaultLimits.MaxGoroutines = *maxGoroutines
+	if *maxHeap != "" {
+		n, err := repl.ParseBytes(*maxHeap)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-fish: -maxheap: %s\n", err)
+			os.Exit(1)
+		}
+		repl.DefaultLimits.MaxHeap = n
+	}
+	repl.MaxLimits = repl.DefaultLimits
+
+	// Legacy mode is on until the prefix is set, so that the prefix
+	// can be turned off.
+	err := repl.Settings["cmdprefix"].Set(*cmdPrefix)
+	if err == nil {
+		err = repl.Settings["legacy"].Set(repl.OnOff(*legacy))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+		os.Exit(1)
+	}
+
+	if testMode {
+		runTranscripts(&env, flag.Args()[1:])
+	}
+
+	if *listenAddr != "" {
+		serve(&env)
+		return
+	}
+
+	if *httpAddr != "" {
+		*repl.Highlight = false
+		serveHTTP(&env)
+		return
+	}
+
+	if *kernel != "" {
+		*repl.Highlight = false
+		runKernel(&env)
+	}
+
+	switch *protocol {
+	case "text":
+	case "json":
+		*repl.Highlight = false
+		s := newSession(&env, nil, nil)
+		if err := s.ServeJSON(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "go-fish: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(s.ExitCode)
+	default:
+		fmt.Fprintf(os.Stderr, "go-fish: unknown protocol %s\n", *protocol)
+		usage()
+		os.Exit(1)
+	}
+
 	intro_text()
 
 	repl.Input = bufio.NewReader(os.Stdin)
 
-	// Initialize REPL commands
-	fishcmd.Init()
-
-	repl.REPL(&env, repl.SimpleReadLine, repl.SimpleInspect)
-	os.Exit(repl.ExitCode)
+	s := newSession(&env, repl.SimpleReadLine, repl.SimpleInspect)
+	s.InitFile = *initFile
+	if _, err := os.Stat(s.InitFile); err == nil {
+		s.ProcessLine(repl.CmdPrefix + "source -q " + repl.QuoteArg(s.InitFile))
+	}
+	if err := s.Run(); err != nil {
+		panic(err)
+	}
+	os.Exit(s.ExitCode)
 }
@@ -4,13 +4,20 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
 	"go/token"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 	"code.google.com/p/go.tools/importer"
@@ -35,6 +42,75 @@ const MyImport = "github.com/rocky/go-fish"
 // asked for, we will exclude it by default.
 var excludeSyscallConsts bool = true
 
+// withDocs is set when we should also emit doc strings into
+// repl.Docs so that the REPL "doc" command works when package source
+// is not available.
+var withDocs = flag.Bool("docs", false,
+	"embed package documentation in the generated code")
+
+// preferredPaths gives, for package names that are shared by more
+// than one package, the import path that gets to keep the plain
+// name. Other packages with that name get an alias. When a name isn't
+// listed here, the first import path in sort order wins. It is read
+// from PreferredPaths in go-fish's pkgnames.go by loadPreferredPaths,
+// so that the REPL and the generated code agree.
+var preferredPaths = make(map[string]string)
+
+// loadPreferredPaths fills in preferredPaths from the map literal
+// given to PreferredPaths in pkgnames.go.
+func loadPreferredPaths() error {
+	bpkg, err := build.Import(MyImport, "", build.FindOnly)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, "pkgnames.go"),
+		nil, 0)
+	if err != nil {
+		return err
+	}
+	obj := file.Scope.Lookup("PreferredPaths")
+	if obj == nil {
+		return fmt.Errorf("PreferredPaths not found in pkgnames.go")
+	}
+	spec, ok := obj.Decl.(*ast.ValueSpec)
+	if !ok || len(spec.Values) != 1 {
+		return fmt.Errorf("PreferredPaths in pkgnames.go isn't a map literal")
+	}
+	lit, ok := spec.Values[0].(*ast.CompositeLit)
+	if !ok {
+		return fmt.Errorf("PreferredPaths in pkgnames.go isn't a map literal")
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, kerr := stringLit(kv.Key)
+		value, verr := stringLit(kv.Value)
+		if kerr != nil || verr != nil {
+			return fmt.Errorf("PreferredPaths in pkgnames.go: expecting "+
+				"string keys and values; got %s", nodeString(fset, kv))
+		}
+		preferredPaths[key] = value
+	}
+	return nil
+}
+
+// stringLit returns the value of e if it is a string literal.
+func stringLit(e ast.Expr) (string, error) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", fmt.Errorf("not a string literal")
+	}
+	return strconv.Unquote(lit.Value)
+}
+
+// importNames maps an import path to the alias we import it under in
+// the generated code, for those packages whose name collides with
+// another package's name.
+var importNames = make(map[string]string)
+
 // isExportedIdent returns false if e is an Ident with name "_".
 // These identifers have no associated types.Object, and thus no type.
 // isExportedIdent also returns false if identifier e doesn't start
@@ -96,6 +172,9 @@ func memberFromDecl(decl ast.Decl, imp *importer.Importer,
 }
 
 func fullIdentName(path, pkg, ident string) (fullname string) {
+	if alias, ok := importNames[path]; ok {
+		pkg = alias
+	}
 	fullname = pkg + "." + ident
 	if "repl" == pkg && MyImport == path {
 		// This is me my package! We can't include repl
@@ -176,7 +255,8 @@ func extractPackageSymbols(pkg_info *importer.PackageInfo, imp *importer.Importe
 		fmt.Println("\n\ttypes = make(map[string] reflect.Type)")
 		for _, v := range types {
 			fullname := fullIdentName(path, name, *v)
-			fmt.Printf("\ttypes[\"%s\"] = reflect.TypeOf(*new(%s))\n", *v, fullname)
+			// TypeOf(*new(T)) would give nil for interface types.
+			fmt.Printf("\ttypes[\"%s\"] = reflect.TypeOf(new(%s)).Elem()\n", *v, fullname)
 		}
 
 		fmt.Println("\n\tvars = make(map[string] reflect.Value)")
@@ -194,7 +274,13 @@ func extractPackageSymbols(pkg_info *importer.PackageInfo, imp *importer.Importe
 		Pkgs:   pkgs,
 		Path:   "%s",
 	}
-`, name, name, path)
+`, path, name, path)
+		if alias, ok := importNames[path]; ok {
+			fmt.Printf("\tPkgAliases[\"%s\"] = \"%s\"\n", path, alias)
+		}
+		if *withDocs {
+			writeDocs(path)
+		}
 	}
 
 	// } else {
@@ -216,6 +302,77 @@ func extractPackageSymbols(pkg_info *importer.PackageInfo, imp *importer.Importe
 
 }
 
+// nodeString pretty-prints a declaration without its doc comment or
+// function body.
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	switch decl := node.(type) {
+	case *ast.FuncDecl:
+		d := *decl
+		d.Doc, d.Body = nil, nil
+		node = &d
+	case *ast.GenDecl:
+		d := *decl
+		d.Doc = nil
+		node = &d
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+// writeDocs writes assignments to repl.Docs for the package with
+// import path "path" and its exported members. See the Docs variable
+// in godoc.go for the format.
+func writeDocs(path string) {
+	bpkg, err := build.Import(path, "", 0)
+	if err != nil {
+		fmt.Printf("\t// no documentation for %s: %s\n", path, err)
+		return
+	}
+	fset := token.NewFileSet()
+	files := make(map[string]*ast.File)
+	for _, name := range bpkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, name),
+			nil, parser.ParseComments)
+		if err != nil {
+			fmt.Printf("\t// no documentation for %s: %s\n", path, err)
+			return
+		}
+		files[name] = file
+	}
+	pkg := doc.New(&ast.Package{Name: bpkg.Name, Files: files}, path, 0)
+
+	emit := func(key, decl, text string) {
+		fmt.Printf("\tDocs[%q] = %q\n", key, decl+"\n\n"+text)
+	}
+	emitValues := func(values []*doc.Value) {
+		for _, v := range values {
+			decl := nodeString(fset, v.Decl)
+			for _, name := range v.Names {
+				emit(path+"."+name, decl, v.Doc)
+			}
+		}
+	}
+	emitFuncs := func(prefix string, funcs []*doc.Func) {
+		for _, f := range funcs {
+			emit(prefix+f.Name, nodeString(fset, f.Decl), f.Doc)
+		}
+	}
+
+	emit(path, fmt.Sprintf("package %s // import \"%s\"", pkg.Name, path),
+		pkg.Doc)
+	emitValues(pkg.Consts)
+	emitValues(pkg.Vars)
+	emitFuncs(path+".", pkg.Funcs)
+	for _, t := range pkg.Types {
+		emit(path+"."+t.Name, nodeString(fset, t.Decl), t.Doc)
+		emitValues(t.Consts)
+		emitValues(t.Vars)
+		emitFuncs(path+".", t.Funcs)
+		emitFuncs(path+"."+t.Name+".", t.Methods)
+	}
+}
+
 // By is the type of a "less" function that defines the ordering of
 // its Planet arguments.
 type By func(p1, p2 *importer.PackageInfo) bool
@@ -253,6 +410,64 @@ func (s *packageInfoSorter) Less(i, j int) bool {
 	return s.by(s.pkg_infos[i], s.pkg_infos[j])
 }
 
+// aliasFor makes up an import alias for the package with import path
+// "path" and name "name" by prefixing the name with the path element
+// before it, e.g. "cryptorand" for "crypto/rand". Digits are added if
+// that is already taken according to "taken".
+func aliasFor(path, name string, taken map[string]bool) string {
+	prefix := ""
+	elts := strings.Split(path, "/")
+	if len(elts) > 1 {
+		for _, r := range elts[len(elts)-2] {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				prefix += string(unicode.ToLower(r))
+			}
+		}
+	}
+	alias := prefix + name
+	for i := 2; taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%s%d", prefix, name, i)
+	}
+	return alias
+}
+
+// assignImportNames fills in importNames for those packages whose
+// name is the same as some other package's name. See preferredPaths
+// for which package keeps the plain name.
+func assignImportNames(pkg_infos []*importer.PackageInfo) {
+	byName := make(map[string][]string)
+	taken := make(map[string]bool)
+	for _, pkg_info := range pkg_infos {
+		name := pkg_info.Pkg.Name()
+		byName[name] = append(byName[name], pkg_info.Pkg.Path())
+		taken[name] = true
+	}
+	names := []string{}
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names) // for reproducible output
+	for _, name := range names {
+		paths := byName[name]
+		if len(paths) < 2 {
+			continue
+		}
+		keeper := paths[0]
+		for _, path := range paths {
+			if path == preferredPaths[name] {
+				keeper = path
+			}
+		}
+		for _, path := range paths {
+			if path != keeper {
+				alias := aliasFor(path, name, taken)
+				taken[alias] = true
+				importNames[path] = alias
+			}
+		}
+	}
+}
+
 // writePreamble prints the initial boiler-plate Go package code. That
 // is it starts out:
 //     package repl; import (... )
@@ -271,18 +486,26 @@ import (`)
 	for _, pkg_info := range pkg_infos {
 		path := pkg_info.Pkg.Path()
 		if !strings.HasSuffix(path, "_test") {
-			if	MyImport != path {
+			kept_pkgs = append(kept_pkgs, pkg_info)
+		}
+	}
+	assignImportNames(kept_pkgs)
+	for _, pkg_info := range kept_pkgs {
+		path := pkg_info.Pkg.Path()
+		if	MyImport != path {
+			if alias, ok := importNames[path]; ok {
+				fmt.Printf("\t%s \"%s\"\n", alias, path)
+			} else {
 				fmt.Printf("\t\"%s\"\n", path)
 			}
-			kept_pkgs = append(kept_pkgs, pkg_info)
 		}
 	}
 	fmt.Printf(`)
 
 type pkgType map[string] eval.Pkg
 
-// %sEnvironment adds to eval.Pkg those packages included
-// with import "%s".
+// %sEnvironment adds to pkgs, keyed by import path, those packages
+// included with import "%s".
 
 func %sEnvironment(pkgs pkgType) {
 	var consts map[string] reflect.Value
@@ -303,13 +526,22 @@ func writePostamble() {
 // environment (of type eval.Env) the transitive closure of imports
 // for a given starting package. Here we use github.com/0xfaded/eval.
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [-docs] [starting-import]\n",
+			os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 	startingImport := DefaultStartingImport
-	if len(os.Args) == 2 {
-		startingImport = os.Args[1]
-	} else if len(os.Args) > 2 {
-		fmt.Printf("usage: %s [starting-import]\n")
+	if flag.NArg() == 1 {
+		startingImport = flag.Arg(0)
+	} else if flag.NArg() > 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
+	if err := loadPreferredPaths(); err != nil {
+		log.Fatal(err)
+	}
 	fmt.Printf("// starting import: \"%s\"\n", startingImport)
 
 	impctx := importer.Config{Build: &build.Default}
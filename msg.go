@@ -4,6 +4,7 @@ package repl
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -14,6 +15,10 @@ import (
 
 var	termReset, termBold, termHighlight string
 
+// Out is where REPL output goes. Sessions set this to their own
+// output while they run a command or evaluate an expression.
+var Out io.Writer = os.Stdout
+
 func init() {
 	termReset     = ansi.ColorCode("reset")
 	termBold      = ansi.ColorCode("+b")
@@ -26,17 +31,17 @@ func Errmsg(format string, a ...interface{}) (n int, err error) {
 	} else {
 		format = "** " + format + "\n"
 	}
-	return fmt.Fprintf(os.Stdout, format, a...)
+	return fmt.Fprintf(Out, format, a...)
 }
 
 func MsgNoCr(format string, a ...interface{}) (n int, err error) {
 	format = format
-	return fmt.Fprintf(os.Stdout, format, a...)
+	return fmt.Fprintf(Out, format, a...)
 }
 
 func Msg(format string, a ...interface{}) (n int, err error) {
 	format = format + "\n"
-	return fmt.Fprintf(os.Stdout, format, a...)
+	return fmt.Fprintf(Out, format, a...)
 }
 
 // A more emphasized version of msg. For section headings.
@@ -46,7 +51,7 @@ func Section(format string, a ...interface{}) (n int, err error) {
 	} else {
 		format = format + "\n"
 	}
-	return fmt.Fprintf(os.Stdout, format, a...)
+	return fmt.Fprintf(Out, format, a...)
 }
 
 func PrintSorted(title string, names []string) {
@@ -0,0 +1,145 @@
+// Copyright 2014 Rocky Bernstein.
+// Package names, aliases and name conflicts
+
+package repl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/0xfaded/eval"
+)
+
+// Packages holds every package compiled into go-fish keyed by import
+// path. The evaluation environment's Pkgs map is keyed by the name
+// used for a package at the prompt instead.
+var Packages pkgType = make(pkgType)
+
+// PkgAliases maps an import path to the name a package goes by at the
+// prompt when that isn't the package's own name. Code generated by
+// make_env adds entries for packages whose names collide, and
+// MakeEvalEnv the rest.
+var PkgAliases map[string]string = make(map[string]string)
+
+// PkgConflicts maps a package name to the import paths of all
+// packages having that name, for names shared by more than one
+// package. For example "rand" might map to "crypto/rand" and
+// "math/rand". It is filled in by MakeEvalEnv.
+var PkgConflicts map[string][]string = make(map[string][]string)
+
+// PreferredPaths gives, for package names shared by more than one
+// package, the import path that gets to keep the plain name. make_env
+// reads this map literal from here, so it must stay a literal of
+// strings.
+var PreferredPaths map[string]string = map[string]string{
+	"rand":     "math/rand",
+	"scanner":  "go/scanner",
+	"template": "text/template",
+}
+
+// ResolvePackageNames returns a map of the packages in pkgs, which is
+// keyed by import path, keyed instead by the name by which each is
+// known at the prompt. That is its entry in aliases or else its
+// package name. When two packages would get the same name, the one in
+// PreferredPaths, or else the first in import path order, keeps it and
+// the others get an alias. Also returned are aliases with those added,
+// and a map like PkgConflicts of the names shared by more than one
+// package. Neither pkgs nor aliases is changed.
+func ResolvePackageNames(pkgs pkgType, aliases map[string]string) (
+	byName map[string]eval.Pkg, allAliases map[string]string,
+	conflicts map[string][]string) {
+	paths := []string{}
+	for path := range pkgs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	preferred := []string{}
+	others := []string{}
+	for _, path := range paths {
+		if PreferredPaths[pkgs[path].Name] == path {
+			preferred = append(preferred, path)
+		} else {
+			others = append(others, path)
+		}
+	}
+	paths = append(preferred, others...)
+
+	byName = make(map[string]eval.Pkg)
+	allAliases = make(map[string]string)
+	// Explicit aliases go first so they don't get taken.
+	for _, path := range paths {
+		if alias, ok := aliases[path]; ok {
+			byName[alias] = pkgs[path]
+			allAliases[path] = alias
+		}
+	}
+	names := make(map[string][]string)
+	for _, path := range paths {
+		pkg := pkgs[path]
+		names[pkg.Name] = append(names[pkg.Name], path)
+		if _, ok := aliases[path]; ok {
+			continue
+		}
+		name := pkg.Name
+		if _, taken := byName[name]; taken {
+			name = PackageAlias(path, name, byName)
+			allAliases[path] = name
+		}
+		byName[name] = pkg
+	}
+	conflicts = make(map[string][]string)
+	for name, paths := range names {
+		if len(paths) > 1 {
+			conflicts[name] = paths
+		}
+	}
+	return byName, allAliases, conflicts
+}
+
+// PackageAlias makes up a name for the package with import path
+// "path" and name "name" that isn't in "taken". The name is prefixed
+// with the path element before it, e.g. "cryptorand" for
+// "crypto/rand", and digits are added after that if needed.
+func PackageAlias(path, name string, taken map[string]eval.Pkg) string {
+	prefix := ""
+	elts := strings.Split(path, "/")
+	if len(elts) > 1 {
+		for _, r := range elts[len(elts)-2] {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				prefix += string(unicode.ToLower(r))
+			}
+		}
+	}
+	alias := prefix + name
+	for i := 2; ; i++ {
+		if _, ok := taken[alias]; !ok {
+			return alias
+		}
+		alias = fmt.Sprintf("%s%s%d", prefix, name, i)
+	}
+}
+
+// ImportPackage makes the compiled-in package with import path "path"
+// available in env under "name", or under its alias or package name
+// if name is "". This is what "import name path" does at the prompt.
+func ImportPackage(env *eval.Env, name string, path string) error {
+	pkg, ok := Packages[path]
+	if !ok {
+		return fmt.Errorf("package \"%s\" is not compiled into go-fish; "+
+			"see make_env", path)
+	}
+	if name == "" {
+		name = pkg.Name
+		if alias, ok := PkgAliases[path]; ok {
+			name = alias
+		}
+	}
+	if old, ok := env.Pkgs[name]; ok && old.Path != path {
+		return fmt.Errorf("name %s is already used for package \"%s\"",
+			name, old.Path)
+	}
+	env.Pkgs[name] = pkg
+	return nil
+}
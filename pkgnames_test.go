@@ -0,0 +1,40 @@
+package repl_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+func TestResolvePackageNames(t *testing.T) {
+	pkgs := map[string]eval.Pkg{
+		"crypto/rand": &eval.Env{Name: "rand", Path: "crypto/rand"},
+		"math/rand":   &eval.Env{Name: "rand", Path: "math/rand"},
+		"strings":     &eval.Env{Name: "strings", Path: "strings"},
+	}
+	aliases := map[string]string{"strings": "str"}
+	byName, allAliases, conflicts := repl.ResolvePackageNames(pkgs, aliases)
+	for name, path := range map[string]string{"rand": "math/rand",
+		"cryptorand": "crypto/rand", "str": "strings"} {
+		if pkg, ok := byName[name]; !ok || pkg.Path != path {
+			t.Errorf("expecting %s to be package \"%s\"; got %v", name, path, pkg)
+		}
+	}
+	want := map[string]string{"strings": "str", "crypto/rand": "cryptorand"}
+	if !reflect.DeepEqual(allAliases, want) {
+		t.Errorf("expecting aliases %v; got %v", want, allAliases)
+	}
+	if len(aliases) != 1 {
+		t.Errorf("expecting the aliases given to be left alone; got %v", aliases)
+	}
+	if got := conflicts["rand"]; len(conflicts) != 1 || len(got) != 2 {
+		t.Errorf("expecting a conflict for rand only; got %v", conflicts)
+	}
+	for _, pkg := range pkgs {
+		if pkg.Pkgs != nil {
+			t.Errorf("expecting package %s to be left alone", pkg.Path)
+		}
+	}
+}
@@ -4,36 +4,277 @@
 package repl
 
 import (
+	"fmt"
+	"go/parser"
+	"regexp"
 	"strings"
+	"unicode"
 )
 
+// CmdLine is the line of the command being run, without the command
+// prefix.
 var CmdLine string
 
+// CmdRest is what follows the command name in CmdLine, as it was
+// typed, for commands that take Go code or other text that shouldn't
+// be split into arguments.
+var CmdRest string
+
+// CmdPrefix starts a line that is a REPL command, as in ":help". It
+// can't be something that starts a Go expression.
+var CmdPrefix = ":"
+
+// LegacyCommands allows commands to be given without CmdPrefix. A line
+// is then a command if its first word is one, unless it is Go that
+// makes sense on its own.
+var LegacyCommands = true
+
+// shellCapture matches "VAR := !!command" and "VAR = !!command".
+var shellCapture = regexp.MustCompile(`^([\pL_][\pL\pN_]*)\s*:?=\s*!!(.*)$`)
+
+// shellEscape turns the shell escapes "!command", "!!command" and
+// "VAR := !!command" into "shell" command lines. Unless explicit is
+// set, as it is after the command prefix, a line such as "!ok" that
+// negates a Go expression is left as it is.
+func shellEscape(line string, explicit bool) string {
+	if m := shellCapture.FindStringSubmatch(line); m != nil {
+		return "shell -o " + m[1] + " " + m[2]
+	}
+	if strings.HasPrefix(line, "!!") {
+		return "shell -r " + line[2:]
+	}
+	if strings.HasPrefix(line, "!") && (explicit || !isNegation(line)) {
+		return "shell " + line[1:]
+	}
+	return line
+}
+
+// isNegation reports whether line, which starts with "!", is a Go
+// expression rather than a shell escape: it parses, and what it
+// negates starts with a name Go knows of, as in "!ok" or
+// "!strings.HasPrefix(s, "x")", or with something other than a name,
+// as in "!(a < b)".
+func isNegation(line string) bool {
+	if _, err := parser.ParseExpr(line); err != nil {
+		return false
+	}
+	rest := strings.TrimLeftFunc(line[1:], unicode.IsSpace)
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	name := rest[:end]
+	return name == "" || isGo(name, name)
+}
+
 func wasProcessed(line string) bool {
-	CmdLine = strings.Trim(line, " \t\n")
-	args  := strings.Split(CmdLine, " ")
-	if len(args) == 0 || len(args[0]) == 0 {
+	CmdLine = strings.TrimSpace(line)
+	if CmdLine == "" {
 		Msg("Empty line skipped")
 		// gnureadline.RemoveHistory(gnureadline.HistoryLength()-1)
 		return true
 	}
-	if args[0][0] == '/' && len(args) > 1 && args[0][1] == '/' {
+	if strings.HasPrefix(CmdLine, "//") {
 		// gnureadline.RemoveHistory(gnureadline.HistoryLength()-1)
 		Msg(line) // echo line but do nothing
 		return true
 	}
 
-	name := args[0]
-	if newname := LookupCmd(name); newname != "" {
-		name = newname
+	// A line is certainly a command if it starts with the command
+	// prefix or is a shell escape.
+	explicit := true
+	if escaped := shellEscape(CmdLine, false); escaped != CmdLine {
+		CmdLine = escaped
+	} else if CmdPrefix != "" && strings.HasPrefix(CmdLine, CmdPrefix) {
+		CmdLine = strings.TrimLeftFunc(CmdLine[len(CmdPrefix):], unicode.IsSpace)
+		if CmdLine == "" {
+			Errmsg("Expecting a command after %s", CmdPrefix)
+			return true
+		}
+		CmdLine = shellEscape(CmdLine, true)
+	} else if !LegacyCommands {
+		return false
+	} else {
+		explicit = false
 	}
-	cmd := Cmds[name];
+
+	fields := strings.Fields(CmdLine)
+	if len(fields) == 0 {
+		return true
+	}
+	name := fields[0]
+	CmdRest = strings.TrimSpace(CmdLine[len(name):])
+	if Cmds[name] == nil && Aliases[name] == "" {
+		if !explicit && isGo(name, CmdLine) {
+			return false
+		}
+		if candidates := CmdCandidates(name); len(candidates) > 1 {
+			Errmsg("Ambiguous command %s: %s", name,
+				strings.Join(candidates, ", "))
+			return true
+		}
+	} else if !explicit && shadowsCommand(name, CmdLine) {
+		return false
+	}
+	cmdName := LookupCmd(name)
+	cmd := Cmds[cmdName];
 
 	if cmd != nil {
+		if Current != nil {
+			if err := Current.CheckCommand(cmdName); err != nil {
+				Errmsg("%s", err)
+				return true
+			}
+		}
+		args := strings.Fields(CmdRest)
+		if !cmd.RawArgs {
+			var err error
+			if args, err = SplitArgs(CmdRest); err != nil {
+				Errmsg("%s", err)
+				return true
+			}
+		}
+		if cmd.Args != nil {
+			parsed, err := ParseArgs(cmd.Args, args, CmdRest)
+			if err != nil {
+				Errmsg("%s", err)
+				return true
+			}
+			CmdArgs = parsed
+			cmd.Fn(append([]string{name}, args...))
+			return true
+		}
+		args = append([]string{name}, args...)
 		if ArgCountOK(cmd.Min_args, cmd.Max_args, args) {
-			Cmds[name].Fn(args)
+			cmd.Fn(args)
+		}
+		return true
+	}
+	if explicit {
+		Errmsg("Unknown command %s; try \"%shelp *\"", name, CmdPrefix)
+		return true
+	}
+	return false
+}
+
+// shadowsCommand reports whether "name", a command or alias at the
+// start of line, has been defined in the current session's
+// environment and line is a Go expression, so that line should be
+// evaluated. The first time this happens for name, a warning is
+// given.
+func shadowsCommand(name, line string) bool {
+	if Current == nil {
+		return false
+	}
+	if _, ok := Current.Env.Pkgs[name]; !ok && !isEnvName(Current.Env, name) {
+		return false
+	}
+	if _, err := parser.ParseExpr(line); err != nil {
+		return false
+	}
+	if !Current.shadowWarned[name] {
+		if Current.shadowWarned == nil {
+			Current.shadowWarned = make(map[string]bool)
 		}
+		Current.shadowWarned[name] = true
+		if CmdPrefix != "" {
+			Msg("Note: %s is a Go name as well as a command, so it is "+
+				"evaluated; enter \"%s%s\" for the command", name, CmdPrefix, name)
+		} else {
+			Msg("Note: %s is a Go name as well as a command, so it is "+
+				"evaluated", name)
+		}
+	}
+	return true
+}
+
+// isGo reports whether a line starting with "name", which isn't a
+// command or an alias, is meant to be Go rather than an abbreviated
+// command: name is something Go knows of, or the line is an
+// expression with more to it than name.
+func isGo(name, line string) bool {
+	if Current != nil {
+		if isEnvName(Current.Env, name) {
+			return true
+		}
+		if _, ok := Current.Env.Pkgs[name]; ok {
+			return true
+		}
+	}
+	if isBasicTypeName(name) || isPredeclared(name) {
+		return true
+	}
+	if line == name {
+		return false
+	}
+	_, err := parser.ParseExpr(line)
+	return err == nil
+}
+
+// isPredeclared reports whether name is a predeclared constant or
+// function, other than a type name.
+func isPredeclared(name string) bool {
+	switch name {
+	case "true", "false", "nil", "iota", "append", "cap", "close",
+		"complex", "copy", "delete", "imag", "len", "make", "new",
+		"panic", "print", "println", "real", "recover":
 		return true
 	}
 	return false
 }
+
+// checkCmdPrefix returns an error if prefix can't be a command prefix,
+// because it could start a Go expression, a comment or a shell
+// escape.
+func checkCmdPrefix(prefix string) error {
+	for _, r := range prefix {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) ||
+			strings.ContainsRune("_\"'`([{*&-+^<!./", r) {
+			return fmt.Errorf("a command prefix can't contain %q", r)
+		}
+	}
+	return nil
+}
+
+func init() {
+	Settings["cmdprefix"] = &Setting{
+		Help: `what starts a line that is a REPL command; "off" for nothing`,
+		Get: func() string {
+			if CmdPrefix == "" {
+				return "off"
+			}
+			return CmdPrefix
+		},
+		Set: func(value string) error {
+			if value == "off" {
+				if !LegacyCommands {
+					return fmt.Errorf("commands need a prefix when legacy is off")
+				}
+				value = ""
+			} else if err := checkCmdPrefix(value); err != nil {
+				return err
+			}
+			ownSettings()
+			CmdPrefix = value
+			return nil
+		},
+	}
+	Settings["legacy"] = &Setting{
+		Help: "allow REPL commands without the command prefix",
+		Get:  func() string { return OnOff(LegacyCommands) },
+		Set: func(value string) error {
+			b, err := ParseOnOff(value)
+			if err != nil {
+				return err
+			}
+			if !b && CmdPrefix == "" {
+				return fmt.Errorf("commands need a prefix when legacy is off")
+			}
+			ownSettings()
+			LegacyCommands = b
+			return nil
+		},
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2014 Rocky Bernstein.
+// Read-only evaluation: rejecting calls that may have side effects
+
+package repl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/0xfaded/eval"
+)
+
+// PureFuncs lists the functions that may be called in read-only
+// mode since they have no side effects. A key is either an import
+// path and function name, e.g. "fmt.Sprintf", which allows just that
+// function, or an import path, e.g. "strings", which allows all of
+// the package's functions.
+var PureFuncs map[string]bool = map[string]bool{
+	"bytes.Compare":    true,
+	"bytes.Contains":   true,
+	"bytes.Equal":      true,
+	"bytes.HasPrefix":  true,
+	"bytes.HasSuffix":  true,
+	"bytes.Index":      true,
+	"errors.New":       true,
+	"fmt.Errorf":       true,
+	"fmt.Sprint":       true,
+	"fmt.Sprintf":      true,
+	"fmt.Sprintln":     true,
+	"math":             true,
+	"path":             true,
+	"reflect.DeepEqual": true,
+	"reflect.TypeOf":   true,
+	"reflect.ValueOf":  true,
+	"regexp.Compile":   true,
+	"regexp.MatchString": true,
+	"regexp.QuoteMeta": true,
+	"strconv":          true,
+	"strings":          true,
+	"time.Now":         true,
+	"time.Since":       true,
+	"unicode":          true,
+	"unicode/utf8":     true,
+}
+
+// PureMethods lists the names of methods that may be called in
+// read-only mode. Since we can't tell in general what a method does,
+// this is limited to conventional accessors.
+var PureMethods map[string]bool = map[string]bool{
+	"Error":  true,
+	"Len":    true,
+	"String": true,
+}
+
+// pureBuiltins are the builtin functions allowed in read-only mode.
+var pureBuiltins = map[string]bool{
+	"cap": true, "complex": true, "imag": true, "len": true,
+	"make": true, "new": true, "real": true,
+}
+
+// CheckReadOnly returns an error if evaluating expr in env might have
+// side effects: if it calls a function other than one in PureFuncs,
+// a method other than one in PureMethods, receives from a channel or
+// contains a function literal.
+func CheckReadOnly(expr ast.Expr, env *eval.Env) error {
+	var err error
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			err = fmt.Errorf("read-only mode: function literals are not allowed")
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				err = fmt.Errorf("read-only mode: channel receives are not allowed")
+			}
+		case *ast.CallExpr:
+			err = checkReadOnlyCall(n.Fun, env)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// checkReadOnlyCall checks the function part of a call expression;
+// see CheckReadOnly.
+func checkReadOnlyCall(fun ast.Expr, env *eval.Env) error {
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if _, ok := env.Funcs[f.Name]; ok {
+			if !PureFuncs[f.Name] {
+				return fmt.Errorf("read-only mode: call to %s is not allowed",
+					f.Name)
+			}
+			return nil
+		}
+		if _, ok := env.Types[f.Name]; ok || isBasicTypeName(f.Name) {
+			return nil // a conversion
+		}
+		if pureBuiltins[f.Name] {
+			return nil
+		}
+		return fmt.Errorf("read-only mode: call to %s is not allowed", f.Name)
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			if pkg, ok := env.Pkgs[x.Name]; ok {
+				name := f.Sel.Name
+				if _, ok := pkg.Types[name]; ok {
+					return nil // a conversion
+				}
+				if PureFuncs[pkg.Path] || PureFuncs[pkg.Path+"."+name] {
+					return nil
+				}
+				return fmt.Errorf("read-only mode: call to %s.%s is not allowed",
+					x.Name, name)
+			}
+		}
+		if PureMethods[f.Sel.Name] {
+			return nil
+		}
+		return fmt.Errorf("read-only mode: call to method %s is not allowed",
+			f.Sel.Name)
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType,
+		*ast.InterfaceType, *ast.StructType:
+		return nil // a conversion
+	case *ast.StarExpr:
+		if isTypeExpr(f.X, env) {
+			return nil // a conversion to a pointer type
+		}
+	}
+	return fmt.Errorf("read-only mode: calls through function values are not allowed")
+}
+
+// isTypeExpr reports whether e names a type in env: a type name, a
+// package's type or a pointer to one of those.
+func isTypeExpr(e ast.Expr, env *eval.Env) bool {
+	switch t := e.(type) {
+	case *ast.ParenExpr:
+		return isTypeExpr(t.X, env)
+	case *ast.StarExpr:
+		return isTypeExpr(t.X, env)
+	case *ast.Ident:
+		_, ok := env.Types[t.Name]
+		return ok || isBasicTypeName(t.Name)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if pkg, ok := env.Pkgs[x.Name]; ok {
+				_, ok := pkg.Types[t.Sel.Name]
+				return ok
+			}
+		}
+	}
+	return false
+}
+
+// isBasicTypeName reports whether name is a predeclared type name.
+func isBasicTypeName(name string) bool {
+	switch name {
+	case "bool", "byte", "complex64", "complex128", "error", "float32",
+		"float64", "int", "int8", "int16", "int32", "int64", "rune",
+		"string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package repl_test
+
+import (
+	"go/parser"
+	"testing"
+
+	"github.com/rocky/go-fish"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	env := repl.NewSession(nil, nil, nil).Env
+	tests := map[string]bool{
+		"len(x)":                   true,
+		"int(x)":                   true,
+		"(*int)(p)":                true,
+		"(*strings.Builder)(p)":    true,
+		"strings.TrimSuffix(a, b)": true,
+		"f()":                      false,
+		"(*&f)()":                  false,
+		"(*p)()":                   false,
+		"(*&strings.Fields)(a)":    false,
+	}
+	for src, ok := range tests {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repl.CheckReadOnly(expr, env); (err == nil) != ok {
+			t.Errorf("%s: expecting allowed %v; got %v", src, ok, err)
+		}
+	}
+}
@@ -17,13 +17,12 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
-	"go/parser"
-	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/0xfaded/eval"
 )
@@ -109,10 +108,16 @@ func init() {
 // MakeEvalEnv creates an environment to use in evaluation.  The
 // environment is exactly that environment needed by eval
 // automatically extracted from the package eval
-// (http://github.com/0xfaded/eval).
+// (http://github.com/0xfaded/eval). Packages are entered under their
+// names or aliases; see ResolvePackageNames.
 func MakeEvalEnv() eval.Env {
-	var pkgs map[string] eval.Pkg = make(map[string] eval.Pkg)
-	EvalEnvironment(pkgs)
+	packagesOnce.Do(func() {
+		if len(Packages) == 0 {
+			EvalEnvironment(Packages)
+		}
+		_, PkgAliases, PkgConflicts = ResolvePackageNames(Packages, PkgAliases)
+	})
+	pkgs, _, _ := ResolvePackageNames(Packages, PkgAliases)
 
 	env := eval.Env {
 		Name:   ".",
@@ -125,6 +130,10 @@ func MakeEvalEnv() eval.Env {
 	return env
 }
 
+// packagesOnce loads Packages and records their aliases and name
+// conflicts the first time MakeEvalEnv is called.
+var packagesOnce sync.Once
+
 // LeaveREPL is set when we want to quit.
 var LeaveREPL bool = false
 
@@ -134,75 +143,12 @@ var ExitCode  int  = 0
 // Env is the evaluation environment we are working with.
 var Env *eval.Env
 
-// REPL is the read, eval, and print loop.
+// REPL is the read, eval, and print loop. See Session for running
+// more than one REPL at a time.
 func REPL(env *eval.Env, readLineFn ReadLineFnType, inspectFn InspectFnType) {
-
-	var err error
-
-	// A place to store result values of expressions entered
-	// interactively
-	results := make([] interface{}, 0, 10)
-	env.Vars["results"] = reflect.ValueOf(&results)
-
-	Env = env
-	exprs := 0
-	line, err := readLineFn("gofish> ", true)
-	for true {
-		if err != nil {
-			if err == io.EOF { break }
-			panic(err)
-		}
-		if wasProcessed(line) {
-			if LeaveREPL {break}
-			line, err = readLineFn("gofish> ", true)
-			continue
-		}
-		ctx := &eval.Ctx{line}
-		if expr, err := parser.ParseExpr(line); err != nil {
-			if pair := eval.FormatErrorPos(line, err.Error()); len(pair) == 2 {
-				Msg(pair[0])
-				Msg(pair[1])
-			}
-			Errmsg("parse error: %s", err)
-		} else if cexpr, errs := eval.CheckExpr(ctx, expr, env); len(errs) != 0 {
-			for _, cerr := range errs {
-				Errmsg("%v", cerr)
-			}
-		} else if vals, _, err := eval.EvalExpr(ctx, cexpr, env); err != nil {
-			Errmsg("eval error: %s", err)
-		} else if vals == nil {
-			Msg("Kind=nil\nnil")
-		} else if len(*vals) == 0 {
-			Msg("Kind=Slice\nvoid")
-		} else if len(*vals) == 1 {
-			value := (*vals)[0]
-			if value.IsValid() {
-				kind := value.Kind().String()
-				typ  := value.Type().String()
-				if typ != kind {
-					Msg("Kind = %v", kind)
-					Msg("Type = %v", typ)
-				} else {
-					Msg("Kind = Type = %v", kind)
-				}
-				Msg("results[%d] = %s", exprs, inspectFn(value))
-				exprs += 1
-				results = append(results, (*vals)[0].Interface())
-			} else {
-				Msg("%s", value)
-			}
-		} else {
-			Msg("Kind = Multi-Value")
-			size := len(*vals)
-			for i, v := range *vals {
-				fmt.Printf("%s", inspectFn(v))
-				if i < size-1 { fmt.Printf(", ") }
-			}
-			Msg("")
-			exprs += 1
-			results = append(results, (*vals))
-		}
-
-		line, err = readLineFn("gofish> ", true)
+	s := NewSession(env, readLineFn, inspectFn)
+	if err := s.Run(); err != nil {
+		panic(err)
 	}
+	ExitCode = s.ExitCode
 }
@@ -44,8 +44,8 @@ import (
 
 type pkgType map[string] eval.Pkg
 
-// EvalEnvironment adds to eval.Pkg those packages included
-// with import "github.com/rocky/go-fish".
+// EvalEnvironment adds to pkgs, keyed by import path, those packages
+// included with import "github.com/rocky/go-fish".
 
 func EvalEnvironment(pkgs pkgType) {
 	var consts map[string] reflect.Value
@@ -69,11 +69,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["ScanWords"] = reflect.ValueOf(bufio.ScanWords)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(bufio.Reader))
-	types["Writer"] = reflect.TypeOf(*new(bufio.Writer))
-	types["ReadWriter"] = reflect.TypeOf(*new(bufio.ReadWriter))
-	types["Scanner"] = reflect.TypeOf(*new(bufio.Scanner))
-	types["SplitFunc"] = reflect.TypeOf(*new(bufio.SplitFunc))
+	types["Reader"] = reflect.TypeOf(new(bufio.Reader)).Elem()
+	types["Writer"] = reflect.TypeOf(new(bufio.Writer)).Elem()
+	types["ReadWriter"] = reflect.TypeOf(new(bufio.ReadWriter)).Elem()
+	types["Scanner"] = reflect.TypeOf(new(bufio.Scanner)).Elem()
+	types["SplitFunc"] = reflect.TypeOf(new(bufio.SplitFunc)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrInvalidUnreadByte"] = reflect.ValueOf(&bufio.ErrInvalidUnreadByte)
@@ -143,8 +143,8 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewReader"] = reflect.ValueOf(bytes.NewReader)
 
 	types = make(map[string] reflect.Type)
-	types["Buffer"] = reflect.TypeOf(*new(bytes.Buffer))
-	types["Reader"] = reflect.TypeOf(*new(bytes.Reader))
+	types["Buffer"] = reflect.TypeOf(new(bytes.Buffer)).Elem()
+	types["Reader"] = reflect.TypeOf(new(bytes.Reader)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrTooLarge"] = reflect.ValueOf(&bytes.ErrTooLarge)
@@ -170,11 +170,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["ColumnizeS"] = reflect.ValueOf(columnize.ColumnizeS)
 
 	types = make(map[string] reflect.Type)
-	types["Opts_t"] = reflect.TypeOf(*new(columnize.Opts_t))
-	types["KeyValuePair_t"] = reflect.TypeOf(*new(columnize.KeyValuePair_t))
+	types["Opts_t"] = reflect.TypeOf(new(columnize.Opts_t)).Elem()
+	types["KeyValuePair_t"] = reflect.TypeOf(new(columnize.KeyValuePair_t)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["columnize"] = &eval.Env {
+	pkgs["code.google.com/p/go-columnize"] = &eval.Env {
 		Name: "columnize",
 		Consts: consts,
 		Funcs:  funcs,
@@ -200,12 +200,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["ReadVarint"] = reflect.ValueOf(binary.ReadVarint)
 
 	types = make(map[string] reflect.Type)
-	types["ByteOrder"] = reflect.TypeOf(*new(binary.ByteOrder))
+	types["ByteOrder"] = reflect.TypeOf(new(binary.ByteOrder)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["LittleEndian"] = reflect.ValueOf(&binary.LittleEndian)
 	vars["BigEndian"] = reflect.ValueOf(&binary.BigEndian)
-	pkgs["binary"] = &eval.Env {
+	pkgs["encoding/binary"] = &eval.Env {
 		Name: "binary",
 		Consts: consts,
 		Funcs:  funcs,
@@ -268,11 +268,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewFlagSet"] = reflect.ValueOf(flag.NewFlagSet)
 
 	types = make(map[string] reflect.Type)
-	types["Value"] = reflect.TypeOf(*new(flag.Value))
-	types["Getter"] = reflect.TypeOf(*new(flag.Getter))
-	types["ErrorHandling"] = reflect.TypeOf(*new(flag.ErrorHandling))
-	types["FlagSet"] = reflect.TypeOf(*new(flag.FlagSet))
-	types["Flag"] = reflect.TypeOf(*new(flag.Flag))
+	types["Value"] = reflect.TypeOf(new(flag.Value)).Elem()
+	types["Getter"] = reflect.TypeOf(new(flag.Getter)).Elem()
+	types["ErrorHandling"] = reflect.TypeOf(new(flag.ErrorHandling)).Elem()
+	types["FlagSet"] = reflect.TypeOf(new(flag.FlagSet)).Elem()
+	types["Flag"] = reflect.TypeOf(new(flag.Flag)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrHelp"] = reflect.ValueOf(&flag.ErrHelp)
@@ -311,12 +311,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Fscanf"] = reflect.ValueOf(fmt.Fscanf)
 
 	types = make(map[string] reflect.Type)
-	types["State"] = reflect.TypeOf(*new(fmt.State))
-	types["Formatter"] = reflect.TypeOf(*new(fmt.Formatter))
-	types["Stringer"] = reflect.TypeOf(*new(fmt.Stringer))
-	types["GoStringer"] = reflect.TypeOf(*new(fmt.GoStringer))
-	types["ScanState"] = reflect.TypeOf(*new(fmt.ScanState))
-	types["Scanner"] = reflect.TypeOf(*new(fmt.Scanner))
+	types["State"] = reflect.TypeOf(new(fmt.State)).Elem()
+	types["Formatter"] = reflect.TypeOf(new(fmt.Formatter)).Elem()
+	types["Stringer"] = reflect.TypeOf(new(fmt.Stringer)).Elem()
+	types["GoStringer"] = reflect.TypeOf(new(fmt.GoStringer)).Elem()
+	types["ScanState"] = reflect.TypeOf(new(fmt.ScanState)).Elem()
+	types["Scanner"] = reflect.TypeOf(new(fmt.Scanner)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["fmt"] = &eval.Env {
@@ -356,71 +356,71 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["FormatErrorPos"] = reflect.ValueOf(eval.FormatErrorPos)
 
 	types = make(map[string] reflect.Type)
-	types["Expr"] = reflect.TypeOf(*new(eval.Expr))
-	types["BadExpr"] = reflect.TypeOf(*new(eval.BadExpr))
-	types["Ident"] = reflect.TypeOf(*new(eval.Ident))
-	types["Ellipsis"] = reflect.TypeOf(*new(eval.Ellipsis))
-	types["BasicLit"] = reflect.TypeOf(*new(eval.BasicLit))
-	types["FuncLit"] = reflect.TypeOf(*new(eval.FuncLit))
-	types["CompositeLit"] = reflect.TypeOf(*new(eval.CompositeLit))
-	types["ParenExpr"] = reflect.TypeOf(*new(eval.ParenExpr))
-	types["SelectorExpr"] = reflect.TypeOf(*new(eval.SelectorExpr))
-	types["IndexExpr"] = reflect.TypeOf(*new(eval.IndexExpr))
-	types["SliceExpr"] = reflect.TypeOf(*new(eval.SliceExpr))
-	types["TypeAssertExpr"] = reflect.TypeOf(*new(eval.TypeAssertExpr))
-	types["CallExpr"] = reflect.TypeOf(*new(eval.CallExpr))
-	types["StarExpr"] = reflect.TypeOf(*new(eval.StarExpr))
-	types["UnaryExpr"] = reflect.TypeOf(*new(eval.UnaryExpr))
-	types["BinaryExpr"] = reflect.TypeOf(*new(eval.BinaryExpr))
-	types["KeyValueExpr"] = reflect.TypeOf(*new(eval.KeyValueExpr))
-	types["ArrayType"] = reflect.TypeOf(*new(eval.ArrayType))
-	types["StructType"] = reflect.TypeOf(*new(eval.StructType))
-	types["FuncType"] = reflect.TypeOf(*new(eval.FuncType))
-	types["InterfaceType"] = reflect.TypeOf(*new(eval.InterfaceType))
-	types["MapType"] = reflect.TypeOf(*new(eval.MapType))
-	types["ChanType"] = reflect.TypeOf(*new(eval.ChanType))
-	types["BigComplex"] = reflect.TypeOf(*new(eval.BigComplex))
-	types["ConstNumber"] = reflect.TypeOf(*new(eval.ConstNumber))
-	types["ConstType"] = reflect.TypeOf(*new(eval.ConstType))
-	types["ConstIntType"] = reflect.TypeOf(*new(eval.ConstIntType))
-	types["ConstRuneType"] = reflect.TypeOf(*new(eval.ConstRuneType))
-	types["ConstFloatType"] = reflect.TypeOf(*new(eval.ConstFloatType))
-	types["ConstComplexType"] = reflect.TypeOf(*new(eval.ConstComplexType))
-	types["ConstStringType"] = reflect.TypeOf(*new(eval.ConstStringType))
-	types["ConstNilType"] = reflect.TypeOf(*new(eval.ConstNilType))
-	types["ConstBoolType"] = reflect.TypeOf(*new(eval.ConstBoolType))
-	types["Ctx"] = reflect.TypeOf(*new(eval.Ctx))
-	types["Pkg"] = reflect.TypeOf(*new(eval.Pkg))
-	types["Env"] = reflect.TypeOf(*new(eval.Env))
-	types["ErrBadBasicLit"] = reflect.TypeOf(*new(eval.ErrBadBasicLit))
-	types["ErrInvalidOperand"] = reflect.TypeOf(*new(eval.ErrInvalidOperand))
-	types["ErrInvalidIndirect"] = reflect.TypeOf(*new(eval.ErrInvalidIndirect))
-	types["ErrMismatchedTypes"] = reflect.TypeOf(*new(eval.ErrMismatchedTypes))
-	types["ErrInvalidOperands"] = reflect.TypeOf(*new(eval.ErrInvalidOperands))
-	types["ErrBadFunArgument"] = reflect.TypeOf(*new(eval.ErrBadFunArgument))
-	types["ErrBadComplexArguments"] = reflect.TypeOf(*new(eval.ErrBadComplexArguments))
-	types["ErrBadBuiltinArgument"] = reflect.TypeOf(*new(eval.ErrBadBuiltinArgument))
-	types["ErrWrongNumberOfArgsOld"] = reflect.TypeOf(*new(eval.ErrWrongNumberOfArgsOld))
-	types["ErrWrongNumberOfArgs"] = reflect.TypeOf(*new(eval.ErrWrongNumberOfArgs))
-	types["ErrMissingValue"] = reflect.TypeOf(*new(eval.ErrMissingValue))
-	types["ErrMultiInSingleContext"] = reflect.TypeOf(*new(eval.ErrMultiInSingleContext))
-	types["ErrArrayIndexOutOfBounds"] = reflect.TypeOf(*new(eval.ErrArrayIndexOutOfBounds))
-	types["ErrInvalidIndexOperation"] = reflect.TypeOf(*new(eval.ErrInvalidIndexOperation))
-	types["ErrInvalidIndex"] = reflect.TypeOf(*new(eval.ErrInvalidIndex))
-	types["ErrDivideByZero"] = reflect.TypeOf(*new(eval.ErrDivideByZero))
-	types["ErrInvalidBinaryOperation"] = reflect.TypeOf(*new(eval.ErrInvalidBinaryOperation))
-	types["ErrInvalidUnaryOperation"] = reflect.TypeOf(*new(eval.ErrInvalidUnaryOperation))
-	types["ErrBadConversion"] = reflect.TypeOf(*new(eval.ErrBadConversion))
-	types["ErrBadConstConversion"] = reflect.TypeOf(*new(eval.ErrBadConstConversion))
-	types["ErrTruncatedConstant"] = reflect.TypeOf(*new(eval.ErrTruncatedConstant))
-	types["ErrOverflowedConstant"] = reflect.TypeOf(*new(eval.ErrOverflowedConstant))
-	types["ErrUntypedNil"] = reflect.TypeOf(*new(eval.ErrUntypedNil))
-	types["ErrorContext"] = reflect.TypeOf(*new(eval.ErrorContext))
-	types["EvalIdentExprFunc"] = reflect.TypeOf(*new(eval.EvalIdentExprFunc))
-	types["Rune"] = reflect.TypeOf(*new(eval.Rune))
-	types["EvalSelectorExprFunc"] = reflect.TypeOf(*new(eval.EvalSelectorExprFunc))
-	types["UntypedNil"] = reflect.TypeOf(*new(eval.UntypedNil))
-	types["UserConvertFunc"] = reflect.TypeOf(*new(eval.UserConvertFunc))
+	types["Expr"] = reflect.TypeOf(new(eval.Expr)).Elem()
+	types["BadExpr"] = reflect.TypeOf(new(eval.BadExpr)).Elem()
+	types["Ident"] = reflect.TypeOf(new(eval.Ident)).Elem()
+	types["Ellipsis"] = reflect.TypeOf(new(eval.Ellipsis)).Elem()
+	types["BasicLit"] = reflect.TypeOf(new(eval.BasicLit)).Elem()
+	types["FuncLit"] = reflect.TypeOf(new(eval.FuncLit)).Elem()
+	types["CompositeLit"] = reflect.TypeOf(new(eval.CompositeLit)).Elem()
+	types["ParenExpr"] = reflect.TypeOf(new(eval.ParenExpr)).Elem()
+	types["SelectorExpr"] = reflect.TypeOf(new(eval.SelectorExpr)).Elem()
+	types["IndexExpr"] = reflect.TypeOf(new(eval.IndexExpr)).Elem()
+	types["SliceExpr"] = reflect.TypeOf(new(eval.SliceExpr)).Elem()
+	types["TypeAssertExpr"] = reflect.TypeOf(new(eval.TypeAssertExpr)).Elem()
+	types["CallExpr"] = reflect.TypeOf(new(eval.CallExpr)).Elem()
+	types["StarExpr"] = reflect.TypeOf(new(eval.StarExpr)).Elem()
+	types["UnaryExpr"] = reflect.TypeOf(new(eval.UnaryExpr)).Elem()
+	types["BinaryExpr"] = reflect.TypeOf(new(eval.BinaryExpr)).Elem()
+	types["KeyValueExpr"] = reflect.TypeOf(new(eval.KeyValueExpr)).Elem()
+	types["ArrayType"] = reflect.TypeOf(new(eval.ArrayType)).Elem()
+	types["StructType"] = reflect.TypeOf(new(eval.StructType)).Elem()
+	types["FuncType"] = reflect.TypeOf(new(eval.FuncType)).Elem()
+	types["InterfaceType"] = reflect.TypeOf(new(eval.InterfaceType)).Elem()
+	types["MapType"] = reflect.TypeOf(new(eval.MapType)).Elem()
+	types["ChanType"] = reflect.TypeOf(new(eval.ChanType)).Elem()
+	types["BigComplex"] = reflect.TypeOf(new(eval.BigComplex)).Elem()
+	types["ConstNumber"] = reflect.TypeOf(new(eval.ConstNumber)).Elem()
+	types["ConstType"] = reflect.TypeOf(new(eval.ConstType)).Elem()
+	types["ConstIntType"] = reflect.TypeOf(new(eval.ConstIntType)).Elem()
+	types["ConstRuneType"] = reflect.TypeOf(new(eval.ConstRuneType)).Elem()
+	types["ConstFloatType"] = reflect.TypeOf(new(eval.ConstFloatType)).Elem()
+	types["ConstComplexType"] = reflect.TypeOf(new(eval.ConstComplexType)).Elem()
+	types["ConstStringType"] = reflect.TypeOf(new(eval.ConstStringType)).Elem()
+	types["ConstNilType"] = reflect.TypeOf(new(eval.ConstNilType)).Elem()
+	types["ConstBoolType"] = reflect.TypeOf(new(eval.ConstBoolType)).Elem()
+	types["Ctx"] = reflect.TypeOf(new(eval.Ctx)).Elem()
+	types["Pkg"] = reflect.TypeOf(new(eval.Pkg)).Elem()
+	types["Env"] = reflect.TypeOf(new(eval.Env)).Elem()
+	types["ErrBadBasicLit"] = reflect.TypeOf(new(eval.ErrBadBasicLit)).Elem()
+	types["ErrInvalidOperand"] = reflect.TypeOf(new(eval.ErrInvalidOperand)).Elem()
+	types["ErrInvalidIndirect"] = reflect.TypeOf(new(eval.ErrInvalidIndirect)).Elem()
+	types["ErrMismatchedTypes"] = reflect.TypeOf(new(eval.ErrMismatchedTypes)).Elem()
+	types["ErrInvalidOperands"] = reflect.TypeOf(new(eval.ErrInvalidOperands)).Elem()
+	types["ErrBadFunArgument"] = reflect.TypeOf(new(eval.ErrBadFunArgument)).Elem()
+	types["ErrBadComplexArguments"] = reflect.TypeOf(new(eval.ErrBadComplexArguments)).Elem()
+	types["ErrBadBuiltinArgument"] = reflect.TypeOf(new(eval.ErrBadBuiltinArgument)).Elem()
+	types["ErrWrongNumberOfArgsOld"] = reflect.TypeOf(new(eval.ErrWrongNumberOfArgsOld)).Elem()
+	types["ErrWrongNumberOfArgs"] = reflect.TypeOf(new(eval.ErrWrongNumberOfArgs)).Elem()
+	types["ErrMissingValue"] = reflect.TypeOf(new(eval.ErrMissingValue)).Elem()
+	types["ErrMultiInSingleContext"] = reflect.TypeOf(new(eval.ErrMultiInSingleContext)).Elem()
+	types["ErrArrayIndexOutOfBounds"] = reflect.TypeOf(new(eval.ErrArrayIndexOutOfBounds)).Elem()
+	types["ErrInvalidIndexOperation"] = reflect.TypeOf(new(eval.ErrInvalidIndexOperation)).Elem()
+	types["ErrInvalidIndex"] = reflect.TypeOf(new(eval.ErrInvalidIndex)).Elem()
+	types["ErrDivideByZero"] = reflect.TypeOf(new(eval.ErrDivideByZero)).Elem()
+	types["ErrInvalidBinaryOperation"] = reflect.TypeOf(new(eval.ErrInvalidBinaryOperation)).Elem()
+	types["ErrInvalidUnaryOperation"] = reflect.TypeOf(new(eval.ErrInvalidUnaryOperation)).Elem()
+	types["ErrBadConversion"] = reflect.TypeOf(new(eval.ErrBadConversion)).Elem()
+	types["ErrBadConstConversion"] = reflect.TypeOf(new(eval.ErrBadConstConversion)).Elem()
+	types["ErrTruncatedConstant"] = reflect.TypeOf(new(eval.ErrTruncatedConstant)).Elem()
+	types["ErrOverflowedConstant"] = reflect.TypeOf(new(eval.ErrOverflowedConstant)).Elem()
+	types["ErrUntypedNil"] = reflect.TypeOf(new(eval.ErrUntypedNil)).Elem()
+	types["ErrorContext"] = reflect.TypeOf(new(eval.ErrorContext)).Elem()
+	types["EvalIdentExprFunc"] = reflect.TypeOf(new(eval.EvalIdentExprFunc)).Elem()
+	types["Rune"] = reflect.TypeOf(new(eval.Rune)).Elem()
+	types["EvalSelectorExprFunc"] = reflect.TypeOf(new(eval.EvalSelectorExprFunc)).Elem()
+	types["UntypedNil"] = reflect.TypeOf(new(eval.UntypedNil)).Elem()
+	types["UserConvertFunc"] = reflect.TypeOf(new(eval.UserConvertFunc)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ConstInt"] = reflect.ValueOf(&eval.ConstInt)
@@ -432,7 +432,7 @@ func EvalEnvironment(pkgs pkgType) {
 	vars["ConstBool"] = reflect.ValueOf(&eval.ConstBool)
 	vars["ErrArrayKey"] = reflect.ValueOf(&eval.ErrArrayKey)
 	vars["RuneType"] = reflect.ValueOf(&eval.RuneType)
-	pkgs["eval"] = &eval.Env {
+	pkgs["github.com/0xfaded/eval"] = &eval.Env {
 		Name: "eval",
 		Consts: consts,
 		Funcs:  funcs,
@@ -453,7 +453,7 @@ func EvalEnvironment(pkgs pkgType) {
 	types = make(map[string] reflect.Type)
 
 	vars = make(map[string] reflect.Value)
-	pkgs["ansi"] = &eval.Env {
+	pkgs["github.com/mgutz/ansi"] = &eval.Env {
 		Name: "ansi",
 		Consts: consts,
 		Funcs:  funcs,
@@ -484,11 +484,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["GetUInt"] = reflect.ValueOf(GetUInt)
 
 	types = make(map[string] reflect.Type)
-	types["CmdFunc"] = reflect.TypeOf(*new(CmdFunc))
-	types["CmdInfo"] = reflect.TypeOf(*new(CmdInfo))
-	types["ReadLineFnType"] = reflect.TypeOf(*new(ReadLineFnType))
-	types["InspectFnType"] = reflect.TypeOf(*new(InspectFnType))
-	types["NumError"] = reflect.TypeOf(*new(NumError))
+	types["CmdFunc"] = reflect.TypeOf(new(CmdFunc)).Elem()
+	types["CmdInfo"] = reflect.TypeOf(new(CmdInfo)).Elem()
+	types["ReadLineFnType"] = reflect.TypeOf(new(ReadLineFnType)).Elem()
+	types["InspectFnType"] = reflect.TypeOf(new(InspectFnType)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["Cmds"] = reflect.ValueOf(&Cmds)
@@ -502,7 +501,7 @@ func EvalEnvironment(pkgs pkgType) {
 	vars["LeaveREPL"] = reflect.ValueOf(&LeaveREPL)
 	vars["ExitCode"] = reflect.ValueOf(&ExitCode)
 	vars["Env"] = reflect.ValueOf(&Env)
-	pkgs["repl"] = &eval.Env {
+	pkgs["github.com/rocky/go-fish"] = &eval.Env {
 		Name: "repl",
 		Consts: consts,
 		Funcs:  funcs,
@@ -546,79 +545,79 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Inspect"] = reflect.ValueOf(ast.Inspect)
 
 	types = make(map[string] reflect.Type)
-	types["Node"] = reflect.TypeOf(*new(ast.Node))
-	types["Expr"] = reflect.TypeOf(*new(ast.Expr))
-	types["Stmt"] = reflect.TypeOf(*new(ast.Stmt))
-	types["Decl"] = reflect.TypeOf(*new(ast.Decl))
-	types["Comment"] = reflect.TypeOf(*new(ast.Comment))
-	types["CommentGroup"] = reflect.TypeOf(*new(ast.CommentGroup))
-	types["Field"] = reflect.TypeOf(*new(ast.Field))
-	types["FieldList"] = reflect.TypeOf(*new(ast.FieldList))
-	types["BadExpr"] = reflect.TypeOf(*new(ast.BadExpr))
-	types["Ident"] = reflect.TypeOf(*new(ast.Ident))
-	types["Ellipsis"] = reflect.TypeOf(*new(ast.Ellipsis))
-	types["BasicLit"] = reflect.TypeOf(*new(ast.BasicLit))
-	types["FuncLit"] = reflect.TypeOf(*new(ast.FuncLit))
-	types["CompositeLit"] = reflect.TypeOf(*new(ast.CompositeLit))
-	types["ParenExpr"] = reflect.TypeOf(*new(ast.ParenExpr))
-	types["SelectorExpr"] = reflect.TypeOf(*new(ast.SelectorExpr))
-	types["IndexExpr"] = reflect.TypeOf(*new(ast.IndexExpr))
-	types["SliceExpr"] = reflect.TypeOf(*new(ast.SliceExpr))
-	types["TypeAssertExpr"] = reflect.TypeOf(*new(ast.TypeAssertExpr))
-	types["CallExpr"] = reflect.TypeOf(*new(ast.CallExpr))
-	types["StarExpr"] = reflect.TypeOf(*new(ast.StarExpr))
-	types["UnaryExpr"] = reflect.TypeOf(*new(ast.UnaryExpr))
-	types["BinaryExpr"] = reflect.TypeOf(*new(ast.BinaryExpr))
-	types["KeyValueExpr"] = reflect.TypeOf(*new(ast.KeyValueExpr))
-	types["ChanDir"] = reflect.TypeOf(*new(ast.ChanDir))
-	types["ArrayType"] = reflect.TypeOf(*new(ast.ArrayType))
-	types["StructType"] = reflect.TypeOf(*new(ast.StructType))
-	types["FuncType"] = reflect.TypeOf(*new(ast.FuncType))
-	types["InterfaceType"] = reflect.TypeOf(*new(ast.InterfaceType))
-	types["MapType"] = reflect.TypeOf(*new(ast.MapType))
-	types["ChanType"] = reflect.TypeOf(*new(ast.ChanType))
-	types["BadStmt"] = reflect.TypeOf(*new(ast.BadStmt))
-	types["DeclStmt"] = reflect.TypeOf(*new(ast.DeclStmt))
-	types["EmptyStmt"] = reflect.TypeOf(*new(ast.EmptyStmt))
-	types["LabeledStmt"] = reflect.TypeOf(*new(ast.LabeledStmt))
-	types["ExprStmt"] = reflect.TypeOf(*new(ast.ExprStmt))
-	types["SendStmt"] = reflect.TypeOf(*new(ast.SendStmt))
-	types["IncDecStmt"] = reflect.TypeOf(*new(ast.IncDecStmt))
-	types["AssignStmt"] = reflect.TypeOf(*new(ast.AssignStmt))
-	types["GoStmt"] = reflect.TypeOf(*new(ast.GoStmt))
-	types["DeferStmt"] = reflect.TypeOf(*new(ast.DeferStmt))
-	types["ReturnStmt"] = reflect.TypeOf(*new(ast.ReturnStmt))
-	types["BranchStmt"] = reflect.TypeOf(*new(ast.BranchStmt))
-	types["BlockStmt"] = reflect.TypeOf(*new(ast.BlockStmt))
-	types["IfStmt"] = reflect.TypeOf(*new(ast.IfStmt))
-	types["CaseClause"] = reflect.TypeOf(*new(ast.CaseClause))
-	types["SwitchStmt"] = reflect.TypeOf(*new(ast.SwitchStmt))
-	types["TypeSwitchStmt"] = reflect.TypeOf(*new(ast.TypeSwitchStmt))
-	types["CommClause"] = reflect.TypeOf(*new(ast.CommClause))
-	types["SelectStmt"] = reflect.TypeOf(*new(ast.SelectStmt))
-	types["ForStmt"] = reflect.TypeOf(*new(ast.ForStmt))
-	types["RangeStmt"] = reflect.TypeOf(*new(ast.RangeStmt))
-	types["Spec"] = reflect.TypeOf(*new(ast.Spec))
-	types["ImportSpec"] = reflect.TypeOf(*new(ast.ImportSpec))
-	types["ValueSpec"] = reflect.TypeOf(*new(ast.ValueSpec))
-	types["TypeSpec"] = reflect.TypeOf(*new(ast.TypeSpec))
-	types["BadDecl"] = reflect.TypeOf(*new(ast.BadDecl))
-	types["GenDecl"] = reflect.TypeOf(*new(ast.GenDecl))
-	types["FuncDecl"] = reflect.TypeOf(*new(ast.FuncDecl))
-	types["File"] = reflect.TypeOf(*new(ast.File))
-	types["Package"] = reflect.TypeOf(*new(ast.Package))
-	types["CommentMap"] = reflect.TypeOf(*new(ast.CommentMap))
-	types["Filter"] = reflect.TypeOf(*new(ast.Filter))
-	types["MergeMode"] = reflect.TypeOf(*new(ast.MergeMode))
-	types["FieldFilter"] = reflect.TypeOf(*new(ast.FieldFilter))
-	types["Importer"] = reflect.TypeOf(*new(ast.Importer))
-	types["Scope"] = reflect.TypeOf(*new(ast.Scope))
-	types["Object"] = reflect.TypeOf(*new(ast.Object))
-	types["ObjKind"] = reflect.TypeOf(*new(ast.ObjKind))
-	types["Visitor"] = reflect.TypeOf(*new(ast.Visitor))
+	types["Node"] = reflect.TypeOf(new(ast.Node)).Elem()
+	types["Expr"] = reflect.TypeOf(new(ast.Expr)).Elem()
+	types["Stmt"] = reflect.TypeOf(new(ast.Stmt)).Elem()
+	types["Decl"] = reflect.TypeOf(new(ast.Decl)).Elem()
+	types["Comment"] = reflect.TypeOf(new(ast.Comment)).Elem()
+	types["CommentGroup"] = reflect.TypeOf(new(ast.CommentGroup)).Elem()
+	types["Field"] = reflect.TypeOf(new(ast.Field)).Elem()
+	types["FieldList"] = reflect.TypeOf(new(ast.FieldList)).Elem()
+	types["BadExpr"] = reflect.TypeOf(new(ast.BadExpr)).Elem()
+	types["Ident"] = reflect.TypeOf(new(ast.Ident)).Elem()
+	types["Ellipsis"] = reflect.TypeOf(new(ast.Ellipsis)).Elem()
+	types["BasicLit"] = reflect.TypeOf(new(ast.BasicLit)).Elem()
+	types["FuncLit"] = reflect.TypeOf(new(ast.FuncLit)).Elem()
+	types["CompositeLit"] = reflect.TypeOf(new(ast.CompositeLit)).Elem()
+	types["ParenExpr"] = reflect.TypeOf(new(ast.ParenExpr)).Elem()
+	types["SelectorExpr"] = reflect.TypeOf(new(ast.SelectorExpr)).Elem()
+	types["IndexExpr"] = reflect.TypeOf(new(ast.IndexExpr)).Elem()
+	types["SliceExpr"] = reflect.TypeOf(new(ast.SliceExpr)).Elem()
+	types["TypeAssertExpr"] = reflect.TypeOf(new(ast.TypeAssertExpr)).Elem()
+	types["CallExpr"] = reflect.TypeOf(new(ast.CallExpr)).Elem()
+	types["StarExpr"] = reflect.TypeOf(new(ast.StarExpr)).Elem()
+	types["UnaryExpr"] = reflect.TypeOf(new(ast.UnaryExpr)).Elem()
+	types["BinaryExpr"] = reflect.TypeOf(new(ast.BinaryExpr)).Elem()
+	types["KeyValueExpr"] = reflect.TypeOf(new(ast.KeyValueExpr)).Elem()
+	types["ChanDir"] = reflect.TypeOf(new(ast.ChanDir)).Elem()
+	types["ArrayType"] = reflect.TypeOf(new(ast.ArrayType)).Elem()
+	types["StructType"] = reflect.TypeOf(new(ast.StructType)).Elem()
+	types["FuncType"] = reflect.TypeOf(new(ast.FuncType)).Elem()
+	types["InterfaceType"] = reflect.TypeOf(new(ast.InterfaceType)).Elem()
+	types["MapType"] = reflect.TypeOf(new(ast.MapType)).Elem()
+	types["ChanType"] = reflect.TypeOf(new(ast.ChanType)).Elem()
+	types["BadStmt"] = reflect.TypeOf(new(ast.BadStmt)).Elem()
+	types["DeclStmt"] = reflect.TypeOf(new(ast.DeclStmt)).Elem()
+	types["EmptyStmt"] = reflect.TypeOf(new(ast.EmptyStmt)).Elem()
+	types["LabeledStmt"] = reflect.TypeOf(new(ast.LabeledStmt)).Elem()
+	types["ExprStmt"] = reflect.TypeOf(new(ast.ExprStmt)).Elem()
+	types["SendStmt"] = reflect.TypeOf(new(ast.SendStmt)).Elem()
+	types["IncDecStmt"] = reflect.TypeOf(new(ast.IncDecStmt)).Elem()
+	types["AssignStmt"] = reflect.TypeOf(new(ast.AssignStmt)).Elem()
+	types["GoStmt"] = reflect.TypeOf(new(ast.GoStmt)).Elem()
+	types["DeferStmt"] = reflect.TypeOf(new(ast.DeferStmt)).Elem()
+	types["ReturnStmt"] = reflect.TypeOf(new(ast.ReturnStmt)).Elem()
+	types["BranchStmt"] = reflect.TypeOf(new(ast.BranchStmt)).Elem()
+	types["BlockStmt"] = reflect.TypeOf(new(ast.BlockStmt)).Elem()
+	types["IfStmt"] = reflect.TypeOf(new(ast.IfStmt)).Elem()
+	types["CaseClause"] = reflect.TypeOf(new(ast.CaseClause)).Elem()
+	types["SwitchStmt"] = reflect.TypeOf(new(ast.SwitchStmt)).Elem()
+	types["TypeSwitchStmt"] = reflect.TypeOf(new(ast.TypeSwitchStmt)).Elem()
+	types["CommClause"] = reflect.TypeOf(new(ast.CommClause)).Elem()
+	types["SelectStmt"] = reflect.TypeOf(new(ast.SelectStmt)).Elem()
+	types["ForStmt"] = reflect.TypeOf(new(ast.ForStmt)).Elem()
+	types["RangeStmt"] = reflect.TypeOf(new(ast.RangeStmt)).Elem()
+	types["Spec"] = reflect.TypeOf(new(ast.Spec)).Elem()
+	types["ImportSpec"] = reflect.TypeOf(new(ast.ImportSpec)).Elem()
+	types["ValueSpec"] = reflect.TypeOf(new(ast.ValueSpec)).Elem()
+	types["TypeSpec"] = reflect.TypeOf(new(ast.TypeSpec)).Elem()
+	types["BadDecl"] = reflect.TypeOf(new(ast.BadDecl)).Elem()
+	types["GenDecl"] = reflect.TypeOf(new(ast.GenDecl)).Elem()
+	types["FuncDecl"] = reflect.TypeOf(new(ast.FuncDecl)).Elem()
+	types["File"] = reflect.TypeOf(new(ast.File)).Elem()
+	types["Package"] = reflect.TypeOf(new(ast.Package)).Elem()
+	types["CommentMap"] = reflect.TypeOf(new(ast.CommentMap)).Elem()
+	types["Filter"] = reflect.TypeOf(new(ast.Filter)).Elem()
+	types["MergeMode"] = reflect.TypeOf(new(ast.MergeMode)).Elem()
+	types["FieldFilter"] = reflect.TypeOf(new(ast.FieldFilter)).Elem()
+	types["Importer"] = reflect.TypeOf(new(ast.Importer)).Elem()
+	types["Scope"] = reflect.TypeOf(new(ast.Scope)).Elem()
+	types["Object"] = reflect.TypeOf(new(ast.Object)).Elem()
+	types["ObjKind"] = reflect.TypeOf(new(ast.ObjKind)).Elem()
+	types["Visitor"] = reflect.TypeOf(new(ast.Visitor)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["ast"] = &eval.Env {
+	pkgs["go/ast"] = &eval.Env {
 		Name: "ast",
 		Consts: consts,
 		Funcs:  funcs,
@@ -642,10 +641,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["ParseExpr"] = reflect.ValueOf(parser.ParseExpr)
 
 	types = make(map[string] reflect.Type)
-	types["Mode"] = reflect.TypeOf(*new(parser.Mode))
+	types["Mode"] = reflect.TypeOf(new(parser.Mode)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["parser"] = &eval.Env {
+	pkgs["go/parser"] = &eval.Env {
 		Name: "parser",
 		Consts: consts,
 		Funcs:  funcs,
@@ -661,14 +660,14 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["PrintError"] = reflect.ValueOf(scanner.PrintError)
 
 	types = make(map[string] reflect.Type)
-	types["Error"] = reflect.TypeOf(*new(scanner.Error))
-	types["ErrorList"] = reflect.TypeOf(*new(scanner.ErrorList))
-	types["ErrorHandler"] = reflect.TypeOf(*new(scanner.ErrorHandler))
-	types["Scanner"] = reflect.TypeOf(*new(scanner.Scanner))
-	types["Mode"] = reflect.TypeOf(*new(scanner.Mode))
+	types["Error"] = reflect.TypeOf(new(scanner.Error)).Elem()
+	types["ErrorList"] = reflect.TypeOf(new(scanner.ErrorList)).Elem()
+	types["ErrorHandler"] = reflect.TypeOf(new(scanner.ErrorHandler)).Elem()
+	types["Scanner"] = reflect.TypeOf(new(scanner.Scanner)).Elem()
+	types["Mode"] = reflect.TypeOf(new(scanner.Mode)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["scanner"] = &eval.Env {
+	pkgs["go/scanner"] = &eval.Env {
 		Name: "scanner",
 		Consts: consts,
 		Funcs:  funcs,
@@ -769,14 +768,14 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Lookup"] = reflect.ValueOf(token.Lookup)
 
 	types = make(map[string] reflect.Type)
-	types["Position"] = reflect.TypeOf(*new(token.Position))
-	types["Pos"] = reflect.TypeOf(*new(token.Pos))
-	types["File"] = reflect.TypeOf(*new(token.File))
-	types["FileSet"] = reflect.TypeOf(*new(token.FileSet))
-	types["Token"] = reflect.TypeOf(*new(token.Token))
+	types["Position"] = reflect.TypeOf(new(token.Position)).Elem()
+	types["Pos"] = reflect.TypeOf(new(token.Pos)).Elem()
+	types["File"] = reflect.TypeOf(new(token.File)).Elem()
+	types["FileSet"] = reflect.TypeOf(new(token.FileSet)).Elem()
+	types["Token"] = reflect.TypeOf(new(token.Token)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["token"] = &eval.Env {
+	pkgs["go/token"] = &eval.Env {
 		Name: "token",
 		Consts: consts,
 		Funcs:  funcs,
@@ -801,30 +800,30 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Pipe"] = reflect.ValueOf(io.Pipe)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(io.Reader))
-	types["Writer"] = reflect.TypeOf(*new(io.Writer))
-	types["Closer"] = reflect.TypeOf(*new(io.Closer))
-	types["Seeker"] = reflect.TypeOf(*new(io.Seeker))
-	types["ReadWriter"] = reflect.TypeOf(*new(io.ReadWriter))
-	types["ReadCloser"] = reflect.TypeOf(*new(io.ReadCloser))
-	types["WriteCloser"] = reflect.TypeOf(*new(io.WriteCloser))
-	types["ReadWriteCloser"] = reflect.TypeOf(*new(io.ReadWriteCloser))
-	types["ReadSeeker"] = reflect.TypeOf(*new(io.ReadSeeker))
-	types["WriteSeeker"] = reflect.TypeOf(*new(io.WriteSeeker))
-	types["ReadWriteSeeker"] = reflect.TypeOf(*new(io.ReadWriteSeeker))
-	types["ReaderFrom"] = reflect.TypeOf(*new(io.ReaderFrom))
-	types["WriterTo"] = reflect.TypeOf(*new(io.WriterTo))
-	types["ReaderAt"] = reflect.TypeOf(*new(io.ReaderAt))
-	types["WriterAt"] = reflect.TypeOf(*new(io.WriterAt))
-	types["ByteReader"] = reflect.TypeOf(*new(io.ByteReader))
-	types["ByteScanner"] = reflect.TypeOf(*new(io.ByteScanner))
-	types["ByteWriter"] = reflect.TypeOf(*new(io.ByteWriter))
-	types["RuneReader"] = reflect.TypeOf(*new(io.RuneReader))
-	types["RuneScanner"] = reflect.TypeOf(*new(io.RuneScanner))
-	types["LimitedReader"] = reflect.TypeOf(*new(io.LimitedReader))
-	types["SectionReader"] = reflect.TypeOf(*new(io.SectionReader))
-	types["PipeReader"] = reflect.TypeOf(*new(io.PipeReader))
-	types["PipeWriter"] = reflect.TypeOf(*new(io.PipeWriter))
+	types["Reader"] = reflect.TypeOf(new(io.Reader)).Elem()
+	types["Writer"] = reflect.TypeOf(new(io.Writer)).Elem()
+	types["Closer"] = reflect.TypeOf(new(io.Closer)).Elem()
+	types["Seeker"] = reflect.TypeOf(new(io.Seeker)).Elem()
+	types["ReadWriter"] = reflect.TypeOf(new(io.ReadWriter)).Elem()
+	types["ReadCloser"] = reflect.TypeOf(new(io.ReadCloser)).Elem()
+	types["WriteCloser"] = reflect.TypeOf(new(io.WriteCloser)).Elem()
+	types["ReadWriteCloser"] = reflect.TypeOf(new(io.ReadWriteCloser)).Elem()
+	types["ReadSeeker"] = reflect.TypeOf(new(io.ReadSeeker)).Elem()
+	types["WriteSeeker"] = reflect.TypeOf(new(io.WriteSeeker)).Elem()
+	types["ReadWriteSeeker"] = reflect.TypeOf(new(io.ReadWriteSeeker)).Elem()
+	types["ReaderFrom"] = reflect.TypeOf(new(io.ReaderFrom)).Elem()
+	types["WriterTo"] = reflect.TypeOf(new(io.WriterTo)).Elem()
+	types["ReaderAt"] = reflect.TypeOf(new(io.ReaderAt)).Elem()
+	types["WriterAt"] = reflect.TypeOf(new(io.WriterAt)).Elem()
+	types["ByteReader"] = reflect.TypeOf(new(io.ByteReader)).Elem()
+	types["ByteScanner"] = reflect.TypeOf(new(io.ByteScanner)).Elem()
+	types["ByteWriter"] = reflect.TypeOf(new(io.ByteWriter)).Elem()
+	types["RuneReader"] = reflect.TypeOf(new(io.RuneReader)).Elem()
+	types["RuneScanner"] = reflect.TypeOf(new(io.RuneScanner)).Elem()
+	types["LimitedReader"] = reflect.TypeOf(new(io.LimitedReader)).Elem()
+	types["SectionReader"] = reflect.TypeOf(new(io.SectionReader)).Elem()
+	types["PipeReader"] = reflect.TypeOf(new(io.PipeReader)).Elem()
+	types["PipeWriter"] = reflect.TypeOf(new(io.PipeWriter)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrShortWrite"] = reflect.ValueOf(&io.ErrShortWrite)
@@ -857,7 +856,7 @@ func EvalEnvironment(pkgs pkgType) {
 
 	vars = make(map[string] reflect.Value)
 	vars["Discard"] = reflect.ValueOf(&ioutil.Discard)
-	pkgs["ioutil"] = &eval.Env {
+	pkgs["io/ioutil"] = &eval.Env {
 		Name: "ioutil",
 		Consts: consts,
 		Funcs:  funcs,
@@ -892,7 +891,7 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Panicln"] = reflect.ValueOf(log.Panicln)
 
 	types = make(map[string] reflect.Type)
-	types["Logger"] = reflect.TypeOf(*new(log.Logger))
+	types["Logger"] = reflect.TypeOf(new(log.Logger)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["log"] = &eval.Env {
@@ -1016,12 +1015,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewRat"] = reflect.ValueOf(big.NewRat)
 
 	types = make(map[string] reflect.Type)
-	types["Word"] = reflect.TypeOf(*new(big.Word))
-	types["Int"] = reflect.TypeOf(*new(big.Int))
-	types["Rat"] = reflect.TypeOf(*new(big.Rat))
+	types["Word"] = reflect.TypeOf(new(big.Word)).Elem()
+	types["Int"] = reflect.TypeOf(new(big.Int)).Elem()
+	types["Rat"] = reflect.TypeOf(new(big.Rat)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["big"] = &eval.Env {
+	pkgs["math/big"] = &eval.Env {
 		Name: "big",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1051,12 +1050,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewZipf"] = reflect.ValueOf(rand.NewZipf)
 
 	types = make(map[string] reflect.Type)
-	types["Source"] = reflect.TypeOf(*new(rand.Source))
-	types["Rand"] = reflect.TypeOf(*new(rand.Rand))
-	types["Zipf"] = reflect.TypeOf(*new(rand.Zipf))
+	types["Source"] = reflect.TypeOf(new(rand.Source)).Elem()
+	types["Rand"] = reflect.TypeOf(new(rand.Rand)).Elem()
+	types["Zipf"] = reflect.TypeOf(new(rand.Zipf)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["rand"] = &eval.Env {
+	pkgs["math/rand"] = &eval.Env {
 		Name: "rand",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1145,16 +1144,16 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["SameFile"] = reflect.ValueOf(os.SameFile)
 
 	types = make(map[string] reflect.Type)
-	types["PathError"] = reflect.TypeOf(*new(os.PathError))
-	types["SyscallError"] = reflect.TypeOf(*new(os.SyscallError))
-	types["Process"] = reflect.TypeOf(*new(os.Process))
-	types["ProcAttr"] = reflect.TypeOf(*new(os.ProcAttr))
-	types["Signal"] = reflect.TypeOf(*new(os.Signal))
-	types["ProcessState"] = reflect.TypeOf(*new(os.ProcessState))
-	types["LinkError"] = reflect.TypeOf(*new(os.LinkError))
-	types["File"] = reflect.TypeOf(*new(os.File))
-	types["FileInfo"] = reflect.TypeOf(*new(os.FileInfo))
-	types["FileMode"] = reflect.TypeOf(*new(os.FileMode))
+	types["PathError"] = reflect.TypeOf(new(os.PathError)).Elem()
+	types["SyscallError"] = reflect.TypeOf(new(os.SyscallError)).Elem()
+	types["Process"] = reflect.TypeOf(new(os.Process)).Elem()
+	types["ProcAttr"] = reflect.TypeOf(new(os.ProcAttr)).Elem()
+	types["Signal"] = reflect.TypeOf(new(os.Signal)).Elem()
+	types["ProcessState"] = reflect.TypeOf(new(os.ProcessState)).Elem()
+	types["LinkError"] = reflect.TypeOf(new(os.LinkError)).Elem()
+	types["File"] = reflect.TypeOf(new(os.File)).Elem()
+	types["FileInfo"] = reflect.TypeOf(new(os.FileInfo)).Elem()
+	types["FileMode"] = reflect.TypeOf(new(os.FileMode)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrInvalid"] = reflect.ValueOf(&os.ErrInvalid)
@@ -1183,13 +1182,13 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["LookPath"] = reflect.ValueOf(exec.LookPath)
 
 	types = make(map[string] reflect.Type)
-	types["Error"] = reflect.TypeOf(*new(exec.Error))
-	types["Cmd"] = reflect.TypeOf(*new(exec.Cmd))
-	types["ExitError"] = reflect.TypeOf(*new(exec.ExitError))
+	types["Error"] = reflect.TypeOf(new(exec.Error)).Elem()
+	types["Cmd"] = reflect.TypeOf(new(exec.Cmd)).Elem()
+	types["ExitError"] = reflect.TypeOf(new(exec.ExitError)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrNotFound"] = reflect.ValueOf(&exec.ErrNotFound)
-	pkgs["exec"] = &eval.Env {
+	pkgs["os/exec"] = &eval.Env {
 		Name: "exec",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1223,12 +1222,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["HasPrefix"] = reflect.ValueOf(filepath.HasPrefix)
 
 	types = make(map[string] reflect.Type)
-	types["WalkFunc"] = reflect.TypeOf(*new(filepath.WalkFunc))
+	types["WalkFunc"] = reflect.TypeOf(new(filepath.WalkFunc)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrBadPattern"] = reflect.ValueOf(&filepath.ErrBadPattern)
 	vars["SkipDir"] = reflect.ValueOf(&filepath.SkipDir)
-	pkgs["filepath"] = &eval.Env {
+	pkgs["path/filepath"] = &eval.Env {
 		Name: "filepath",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1294,18 +1293,18 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewAt"] = reflect.ValueOf(reflect.NewAt)
 
 	types = make(map[string] reflect.Type)
-	types["Type"] = reflect.TypeOf(*new(reflect.Type))
-	types["Kind"] = reflect.TypeOf(*new(reflect.Kind))
-	types["ChanDir"] = reflect.TypeOf(*new(reflect.ChanDir))
-	types["Method"] = reflect.TypeOf(*new(reflect.Method))
-	types["StructField"] = reflect.TypeOf(*new(reflect.StructField))
-	types["StructTag"] = reflect.TypeOf(*new(reflect.StructTag))
-	types["Value"] = reflect.TypeOf(*new(reflect.Value))
-	types["ValueError"] = reflect.TypeOf(*new(reflect.ValueError))
-	types["StringHeader"] = reflect.TypeOf(*new(reflect.StringHeader))
-	types["SliceHeader"] = reflect.TypeOf(*new(reflect.SliceHeader))
-	types["SelectDir"] = reflect.TypeOf(*new(reflect.SelectDir))
-	types["SelectCase"] = reflect.TypeOf(*new(reflect.SelectCase))
+	types["Type"] = reflect.TypeOf(new(reflect.Type)).Elem()
+	types["Kind"] = reflect.TypeOf(new(reflect.Kind)).Elem()
+	types["ChanDir"] = reflect.TypeOf(new(reflect.ChanDir)).Elem()
+	types["Method"] = reflect.TypeOf(new(reflect.Method)).Elem()
+	types["StructField"] = reflect.TypeOf(new(reflect.StructField)).Elem()
+	types["StructTag"] = reflect.TypeOf(new(reflect.StructTag)).Elem()
+	types["Value"] = reflect.TypeOf(new(reflect.Value)).Elem()
+	types["ValueError"] = reflect.TypeOf(new(reflect.ValueError)).Elem()
+	types["StringHeader"] = reflect.TypeOf(new(reflect.StringHeader)).Elem()
+	types["SliceHeader"] = reflect.TypeOf(new(reflect.SliceHeader)).Elem()
+	types["SelectDir"] = reflect.TypeOf(new(reflect.SelectDir)).Elem()
+	types["SelectCase"] = reflect.TypeOf(new(reflect.SelectCase)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["reflect"] = &eval.Env {
@@ -1330,7 +1329,7 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["QuoteMeta"] = reflect.ValueOf(regexp.QuoteMeta)
 
 	types = make(map[string] reflect.Type)
-	types["Regexp"] = reflect.TypeOf(*new(regexp.Regexp))
+	types["Regexp"] = reflect.TypeOf(new(regexp.Regexp)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["regexp"] = &eval.Env {
@@ -1414,18 +1413,18 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["IsWordChar"] = reflect.ValueOf(syntax.IsWordChar)
 
 	types = make(map[string] reflect.Type)
-	types["Error"] = reflect.TypeOf(*new(syntax.Error))
-	types["ErrorCode"] = reflect.TypeOf(*new(syntax.ErrorCode))
-	types["Flags"] = reflect.TypeOf(*new(syntax.Flags))
-	types["Prog"] = reflect.TypeOf(*new(syntax.Prog))
-	types["InstOp"] = reflect.TypeOf(*new(syntax.InstOp))
-	types["EmptyOp"] = reflect.TypeOf(*new(syntax.EmptyOp))
-	types["Inst"] = reflect.TypeOf(*new(syntax.Inst))
-	types["Regexp"] = reflect.TypeOf(*new(syntax.Regexp))
-	types["Op"] = reflect.TypeOf(*new(syntax.Op))
+	types["Error"] = reflect.TypeOf(new(syntax.Error)).Elem()
+	types["ErrorCode"] = reflect.TypeOf(new(syntax.ErrorCode)).Elem()
+	types["Flags"] = reflect.TypeOf(new(syntax.Flags)).Elem()
+	types["Prog"] = reflect.TypeOf(new(syntax.Prog)).Elem()
+	types["InstOp"] = reflect.TypeOf(new(syntax.InstOp)).Elem()
+	types["EmptyOp"] = reflect.TypeOf(new(syntax.EmptyOp)).Elem()
+	types["Inst"] = reflect.TypeOf(new(syntax.Inst)).Elem()
+	types["Regexp"] = reflect.TypeOf(new(syntax.Regexp)).Elem()
+	types["Op"] = reflect.TypeOf(new(syntax.Op)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["syntax"] = &eval.Env {
+	pkgs["regexp/syntax"] = &eval.Env {
 		Name: "syntax",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1467,13 +1466,13 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["GC"] = reflect.ValueOf(runtime.GC)
 
 	types = make(map[string] reflect.Type)
-	types["MemProfileRecord"] = reflect.TypeOf(*new(runtime.MemProfileRecord))
-	types["StackRecord"] = reflect.TypeOf(*new(runtime.StackRecord))
-	types["BlockProfileRecord"] = reflect.TypeOf(*new(runtime.BlockProfileRecord))
-	types["Error"] = reflect.TypeOf(*new(runtime.Error))
-	types["TypeAssertionError"] = reflect.TypeOf(*new(runtime.TypeAssertionError))
-	types["Func"] = reflect.TypeOf(*new(runtime.Func))
-	types["MemStats"] = reflect.TypeOf(*new(runtime.MemStats))
+	types["MemProfileRecord"] = reflect.TypeOf(new(runtime.MemProfileRecord)).Elem()
+	types["StackRecord"] = reflect.TypeOf(new(runtime.StackRecord)).Elem()
+	types["BlockProfileRecord"] = reflect.TypeOf(new(runtime.BlockProfileRecord)).Elem()
+	types["Error"] = reflect.TypeOf(new(runtime.Error)).Elem()
+	types["TypeAssertionError"] = reflect.TypeOf(new(runtime.TypeAssertionError)).Elem()
+	types["Func"] = reflect.TypeOf(new(runtime.Func)).Elem()
+	types["MemStats"] = reflect.TypeOf(new(runtime.MemStats)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["MemProfileRate"] = reflect.ValueOf(&runtime.MemProfileRate)
@@ -1497,10 +1496,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["StopCPUProfile"] = reflect.ValueOf(pprof.StopCPUProfile)
 
 	types = make(map[string] reflect.Type)
-	types["Profile"] = reflect.TypeOf(*new(pprof.Profile))
+	types["Profile"] = reflect.TypeOf(new(pprof.Profile)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["pprof"] = &eval.Env {
+	pkgs["runtime/pprof"] = &eval.Env {
 		Name: "pprof",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1528,10 +1527,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Stable"] = reflect.ValueOf(sort.Stable)
 
 	types = make(map[string] reflect.Type)
-	types["Interface"] = reflect.TypeOf(*new(sort.Interface))
-	types["IntSlice"] = reflect.TypeOf(*new(sort.IntSlice))
-	types["Float64Slice"] = reflect.TypeOf(*new(sort.Float64Slice))
-	types["StringSlice"] = reflect.TypeOf(*new(sort.StringSlice))
+	types["Interface"] = reflect.TypeOf(new(sort.Interface)).Elem()
+	types["IntSlice"] = reflect.TypeOf(new(sort.IntSlice)).Elem()
+	types["Float64Slice"] = reflect.TypeOf(new(sort.Float64Slice)).Elem()
+	types["StringSlice"] = reflect.TypeOf(new(sort.StringSlice)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["sort"] = &eval.Env {
@@ -1575,7 +1574,7 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["IsPrint"] = reflect.ValueOf(strconv.IsPrint)
 
 	types = make(map[string] reflect.Type)
-	types["NumError"] = reflect.TypeOf(*new(strconv.NumError))
+	types["NumError"] = reflect.TypeOf(new(strconv.NumError)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrRange"] = reflect.ValueOf(&strconv.ErrRange)
@@ -1637,8 +1636,8 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["IndexByte"] = reflect.ValueOf(strings.IndexByte)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(strings.Reader))
-	types["Replacer"] = reflect.TypeOf(*new(strings.Replacer))
+	types["Reader"] = reflect.TypeOf(new(strings.Reader)).Elem()
+	types["Replacer"] = reflect.TypeOf(new(strings.Replacer)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["strings"] = &eval.Env {
@@ -1656,12 +1655,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewCond"] = reflect.ValueOf(sync.NewCond)
 
 	types = make(map[string] reflect.Type)
-	types["Cond"] = reflect.TypeOf(*new(sync.Cond))
-	types["Mutex"] = reflect.TypeOf(*new(sync.Mutex))
-	types["Locker"] = reflect.TypeOf(*new(sync.Locker))
-	types["Once"] = reflect.TypeOf(*new(sync.Once))
-	types["RWMutex"] = reflect.TypeOf(*new(sync.RWMutex))
-	types["WaitGroup"] = reflect.TypeOf(*new(sync.WaitGroup))
+	types["Cond"] = reflect.TypeOf(new(sync.Cond)).Elem()
+	types["Mutex"] = reflect.TypeOf(new(sync.Mutex)).Elem()
+	types["Locker"] = reflect.TypeOf(new(sync.Locker)).Elem()
+	types["Once"] = reflect.TypeOf(new(sync.Once)).Elem()
+	types["RWMutex"] = reflect.TypeOf(new(sync.RWMutex)).Elem()
+	types["WaitGroup"] = reflect.TypeOf(new(sync.WaitGroup)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["sync"] = &eval.Env {
@@ -1709,7 +1708,7 @@ func EvalEnvironment(pkgs pkgType) {
 	types = make(map[string] reflect.Type)
 
 	vars = make(map[string] reflect.Value)
-	pkgs["atomic"] = &eval.Env {
+	pkgs["sync/atomic"] = &eval.Env {
 		Name: "atomic",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1948,73 +1947,73 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Time"] = reflect.ValueOf(syscall.Time)
 
 	types = make(map[string] reflect.Type)
-	types["SysProcAttr"] = reflect.TypeOf(*new(syscall.SysProcAttr))
-	types["Credential"] = reflect.TypeOf(*new(syscall.Credential))
-	types["ProcAttr"] = reflect.TypeOf(*new(syscall.ProcAttr))
-	types["NetlinkRouteRequest"] = reflect.TypeOf(*new(syscall.NetlinkRouteRequest))
-	types["NetlinkMessage"] = reflect.TypeOf(*new(syscall.NetlinkMessage))
-	types["NetlinkRouteAttr"] = reflect.TypeOf(*new(syscall.NetlinkRouteAttr))
-	types["SocketControlMessage"] = reflect.TypeOf(*new(syscall.SocketControlMessage))
-	types["WaitStatus"] = reflect.TypeOf(*new(syscall.WaitStatus))
-	types["SockaddrLinklayer"] = reflect.TypeOf(*new(syscall.SockaddrLinklayer))
-	types["SockaddrNetlink"] = reflect.TypeOf(*new(syscall.SockaddrNetlink))
-	types["Errno"] = reflect.TypeOf(*new(syscall.Errno))
-	types["Signal"] = reflect.TypeOf(*new(syscall.Signal))
-	types["Sockaddr"] = reflect.TypeOf(*new(syscall.Sockaddr))
-	types["SockaddrInet4"] = reflect.TypeOf(*new(syscall.SockaddrInet4))
-	types["SockaddrInet6"] = reflect.TypeOf(*new(syscall.SockaddrInet6))
-	types["SockaddrUnix"] = reflect.TypeOf(*new(syscall.SockaddrUnix))
-	types["Timespec"] = reflect.TypeOf(*new(syscall.Timespec))
-	types["Timeval"] = reflect.TypeOf(*new(syscall.Timeval))
-	types["Timex"] = reflect.TypeOf(*new(syscall.Timex))
-	types["Time_t"] = reflect.TypeOf(*new(syscall.Time_t))
-	types["Tms"] = reflect.TypeOf(*new(syscall.Tms))
-	types["Utimbuf"] = reflect.TypeOf(*new(syscall.Utimbuf))
-	types["Rusage"] = reflect.TypeOf(*new(syscall.Rusage))
-	types["Rlimit"] = reflect.TypeOf(*new(syscall.Rlimit))
-	types["Stat_t"] = reflect.TypeOf(*new(syscall.Stat_t))
-	types["Statfs_t"] = reflect.TypeOf(*new(syscall.Statfs_t))
-	types["Dirent"] = reflect.TypeOf(*new(syscall.Dirent))
-	types["Fsid"] = reflect.TypeOf(*new(syscall.Fsid))
-	types["RawSockaddrInet4"] = reflect.TypeOf(*new(syscall.RawSockaddrInet4))
-	types["RawSockaddrInet6"] = reflect.TypeOf(*new(syscall.RawSockaddrInet6))
-	types["RawSockaddrUnix"] = reflect.TypeOf(*new(syscall.RawSockaddrUnix))
-	types["RawSockaddrLinklayer"] = reflect.TypeOf(*new(syscall.RawSockaddrLinklayer))
-	types["RawSockaddrNetlink"] = reflect.TypeOf(*new(syscall.RawSockaddrNetlink))
-	types["RawSockaddr"] = reflect.TypeOf(*new(syscall.RawSockaddr))
-	types["RawSockaddrAny"] = reflect.TypeOf(*new(syscall.RawSockaddrAny))
-	types["Linger"] = reflect.TypeOf(*new(syscall.Linger))
-	types["Iovec"] = reflect.TypeOf(*new(syscall.Iovec))
-	types["IPMreq"] = reflect.TypeOf(*new(syscall.IPMreq))
-	types["IPMreqn"] = reflect.TypeOf(*new(syscall.IPMreqn))
-	types["IPv6Mreq"] = reflect.TypeOf(*new(syscall.IPv6Mreq))
-	types["Msghdr"] = reflect.TypeOf(*new(syscall.Msghdr))
-	types["Cmsghdr"] = reflect.TypeOf(*new(syscall.Cmsghdr))
-	types["Inet4Pktinfo"] = reflect.TypeOf(*new(syscall.Inet4Pktinfo))
-	types["Inet6Pktinfo"] = reflect.TypeOf(*new(syscall.Inet6Pktinfo))
-	types["IPv6MTUInfo"] = reflect.TypeOf(*new(syscall.IPv6MTUInfo))
-	types["ICMPv6Filter"] = reflect.TypeOf(*new(syscall.ICMPv6Filter))
-	types["Ucred"] = reflect.TypeOf(*new(syscall.Ucred))
-	types["TCPInfo"] = reflect.TypeOf(*new(syscall.TCPInfo))
-	types["NlMsghdr"] = reflect.TypeOf(*new(syscall.NlMsghdr))
-	types["NlMsgerr"] = reflect.TypeOf(*new(syscall.NlMsgerr))
-	types["RtGenmsg"] = reflect.TypeOf(*new(syscall.RtGenmsg))
-	types["NlAttr"] = reflect.TypeOf(*new(syscall.NlAttr))
-	types["RtAttr"] = reflect.TypeOf(*new(syscall.RtAttr))
-	types["IfInfomsg"] = reflect.TypeOf(*new(syscall.IfInfomsg))
-	types["IfAddrmsg"] = reflect.TypeOf(*new(syscall.IfAddrmsg))
-	types["RtMsg"] = reflect.TypeOf(*new(syscall.RtMsg))
-	types["RtNexthop"] = reflect.TypeOf(*new(syscall.RtNexthop))
-	types["SockFilter"] = reflect.TypeOf(*new(syscall.SockFilter))
-	types["SockFprog"] = reflect.TypeOf(*new(syscall.SockFprog))
-	types["InotifyEvent"] = reflect.TypeOf(*new(syscall.InotifyEvent))
-	types["PtraceRegs"] = reflect.TypeOf(*new(syscall.PtraceRegs))
-	types["FdSet"] = reflect.TypeOf(*new(syscall.FdSet))
-	types["Sysinfo_t"] = reflect.TypeOf(*new(syscall.Sysinfo_t))
-	types["Utsname"] = reflect.TypeOf(*new(syscall.Utsname))
-	types["Ustat_t"] = reflect.TypeOf(*new(syscall.Ustat_t))
-	types["EpollEvent"] = reflect.TypeOf(*new(syscall.EpollEvent))
-	types["Termios"] = reflect.TypeOf(*new(syscall.Termios))
+	types["SysProcAttr"] = reflect.TypeOf(new(syscall.SysProcAttr)).Elem()
+	types["Credential"] = reflect.TypeOf(new(syscall.Credential)).Elem()
+	types["ProcAttr"] = reflect.TypeOf(new(syscall.ProcAttr)).Elem()
+	types["NetlinkRouteRequest"] = reflect.TypeOf(new(syscall.NetlinkRouteRequest)).Elem()
+	types["NetlinkMessage"] = reflect.TypeOf(new(syscall.NetlinkMessage)).Elem()
+	types["NetlinkRouteAttr"] = reflect.TypeOf(new(syscall.NetlinkRouteAttr)).Elem()
+	types["SocketControlMessage"] = reflect.TypeOf(new(syscall.SocketControlMessage)).Elem()
+	types["WaitStatus"] = reflect.TypeOf(new(syscall.WaitStatus)).Elem()
+	types["SockaddrLinklayer"] = reflect.TypeOf(new(syscall.SockaddrLinklayer)).Elem()
+	types["SockaddrNetlink"] = reflect.TypeOf(new(syscall.SockaddrNetlink)).Elem()
+	types["Errno"] = reflect.TypeOf(new(syscall.Errno)).Elem()
+	types["Signal"] = reflect.TypeOf(new(syscall.Signal)).Elem()
+	types["Sockaddr"] = reflect.TypeOf(new(syscall.Sockaddr)).Elem()
+	types["SockaddrInet4"] = reflect.TypeOf(new(syscall.SockaddrInet4)).Elem()
+	types["SockaddrInet6"] = reflect.TypeOf(new(syscall.SockaddrInet6)).Elem()
+	types["SockaddrUnix"] = reflect.TypeOf(new(syscall.SockaddrUnix)).Elem()
+	types["Timespec"] = reflect.TypeOf(new(syscall.Timespec)).Elem()
+	types["Timeval"] = reflect.TypeOf(new(syscall.Timeval)).Elem()
+	types["Timex"] = reflect.TypeOf(new(syscall.Timex)).Elem()
+	types["Time_t"] = reflect.TypeOf(new(syscall.Time_t)).Elem()
+	types["Tms"] = reflect.TypeOf(new(syscall.Tms)).Elem()
+	types["Utimbuf"] = reflect.TypeOf(new(syscall.Utimbuf)).Elem()
+	types["Rusage"] = reflect.TypeOf(new(syscall.Rusage)).Elem()
+	types["Rlimit"] = reflect.TypeOf(new(syscall.Rlimit)).Elem()
+	types["Stat_t"] = reflect.TypeOf(new(syscall.Stat_t)).Elem()
+	types["Statfs_t"] = reflect.TypeOf(new(syscall.Statfs_t)).Elem()
+	types["Dirent"] = reflect.TypeOf(new(syscall.Dirent)).Elem()
+	types["Fsid"] = reflect.TypeOf(new(syscall.Fsid)).Elem()
+	types["RawSockaddrInet4"] = reflect.TypeOf(new(syscall.RawSockaddrInet4)).Elem()
+	types["RawSockaddrInet6"] = reflect.TypeOf(new(syscall.RawSockaddrInet6)).Elem()
+	types["RawSockaddrUnix"] = reflect.TypeOf(new(syscall.RawSockaddrUnix)).Elem()
+	types["RawSockaddrLinklayer"] = reflect.TypeOf(new(syscall.RawSockaddrLinklayer)).Elem()
+	types["RawSockaddrNetlink"] = reflect.TypeOf(new(syscall.RawSockaddrNetlink)).Elem()
+	types["RawSockaddr"] = reflect.TypeOf(new(syscall.RawSockaddr)).Elem()
+	types["RawSockaddrAny"] = reflect.TypeOf(new(syscall.RawSockaddrAny)).Elem()
+	types["Linger"] = reflect.TypeOf(new(syscall.Linger)).Elem()
+	types["Iovec"] = reflect.TypeOf(new(syscall.Iovec)).Elem()
+	types["IPMreq"] = reflect.TypeOf(new(syscall.IPMreq)).Elem()
+	types["IPMreqn"] = reflect.TypeOf(new(syscall.IPMreqn)).Elem()
+	types["IPv6Mreq"] = reflect.TypeOf(new(syscall.IPv6Mreq)).Elem()
+	types["Msghdr"] = reflect.TypeOf(new(syscall.Msghdr)).Elem()
+	types["Cmsghdr"] = reflect.TypeOf(new(syscall.Cmsghdr)).Elem()
+	types["Inet4Pktinfo"] = reflect.TypeOf(new(syscall.Inet4Pktinfo)).Elem()
+	types["Inet6Pktinfo"] = reflect.TypeOf(new(syscall.Inet6Pktinfo)).Elem()
+	types["IPv6MTUInfo"] = reflect.TypeOf(new(syscall.IPv6MTUInfo)).Elem()
+	types["ICMPv6Filter"] = reflect.TypeOf(new(syscall.ICMPv6Filter)).Elem()
+	types["Ucred"] = reflect.TypeOf(new(syscall.Ucred)).Elem()
+	types["TCPInfo"] = reflect.TypeOf(new(syscall.TCPInfo)).Elem()
+	types["NlMsghdr"] = reflect.TypeOf(new(syscall.NlMsghdr)).Elem()
+	types["NlMsgerr"] = reflect.TypeOf(new(syscall.NlMsgerr)).Elem()
+	types["RtGenmsg"] = reflect.TypeOf(new(syscall.RtGenmsg)).Elem()
+	types["NlAttr"] = reflect.TypeOf(new(syscall.NlAttr)).Elem()
+	types["RtAttr"] = reflect.TypeOf(new(syscall.RtAttr)).Elem()
+	types["IfInfomsg"] = reflect.TypeOf(new(syscall.IfInfomsg)).Elem()
+	types["IfAddrmsg"] = reflect.TypeOf(new(syscall.IfAddrmsg)).Elem()
+	types["RtMsg"] = reflect.TypeOf(new(syscall.RtMsg)).Elem()
+	types["RtNexthop"] = reflect.TypeOf(new(syscall.RtNexthop)).Elem()
+	types["SockFilter"] = reflect.TypeOf(new(syscall.SockFilter)).Elem()
+	types["SockFprog"] = reflect.TypeOf(new(syscall.SockFprog)).Elem()
+	types["InotifyEvent"] = reflect.TypeOf(new(syscall.InotifyEvent)).Elem()
+	types["PtraceRegs"] = reflect.TypeOf(new(syscall.PtraceRegs)).Elem()
+	types["FdSet"] = reflect.TypeOf(new(syscall.FdSet)).Elem()
+	types["Sysinfo_t"] = reflect.TypeOf(new(syscall.Sysinfo_t)).Elem()
+	types["Utsname"] = reflect.TypeOf(new(syscall.Utsname)).Elem()
+	types["Ustat_t"] = reflect.TypeOf(new(syscall.Ustat_t)).Elem()
+	types["EpollEvent"] = reflect.TypeOf(new(syscall.EpollEvent)).Elem()
+	types["Termios"] = reflect.TypeOf(new(syscall.Termios)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ForkLock"] = reflect.ValueOf(&syscall.ForkLock)
@@ -2045,15 +2044,15 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["RunTests"] = reflect.ValueOf(testing.RunTests)
 
 	types = make(map[string] reflect.Type)
-	types["InternalBenchmark"] = reflect.TypeOf(*new(testing.InternalBenchmark))
-	types["B"] = reflect.TypeOf(*new(testing.B))
-	types["BenchmarkResult"] = reflect.TypeOf(*new(testing.BenchmarkResult))
-	types["CoverBlock"] = reflect.TypeOf(*new(testing.CoverBlock))
-	types["Cover"] = reflect.TypeOf(*new(testing.Cover))
-	types["InternalExample"] = reflect.TypeOf(*new(testing.InternalExample))
-	types["TB"] = reflect.TypeOf(*new(testing.TB))
-	types["T"] = reflect.TypeOf(*new(testing.T))
-	types["InternalTest"] = reflect.TypeOf(*new(testing.InternalTest))
+	types["InternalBenchmark"] = reflect.TypeOf(new(testing.InternalBenchmark)).Elem()
+	types["B"] = reflect.TypeOf(new(testing.B)).Elem()
+	types["BenchmarkResult"] = reflect.TypeOf(new(testing.BenchmarkResult)).Elem()
+	types["CoverBlock"] = reflect.TypeOf(new(testing.CoverBlock)).Elem()
+	types["Cover"] = reflect.TypeOf(new(testing.Cover)).Elem()
+	types["InternalExample"] = reflect.TypeOf(new(testing.InternalExample)).Elem()
+	types["TB"] = reflect.TypeOf(new(testing.TB)).Elem()
+	types["T"] = reflect.TypeOf(new(testing.T)).Elem()
+	types["InternalTest"] = reflect.TypeOf(new(testing.InternalTest)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["testing"] = &eval.Env {
@@ -2078,10 +2077,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewWriter"] = reflect.ValueOf(tabwriter.NewWriter)
 
 	types = make(map[string] reflect.Type)
-	types["Writer"] = reflect.TypeOf(*new(tabwriter.Writer))
+	types["Writer"] = reflect.TypeOf(new(tabwriter.Writer)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["tabwriter"] = &eval.Env {
+	pkgs["text/tabwriter"] = &eval.Env {
 		Name: "tabwriter",
 		Consts: consts,
 		Funcs:  funcs,
@@ -2150,14 +2149,14 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["LoadLocation"] = reflect.ValueOf(time.LoadLocation)
 
 	types = make(map[string] reflect.Type)
-	types["ParseError"] = reflect.TypeOf(*new(time.ParseError))
-	types["Timer"] = reflect.TypeOf(*new(time.Timer))
-	types["Ticker"] = reflect.TypeOf(*new(time.Ticker))
-	types["Time"] = reflect.TypeOf(*new(time.Time))
-	types["Month"] = reflect.TypeOf(*new(time.Month))
-	types["Weekday"] = reflect.TypeOf(*new(time.Weekday))
-	types["Duration"] = reflect.TypeOf(*new(time.Duration))
-	types["Location"] = reflect.TypeOf(*new(time.Location))
+	types["ParseError"] = reflect.TypeOf(new(time.ParseError)).Elem()
+	types["Timer"] = reflect.TypeOf(new(time.Timer)).Elem()
+	types["Ticker"] = reflect.TypeOf(new(time.Ticker)).Elem()
+	types["Time"] = reflect.TypeOf(new(time.Time)).Elem()
+	types["Month"] = reflect.TypeOf(new(time.Month)).Elem()
+	types["Weekday"] = reflect.TypeOf(new(time.Weekday)).Elem()
+	types["Duration"] = reflect.TypeOf(new(time.Duration)).Elem()
+	types["Location"] = reflect.TypeOf(new(time.Location)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["UTC"] = reflect.ValueOf(&time.UTC)
@@ -2207,11 +2206,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["SimpleFold"] = reflect.ValueOf(unicode.SimpleFold)
 
 	types = make(map[string] reflect.Type)
-	types["RangeTable"] = reflect.TypeOf(*new(unicode.RangeTable))
-	types["Range16"] = reflect.TypeOf(*new(unicode.Range16))
-	types["Range32"] = reflect.TypeOf(*new(unicode.Range32))
-	types["CaseRange"] = reflect.TypeOf(*new(unicode.CaseRange))
-	types["SpecialCase"] = reflect.TypeOf(*new(unicode.SpecialCase))
+	types["RangeTable"] = reflect.TypeOf(new(unicode.RangeTable)).Elem()
+	types["Range16"] = reflect.TypeOf(new(unicode.Range16)).Elem()
+	types["Range32"] = reflect.TypeOf(new(unicode.Range32)).Elem()
+	types["CaseRange"] = reflect.TypeOf(new(unicode.CaseRange)).Elem()
+	types["SpecialCase"] = reflect.TypeOf(new(unicode.SpecialCase)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["TurkishCase"] = reflect.ValueOf(&unicode.TurkishCase)
@@ -2439,7 +2438,7 @@ func EvalEnvironment(pkgs pkgType) {
 	types = make(map[string] reflect.Type)
 
 	vars = make(map[string] reflect.Value)
-	pkgs["utf8"] = &eval.Env {
+	pkgs["unicode/utf8"] = &eval.Env {
 		Name: "utf8",
 		Consts: consts,
 		Funcs:  funcs,
@@ -0,0 +1,218 @@
+// Copyright 2014 Rocky Bernstein.
+// Transcript-based testing of REPL sessions
+
+// Package repltest checks go-fish sessions against transcripts: files
+// that look like what you see at the terminal. Lines starting with
+// the prompt, "gofish> ", are entered in turn, and each is followed by
+// the output expected for it:
+//
+//    gofish> help quit
+//    quit [exit-code]
+//    ...
+//
+// Text before the first prompt is commentary and is not checked, so a
+// transcript can double as documentation. Entries are run in one
+// session, so later entries see the results of earlier ones. Before
+// output is compared, terminal color codes and trailing white space are
+// removed and hexadecimal numbers of 6 or more digits, which are
+// usually addresses, become 0x?.
+//
+// In a test, use:
+//    func TestTranscripts(t *testing.T) {
+//        repltest.Run(t, "testdata/*.txt")
+//    }
+// and from the command line, "go-fish test FILE...".
+package repltest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish"
+)
+
+// Prompt starts each input line in a transcript.
+const Prompt = "gofish> "
+
+// Entry is one input line of a transcript with its expected output.
+type Entry struct {
+	Line   int // line number of the input in the transcript
+	Input  string
+	Output string
+}
+
+// Mismatch is an entry whose output was not what was expected.
+type Mismatch struct {
+	Entry
+	Actual string
+}
+
+// Parse reads a transcript.
+func Parse(r io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	var entry *Entry
+	var output []string
+	finish := func() {
+		if entry != nil {
+			entry.Output = strings.Join(output, "\n")
+			entries = append(entries, *entry)
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if strings.HasPrefix(line, Prompt) {
+			finish()
+			entry = &Entry{Line: lineno, Input: line[len(Prompt):]}
+			output = nil
+		} else if entry != nil {
+			output = append(output, line)
+		}
+	}
+	finish()
+	return entries, scanner.Err()
+}
+
+var (
+	colorCode = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+	hexNumber = regexp.MustCompile(`0x[0-9a-fA-F]{6,}`)
+	lineEnd   = regexp.MustCompile(`[ \t\r]+\n`)
+)
+
+// Normalize removes what varies from run to run, or with the terminal,
+// from output text: color codes, addresses, trailing white space on
+// lines and trailing blank lines.
+func Normalize(text string) string {
+	text = colorCode.ReplaceAllString(text, "")
+	text = hexNumber.ReplaceAllString(text, "0x?")
+	text = lineEnd.ReplaceAllString(text+"\n", "\n")
+	return strings.TrimRight(text, "\n")
+}
+
+// Check runs the entries of a transcript in session s and returns
+// those whose output doesn't match. Output is captured, and color is
+// turned off while they run.
+func Check(s *repl.Session, entries []Entry) []Mismatch {
+	saveHighlight := *repl.Highlight
+	*repl.Highlight = false
+	defer func() { *repl.Highlight = saveHighlight }()
+
+	mismatches := []Mismatch{}
+	for _, entry := range entries {
+		if s.LeaveREPL {
+			mismatches = append(mismatches, Mismatch{entry, "(session has ended)"})
+			continue
+		}
+		c := s.Capture(entry.Input, true)
+		actual := Normalize(c.Output + c.Stdout + c.Stderr)
+		if actual != Normalize(entry.Output) {
+			mismatches = append(mismatches, Mismatch{entry, actual})
+		}
+	}
+	return mismatches
+}
+
+// CheckFile runs the transcript in file in a session from newSession,
+// writing a report of any mismatches to out. The number of
+// mismatches is returned.
+func CheckFile(file string, newSession func() *repl.Session, out io.Writer) (int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	entries, err := Parse(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", file, err)
+	}
+	mismatches := Check(newSession(), entries)
+	for _, m := range mismatches {
+		fmt.Fprintf(out, "%s:%d: %s%s\n%s", file, m.Line, Prompt, m.Input,
+			Diff(Normalize(m.Output), m.Actual))
+	}
+	return len(mismatches), nil
+}
+
+// NewSession is the default for making sessions to run transcripts
+// in: a session in a fresh environment from repl.MakeEvalEnv.
+func NewSession() *repl.Session {
+	return repl.NewSession(nil, nil, nil)
+}
+
+// Run checks the transcripts in the files matching glob as subtests
+// of t, each in a session from NewSession. The REPL commands have to
+// be linked in, for example by importing github.com/rocky/go-fish/cmd.
+func Run(t *testing.T, glob string) {
+	RunSession(t, glob, NewSession)
+}
+
+// RunSession is like Run but runs each transcript in a session from
+// newSession. Use it to define your own variables, functions and
+// packages for the transcripts to use; see Session.Define.
+func RunSession(t *testing.T, glob string, newSession func() *repl.Session) {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no transcripts match %s", glob)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			var report strings.Builder
+			if _, err := CheckFile(file, newSession, &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Len() > 0 {
+				t.Errorf("transcript differs (-expected +actual):\n%s", report.String())
+			}
+		})
+	}
+}
+
+// Diff returns the differences between the lines of expected and
+// actual. Lines only in expected start with "-", lines only in actual
+// start with "+" and common lines start with a space.
+func Diff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var buf strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&buf, " %s\n", a[i])
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			fmt.Fprintf(&buf, "-%s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&buf, "+%s\n", b[j])
+			j++
+		}
+	}
+	return buf.String()
+}
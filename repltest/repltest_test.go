@@ -0,0 +1,43 @@
+package repltest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish/repltest"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := repltest.Parse(strings.NewReader(
+		"Commentary\n\ngofish> a\n1\n2\ngofish> b\ngofish> c\n3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []repltest.Entry{
+		{Line: 3, Input: "a", Output: "1\n2"},
+		{Line: 6, Input: "b", Output: ""},
+		{Line: 7, Input: "c", Output: "3"},
+	}
+	if len(entries) != len(expect) {
+		t.Fatalf("expecting %d entries; got %v", len(expect), entries)
+	}
+	for i, e := range expect {
+		if entries[i] != e {
+			t.Errorf("entry %d: expecting %v; got %v", i, e, entries[i])
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := repltest.Normalize("\x1b[1mKind = ptr\x1b[0m  \n&{0x1882d120} 0x10\n\n")
+	if expect := "Kind = ptr\n&{0x?} 0x10"; got != expect {
+		t.Errorf("expecting %q; got %q", expect, got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := repltest.Diff("a\nb\nc", "a\nx\nc")
+	if expect := " a\n-b\n+x\n c\n"; got != expect {
+		t.Errorf("expecting %q; got %q", expect, got)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2014 Rocky Bernstein.
+// Sandbox policies: which packages, functions and commands may be used
+
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/0xfaded/eval"
+)
+
+// Policy says which packages, package members, names in the session's
+// environment and REPL commands a session may use. Expressions are
+// checked against it before they are type checked, so nothing that
+// refers to something denied is evaluated.
+type Policy struct {
+	// Name is the policy's name or file, for error messages.
+	Name string
+
+	// Rules allow or deny things; see Allows for which rule wins.
+	Rules []PolicyRule
+
+	// DenyOthers denies what no rule matches; otherwise it is
+	// allowed.
+	DenyOthers bool
+
+	// ReadOnly also disallows calls to functions that may have side
+	// effects; see CheckReadOnly.
+	ReadOnly bool
+}
+
+// PolicyRule allows or denies the things that Pattern matches. For
+// a command rule, Pattern is a command name. Otherwise it is one of:
+//    os              package "os" and all of its members
+//    os.RemoveAll    just member RemoveAll of package "os"
+//    net/...         package "net" and the packages below it
+//    env             name "env" in the session's own environment
+type PolicyRule struct {
+	Allow   bool
+	Command bool
+	Pattern string
+}
+
+// basePolicyRules deny ways around any policy: the REPL and evaluator
+// packages, and the environment itself, give access to everything.
+var basePolicyRules = []PolicyRule{
+	{Pattern: "github.com/rocky/go-fish/..."},
+	{Pattern: "github.com/0xfaded/eval"},
+	{Pattern: "env"},
+	{Pattern: "unsafe"},
+}
+
+// Policies are the built-in policies:
+//    pure     only calls without side effects, as in read-only mode
+//    no-exec  no running programs or system calls, and no exiting
+//    no-fs    no reading or writing files
+// Names can be combined with commas, as in "no-exec,no-fs"; see
+// LoadPolicy. Keep in mind that the lists of what no-exec and no-fs
+// deny can't be complete for every package compiled into go-fish; use
+// "default deny" in a policy file to allow only what you list.
+var Policies = map[string]*Policy{
+	"pure": {
+		Name:     "pure",
+		ReadOnly: true,
+		Rules: append(denyRules(false, "os/exec", "syscall", "plugin"),
+			denyRules(true, readOnlyDeniedCommands...)...),
+	},
+	"no-exec": {
+		Name: "no-exec",
+		Rules: append(denyRules(false, "os/exec", "syscall", "plugin",
+			"os.Exit", "os.FindProcess", "os.StartProcess", "runtime.Goexit"),
+			denyRules(true, "shell")...),
+	},
+	"no-fs": {
+		Name: "no-fs",
+		Rules: append(denyRules(false, "os", "io/ioutil", "syscall",
+			"os/exec", "plugin", "debug/...", "archive/zip.OpenReader",
+			"path/filepath.Abs", "path/filepath.EvalSymlinks",
+			"path/filepath.Glob", "path/filepath.Walk", "path/filepath.WalkDir",
+			"html/template.ParseFiles", "html/template.ParseGlob",
+			"text/template.ParseFiles", "text/template.ParseGlob"),
+			denyRules(true, "alias", "cd", "macro", "profile", "save", "shell",
+				"source", "unalias")...),
+	},
+}
+
+// readOnlyDeniedCommands are the REPL commands with side effects,
+// which read-only sessions and the "pure" policy don't allow.
+// alias, macro and unalias write the session's InitFile.
+var readOnlyDeniedCommands = []string{"alias", "cd", "gc", "gcpercent",
+	"gomaxprocs", "macro", "profile", "save", "shell", "source", "unalias"}
+
+func init() {
+	for _, p := range Policies {
+		p.Rules = append(append([]PolicyRule{}, basePolicyRules...), p.Rules...)
+	}
+}
+
+func denyRules(command bool, patterns ...string) []PolicyRule {
+	rules := []PolicyRule{}
+	for _, pattern := range patterns {
+		rules = append(rules, PolicyRule{Command: command, Pattern: pattern})
+	}
+	return rules
+}
+
+// LoadPolicy returns the policy given by spec: a comma-separated list
+// of built-in policy names (see Policies) and policy files (see
+// ReadPolicy). The policies are combined: their rules are checked
+// together, and what any of them makes read-only or denies by default
+// stays that way.
+func LoadPolicy(spec string) (*Policy, error) {
+	p := &Policy{Name: spec}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		part, ok := Policies[name]
+		if !ok {
+			var err error
+			if part, err = ReadPolicy(name); os.IsNotExist(err) {
+				return nil, fmt.Errorf("no built-in policy or policy file %s; "+
+					"built-in policies are: %s", name,
+					strings.Join(PolicyNames(), ", "))
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		p.add(part)
+	}
+	return p, nil
+}
+
+// add adds the rules and settings of policy q to p.
+func (p *Policy) add(q *Policy) {
+	p.Rules = append(p.Rules, q.Rules...)
+	p.DenyOthers = p.DenyOthers || q.DenyOthers
+	p.ReadOnly = p.ReadOnly || q.ReadOnly
+}
+
+// ReadPolicy reads a policy file; see ParsePolicy.
+func ReadPolicy(file string) (*Policy, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParsePolicy(file, f)
+}
+
+// ParsePolicy reads policy "name" from r, which has one rule or
+// setting per line:
+//    allow PATTERN...
+//    deny PATTERN...
+//    allow command NAME...
+//    deny command NAME...
+//    default allow|deny
+//    readonly
+//    include POLICY
+// PATTERN is as for PolicyRule, and "include" adds the rules of a
+// built-in policy. Blank lines and lines starting with "#" are
+// skipped. Unless "default" says otherwise, what no rule matches is
+// allowed.
+func ParsePolicy(name string, r io.Reader) (*Policy, error) {
+	p := &Policy{Name: name, Rules: append([]PolicyRule{}, basePolicyRules...)}
+	scanner := bufio.NewScanner(r)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+			continue
+		}
+		bad := func(format string, a ...interface{}) error {
+			return fmt.Errorf("%s:%d: %s", name, lineno, fmt.Sprintf(format, a...))
+		}
+		switch words[0] {
+		case "allow", "deny":
+			allow := words[0] == "allow"
+			command := len(words) > 1 && words[1] == "command"
+			patterns := words[1:]
+			if command {
+				patterns = words[2:]
+			}
+			if len(patterns) == 0 {
+				return nil, bad("expecting something to %s", words[0])
+			}
+			for _, pattern := range patterns {
+				p.Rules = append(p.Rules,
+					PolicyRule{Allow: allow, Command: command, Pattern: pattern})
+			}
+		case "default":
+			if len(words) != 2 || (words[1] != "allow" && words[1] != "deny") {
+				return nil, bad("expecting \"default allow\" or \"default deny\"")
+			}
+			p.DenyOthers = words[1] == "deny"
+		case "readonly":
+			p.ReadOnly = true
+		case "include":
+			if len(words) != 2 || Policies[words[1]] == nil {
+				return nil, bad("expecting one of the built-in policies: %s",
+					strings.Join(PolicyNames(), ", "))
+			}
+			p.add(Policies[words[1]])
+		default:
+			return nil, bad("unknown rule %s", words[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PolicyNames returns the names of the built-in policies in order.
+func PolicyNames() []string {
+	names := []string{}
+	for name := range Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// match says how specifically rule r matches member "name" of the
+// package with import path pkgPath, or if pkgPath is empty, name in
+// the session's own environment. 0 means it doesn't match; a member
+// matches more specifically than its package, which matches more
+// specifically than a "/..." pattern.
+func (r PolicyRule) match(pkgPath, name string) int {
+	if r.Command {
+		return 0
+	}
+	if pkgPath == "" {
+		if r.Pattern == name {
+			return 3
+		}
+		return 0
+	}
+	switch {
+	case r.Pattern == pkgPath+"."+name:
+		return 3
+	case r.Pattern == pkgPath:
+		return 2
+	case strings.HasSuffix(r.Pattern, "/..."):
+		prefix := strings.TrimSuffix(r.Pattern, "...")
+		if pkgPath+"/" == prefix || strings.HasPrefix(pkgPath, prefix) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Allows reports whether p allows member "name" of the package with
+// import path pkgPath, or if pkgPath is empty, name in the session's
+// own environment. The most specific rule that matches wins, and of
+// those equally specific, the last one.
+func (p *Policy) Allows(pkgPath, name string) bool {
+	best, allow := 0, !p.DenyOthers
+	for _, r := range p.Rules {
+		if m := r.match(pkgPath, name); m > 0 && m >= best {
+			best, allow = m, r.Allow
+		}
+	}
+	return allow
+}
+
+// AllowsCommand reports whether p allows REPL command "name". The last
+// rule for the command wins. "help" and "quit" are always allowed.
+func (p *Policy) AllowsCommand(name string) bool {
+	if name == "help" || name == "quit" {
+		return true
+	}
+	allow := !p.DenyOthers
+	for _, r := range p.Rules {
+		if r.Command && r.Pattern == name {
+			allow = r.Allow
+		}
+	}
+	return allow
+}
+
+// SandboxError is the error for something a policy doesn't allow.
+type SandboxError struct {
+	Policy string
+	What   string
+}
+
+func (e *SandboxError) Error() string {
+	return fmt.Sprintf("sandbox %s: %s is not allowed", e.Policy, e.What)
+}
+
+// CheckPolicy returns an error if expr refers to a package member, or
+// a name in env itself, that policy p does not allow.
+func CheckPolicy(expr ast.Expr, env *eval.Env, p *Policy) error {
+	var err error
+	deny := func(what string) {
+		err = &SandboxError{Policy: p.Name, What: what}
+	}
+	var check func(node ast.Node) bool
+	check = func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				if pkg, ok := env.Pkgs[x.Name]; ok {
+					if !p.Allows(pkg.Path, n.Sel.Name) {
+						deny(fmt.Sprintf("%s.%s (package \"%s\")", x.Name,
+							n.Sel.Name, pkg.Path))
+					}
+					return false
+				}
+			}
+			// The field or method selected isn't a name in env.
+			ast.Inspect(n.X, check)
+			return false
+		case *ast.Ident:
+			if isEnvName(env, n.Name) && !p.Allows("", n.Name) {
+				deny(n.Name)
+			}
+		}
+		return err == nil
+	}
+	ast.Inspect(expr, check)
+	return err
+}
+
+// isEnvName reports whether name is defined in env itself rather than
+// in one of its packages.
+func isEnvName(env *eval.Env, name string) bool {
+	if _, ok := env.Vars[name]; ok {
+		return true
+	}
+	if _, ok := env.Funcs[name]; ok {
+		return true
+	}
+	if _, ok := env.Consts[name]; ok {
+		return true
+	}
+	_, ok := env.Types[name]
+	return ok
+}
+
+// CheckAllowed returns an error if session s may not evaluate expr,
+// because of its sandbox policy or because it is read-only.
+func (s *Session) CheckAllowed(expr ast.Expr) error {
+	if s.Policy != nil {
+		if err := CheckPolicy(expr, s.Env, s.Policy); err != nil {
+			return err
+		}
+	}
+	if s.ReadOnly || (s.Policy != nil && s.Policy.ReadOnly) {
+		return CheckReadOnly(expr, s.Env)
+	}
+	return nil
+}
+
+// CheckCommand returns an error if session s may not run REPL command
+// "name", because of its sandbox policy or because it is read-only.
+func (s *Session) CheckCommand(name string) error {
+	if s.Policy != nil && !s.Policy.AllowsCommand(name) {
+		return fmt.Errorf("sandbox %s: command %s is not allowed",
+			s.Policy.Name, name)
+	}
+	if s.ReadOnly || (s.Policy != nil && s.Policy.ReadOnly) {
+		for _, denied := range readOnlyDeniedCommands {
+			if name == denied {
+				return fmt.Errorf("read-only: command %s is not allowed", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Restrictions describes what keeps session s from doing whatever it
+// likes, e.g. "read-only" or "sandbox no-fs", or is "" if nothing does.
+func (s *Session) Restrictions() string {
+	restrictions := []string{}
+	if s.ReadOnly {
+		restrictions = append(restrictions, "read-only")
+	}
+	if s.Policy != nil {
+		restrictions = append(restrictions, "sandbox "+s.Policy.Name)
+	}
+	return strings.Join(restrictions, ", ")
+}
@@ -0,0 +1,106 @@
+package repl_test
+
+import (
+	"go/parser"
+	"strings"
+	"testing"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestPolicy(t *testing.T) {
+	s := repl.NewSession(nil, nil, nil)
+	err := s.DefinePackage("sandboxfs", "example.com/sandbox/fs",
+		map[string]interface{}{
+			"Remove": func(string) error { return nil },
+			"Clean":  func(p string) string { return p },
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Define("answer", 42); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := repl.ParsePolicy("test", strings.NewReader(`
+# Nothing from example.com/sandbox but sandboxfs.Clean
+deny example.com/sandbox/...
+allow example.com/sandbox/fs.Clean
+deny answer
+deny command history
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{`sandboxfs.Clean("a")`, true},
+		{`sandboxfs.Remove("a")`, false},
+		{`len(sandboxfs.Clean(sandboxfs.Clean("a")))`, true},
+		{`[]interface{}{sandboxfs.Remove}`, false},
+		{`answer + 1`, false},
+		{`results`, true},
+		{`env.Name`, false},
+	}
+	for _, test := range tests {
+		expr, err := parser.ParseExpr(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = repl.CheckPolicy(expr, s.Env, policy)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: expecting allowed to be %v; got error %v", test.expr,
+				test.ok, err)
+		}
+	}
+
+	s.Policy = policy
+	result := s.Capture(`sandboxfs.Remove("a")`, false).Result
+	if len(result.Errors) != 1 || result.Errors[0].Kind != "sandbox" {
+		t.Errorf("expecting a sandbox error; got %v", result.Errors)
+	}
+	if out := s.Capture("history", true).Output; !strings.Contains(out,
+		"command history is not allowed") {
+		t.Errorf("expecting history to be denied; got %q", out)
+	}
+
+	for _, bad := range []string{"allow", "default maybe", "include nothing",
+		"permit os"} {
+		if _, err := repl.ParsePolicy("bad", strings.NewReader(bad)); err == nil {
+			t.Errorf("%s: expecting an error", bad)
+		}
+	}
+
+	policy, err = repl.LoadPolicy("no-exec,no-fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Allows("os", "Open") || policy.Allows("os/exec", "Command") ||
+		policy.AllowsCommand("save") || !policy.Allows("strings", "Fields") {
+		t.Errorf("no-exec,no-fs: unexpected rules %v", policy.Rules)
+	}
+	for _, name := range []string{"no-fs", "pure"} {
+		for _, command := range []string{"alias", "macro", "unalias"} {
+			if repl.Policies[name].AllowsCommand(command) {
+				t.Errorf("%s: expecting %s, which writes the init file, to be denied",
+					name, command)
+			}
+		}
+	}
+
+	s = repl.NewSession(nil, nil, nil)
+	s.ReadOnly = true
+	for _, line := range []string{"shell echo hi", "!echo hi", "cd /"} {
+		if out := s.Capture(line, true).Output; !strings.Contains(out,
+			"read-only: command") {
+			t.Errorf("%s: expecting to be refused when read-only; got %q", line, out)
+		}
+	}
+	if out := s.Capture("history", true).Output; strings.Contains(out,
+		"not allowed") {
+		t.Errorf("expecting history to be allowed when read-only; got %q", out)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2014 Rocky Bernstein.
+// Remote REPL sessions over TCP or Unix-domain sockets
+
+package repl
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/0xfaded/eval"
+)
+
+// SecretPrompt is what a server sends to a client when it needs to
+// be given the shared secret.
+const SecretPrompt = "go-fish secret: "
+
+// Server runs an independent REPL session for each connection it
+// accepts. Each session gets its own copy of the environment, and its
+// own results and history.
+//
+// Results and command output go to the connection, but what
+// expressions write to os.Stdout and os.Stderr, e.g. with fmt.Println,
+// goes to the serving process's own output. Those are shared by the
+// whole process, and capturing them for one session would keep the
+// others waiting.
+type Server struct {
+	Env *eval.Env
+
+	// Secret, if not empty, must be sent by clients as their first
+	// line before they get a session.
+	Secret string
+
+	// ReadOnly makes sessions read-only; see CheckReadOnly.
+	ReadOnly bool
+
+	// Policy, if not nil, is the sandbox policy for sessions; see
+	// Policy.
+	Policy *Policy
+}
+
+// Serve accepts connections on listener l and runs a REPL session
+// in env for each; see Server for where output goes. It returns when
+// l.Accept fails, for example because the listener was closed.
+func Serve(l net.Listener, env *eval.Env) error {
+	srv := &Server{Env: env}
+	return srv.Serve(l)
+}
+
+// Serve accepts connections on l and runs a REPL session for each.
+func (srv *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.serveConn(conn)
+	}
+}
+
+// SplitAddress splits an address given on the command line into a
+// network and address for net.Dial or net.Listen. "unix:PATH" is a
+// Unix-domain socket; anything else is a TCP host:port.
+func SplitAddress(addr string) (string, string) {
+	if strings.HasPrefix(addr, "unix:") {
+		return "unix", addr[len("unix:"):]
+	}
+	return "tcp", addr
+}
+
+// Listen listens on addr, which is given as for SplitAddress.
+func Listen(addr string) (net.Listener, error) {
+	network, address := SplitAddress(addr)
+	return net.Listen(network, address)
+}
+
+// serveConn authenticates the client on conn if needed, and then runs
+// a REPL session for it.
+func (srv *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	readLine := func(prompt string, add_history ...bool) (string, error) {
+		fmt.Fprint(conn, prompt)
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	if srv.Secret != "" {
+		secret, err := readLine(SecretPrompt)
+		if err != nil {
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(srv.Secret)) != 1 {
+			fmt.Fprintln(conn, "go-fish: wrong secret")
+			return
+		}
+	}
+
+	s := NewSession(CopyEnv(srv.Env), readLine, SimpleInspect)
+	s.Out = conn
+	s.ReadOnly = srv.ReadOnly
+	s.Policy = srv.Policy
+	mode := ""
+	if restrictions := s.Restrictions(); restrictions != "" {
+		mode = " (" + restrictions + ")"
+	}
+	fmt.Fprintf(conn, "go-fish remote session%s from %s.\n"+
+		"To leave, enter: \"quit\" or end of file.\n", mode, conn.LocalAddr())
+	if err := s.Run(); err != nil && err != io.EOF {
+		fmt.Fprintf(conn, "go-fish: %s\n", err)
+	}
+}
+
+// Connect connects to a go-fish server at addr (see SplitAddress)
+// and copies "in" to it and its output to "out" until either side
+// closes. If the server asks for a secret and secret is not empty,
+// it is sent without bothering the user.
+func Connect(addr string, secret string, in io.Reader, out io.Writer) error {
+	network, address := SplitAddress(addr)
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Servers start with either SecretPrompt or a longer greeting.
+	start := make([]byte, len(SecretPrompt))
+	if _, err := io.ReadFull(conn, start); err != nil {
+		return err
+	}
+	if string(start) == SecretPrompt && secret != "" {
+		fmt.Fprintln(conn, secret)
+	} else {
+		out.Write(start)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(out, conn)
+		done <- err
+	}()
+	go func() {
+		io.Copy(conn, in)
+		// Let the server see end of file but keep reading what it
+		// sends.
+		if c, ok := conn.(interface {
+			CloseWrite() error
+		}); ok {
+			c.CloseWrite()
+		}
+	}()
+	return <-done
+}
@@ -0,0 +1,506 @@
+// Copyright 2014 Rocky Bernstein.
+// REPL sessions
+
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"go/parser"
+	"go/scanner"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0xfaded/eval"
+)
+
+// Session is one REPL conversation: an evaluation environment, where
+// input comes from and output goes, and the results and history of
+// what has been entered. Several sessions can be active at once, for
+// example one per network connection (see Serve). Only one of them
+// runs a command at any given time, but expressions are evaluated
+// while others run; see WithLimits.
+type Session struct {
+	Env      *eval.Env
+	ReadLine ReadLineFnType
+	Inspect  InspectFnType
+
+	// Out is where output for this session goes.
+	Out io.Writer
+
+	// Results holds the values of expressions entered. It is
+	// variable "results" in Env.
+	Results []interface{}
+
+	// History holds the lines entered, most recent last.
+	History []string
+
+	// Transcript holds what was entered along with what was shown
+	// for it; see the "save" command.
+	Transcript []TranscriptEntry
+
+	// ReadOnly is set when calls to functions that may have side
+	// effects, and commands that do, are not allowed. See
+	// CheckReadOnly and CheckCommand.
+	ReadOnly bool
+
+	// Policy, if not nil, is the sandbox policy that says what
+	// expressions and commands may use. See CheckAllowed.
+	Policy *Policy
+
+	// Limits are how much an evaluation may use before it is
+	// aborted; see WithLimits.
+	Limits Limits
+
+	// Macros are the aliases and macros defined in the session; see
+	// DefineMacro.
+	Macros map[string]*Macro
+
+	// InitFile, if not empty, is where aliases and macros defined at
+	// the prompt are saved, so that they are defined again when the
+	// file is sourced.
+	InitFile string
+
+	// Hooks are called as lines are entered and evaluated; see
+	// AddHook.
+	Hooks []*Hook
+
+	// SourceDepth is how many "source" commands the session is
+	// running, one inside the other.
+	SourceDepth int
+
+	// inHook is set while hooks are being called.
+	inHook bool
+
+	// shadowWarned holds the commands that the session has been told
+	// are shadowed by Go names.
+	shadowWarned map[string]bool
+
+	// running keeps the session from processing more than one thing
+	// at a time, and locked is set while it holds sessionLock.
+	running sync.Mutex
+	locked  bool
+
+	// settings, if not nil, are the session's own values of settings
+	// such as CmdPrefix, and outside are the values they have when
+	// no session is running; see run.
+	settings *sessionSettings
+	outside  sessionSettings
+
+	// LeaveREPL and ExitCode are the session's values of the
+	// package variables of the same name.
+	LeaveREPL bool
+	ExitCode  int
+}
+
+// Current is the session that is running a command or evaluating an
+// expression.
+var Current *Session
+
+// sessionLock keeps more than one session from using package
+// variables like Env and Out at a time. It is let go while an
+// expression is evaluated, so that a slow evaluation in one session
+// doesn't hold up the others.
+var sessionLock sync.Mutex
+
+// globals are the package variables that a running session sets.
+type globals struct {
+	current   *Session
+	env       *eval.Env
+	out       io.Writer
+	leaveREPL bool
+	exitCode  int
+	cmdLine   string
+	cmdRest   string
+	cmdArgs   *ParsedArgs
+}
+
+func saveGlobals() globals {
+	return globals{Current, Env, Out, LeaveREPL, ExitCode, CmdLine, CmdRest,
+		CmdArgs}
+}
+
+func (g globals) restore() {
+	Current, Env, Out, LeaveREPL, ExitCode = g.current, g.env, g.out,
+		g.leaveREPL, g.exitCode
+	CmdLine, CmdRest, CmdArgs = g.cmdLine, g.cmdRest, g.cmdArgs
+}
+
+// NewSession creates a session that evaluates in env, reads lines
+// with readLineFn and shows values with inspectFn. Variable "results"
+// is set in env.
+//
+// If env is nil, a new environment from MakeEvalEnv is used with "env"
+// set to it. If readLineFn is nil, SimpleReadLine on standard input
+// is used, and if inspectFn is nil, SimpleInspect is used. So all a
+// program needs in order to open a REPL on its own objects is
+// something like:
+//    s := repl.NewSession(nil, nil, nil)
+//    s.Define("server", server); s.Run()
+// along with importing github.com/rocky/go-fish/cmd for the REPL
+// commands.
+func NewSession(env *eval.Env, readLineFn ReadLineFnType,
+	inspectFn InspectFnType) *Session {
+	if env == nil {
+		newEnv := MakeEvalEnv()
+		env = &newEnv
+		env.Vars["env"] = reflect.ValueOf(env)
+	}
+	if readLineFn == nil {
+		if Input == nil {
+			Input = bufio.NewReader(os.Stdin)
+		}
+		readLineFn = SimpleReadLine
+	}
+	if inspectFn == nil {
+		inspectFn = SimpleInspect
+	}
+	s := &Session{
+		Env:      env,
+		ReadLine: readLineFn,
+		Inspect:  inspectFn,
+		Out:      os.Stdout,
+		Results:  make([]interface{}, 0, 10),
+		Limits:   DefaultLimits,
+	}
+	env.Vars["results"] = reflect.ValueOf(&s.Results)
+	return s
+}
+
+// CopyEnv returns a copy of env which can be changed, say by adding
+// variables or importing packages, without changing env.
+func CopyEnv(env *eval.Env) *eval.Env {
+	newEnv := *env
+	newEnv.Vars = make(map[string]reflect.Value)
+	for name, v := range env.Vars {
+		newEnv.Vars[name] = v
+	}
+	newEnv.Consts = make(map[string]reflect.Value)
+	for name, v := range env.Consts {
+		newEnv.Consts[name] = v
+	}
+	newEnv.Funcs = make(map[string]reflect.Value)
+	for name, v := range env.Funcs {
+		newEnv.Funcs[name] = v
+	}
+	newEnv.Types = make(map[string]reflect.Type)
+	for name, t := range env.Types {
+		newEnv.Types[name] = t
+	}
+	newEnv.Pkgs = make(map[string]eval.Pkg)
+	for name, pkg := range env.Pkgs {
+		newEnv.Pkgs[name] = pkg
+	}
+	if _, ok := env.Vars["env"]; ok {
+		newEnv.Vars["env"] = reflect.ValueOf(&newEnv)
+	}
+	return &newEnv
+}
+
+// Run reads lines and processes them until end of file or until
+// we are asked to leave. An error other than io.EOF from reading is
+// returned.
+func (s *Session) Run() error {
+	for !s.LeaveREPL {
+		line, err := s.ReadLine("gofish> ", true)
+		if err != nil {
+			if err == io.EOF {
+				s.run(s.exitHooks)
+				return nil
+			}
+			return err
+		}
+		s.ProcessLine(line)
+	}
+	s.run(s.exitHooks)
+	return nil
+}
+
+// ProcessLine runs the REPL command on line or else evaluates it.
+func (s *Session) ProcessLine(line string) {
+	s.run(func() { s.processLine(line, true) })
+}
+
+// run calls fn with package variables like Env and Out set for
+// session s, and with no other session running.
+func (s *Session) run(fn func()) {
+	s.running.Lock()
+	defer s.running.Unlock()
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
+	Current, Env, Out = s, s.Env, s.Out
+	LeaveREPL, ExitCode = s.LeaveREPL, s.ExitCode
+	s.enterSettings()
+	s.locked = true
+	defer func() {
+		s.locked = false
+		s.LeaveREPL, s.ExitCode = LeaveREPL, ExitCode
+		s.leaveSettings()
+	}()
+	fn()
+}
+
+// enterSettings puts session s's own settings, if any, in place.
+func (s *Session) enterSettings() {
+	s.outside = currentSettings()
+	if s.settings != nil {
+		s.settings.apply()
+	}
+}
+
+// leaveSettings saves session s's own settings, if any, and puts back
+// those that were in place before enterSettings.
+func (s *Session) leaveSettings() {
+	if s.settings != nil {
+		*s.settings = currentSettings()
+	}
+	s.outside.apply()
+}
+
+// unlocked calls fn, which mustn't use package variables like Env and
+// Out, with sessionLock let go if session s holds it, so that other
+// sessions can run meanwhile. The package variables are set back
+// afterwards.
+func (s *Session) unlocked(fn func()) {
+	if !s.locked {
+		fn()
+		return
+	}
+	saved := saveGlobals()
+	s.leaveSettings()
+	s.locked = false
+	sessionLock.Unlock()
+	defer func() {
+		sessionLock.Lock()
+		s.locked = true
+		saved.restore()
+		s.enterSettings()
+	}()
+	fn()
+}
+
+// TranscriptEntry is a line entered in a session and the output
+// shown for it.
+type TranscriptEntry struct {
+	Input  string
+	Output string
+
+	// Result is the outcome of evaluating Input, or nil if it was
+	// a REPL command.
+	Result *EvalResult
+}
+
+// Enter processes line as if it had been entered at the prompt. Use
+// this from within commands, where the session is already running.
+func (s *Session) Enter(line string) *EvalResult {
+	return s.processLine(line, true)
+}
+
+// processLine runs the REPL command on line or else evaluates it. The
+// outcome of an evaluation is returned, and shown if "show" is set.
+// nil is returned for REPL commands.
+func (s *Session) processLine(line string, show bool) *EvalResult {
+	if line == "" {
+		wasProcessed(line)
+		return nil
+	}
+	if line = s.inputHooks(line); line == "" {
+		return nil
+	}
+	s.History = append(s.History, line)
+	var output bytes.Buffer
+	saveOut := Out
+	Out = io.MultiWriter(Out, &output)
+	defer func() { Out = saveOut }()
+
+	entry := len(s.Transcript)
+	s.Transcript = append(s.Transcript, TranscriptEntry{Input: line})
+	var result *EvalResult
+	if expanded, err := s.ExpandMacros(line); err != nil {
+		Errmsg("%s", err)
+	} else if !wasProcessed(expanded) {
+		s.callHooks(func(h *Hook) {
+			if h.BeforeEval != nil {
+				h.BeforeEval(expanded)
+			}
+		})
+		start := time.Now()
+		result = s.Eval(expanded)
+		elapsed := time.Since(start)
+		if show {
+			s.showResult(expanded, result)
+		}
+		s.afterEvalHooks(result, elapsed)
+	}
+	s.Transcript[entry].Output = output.String()
+	s.Transcript[entry].Result = result
+	return result
+}
+
+// EvalError is an error found parsing, checking or evaluating an
+// expression.
+type EvalError struct {
+	// Kind is "parse", "check", "eval", "read-only", "sandbox" or
+	// "limit".
+	Kind   string
+	Msg    string
+	Line   int // position of the error in the expression, if known
+	Column int
+	err    error
+}
+
+func (e *EvalError) Error() string {
+	return e.Msg
+}
+
+// errPos matches the position at the start of an error message.
+var errPos = regexp.MustCompile(`^(\d+):(\d+): `)
+
+// newEvalError makes an EvalError of kind "kind" from err, picking
+// out any position at the start of its message.
+func newEvalError(kind string, err error) *EvalError {
+	e := &EvalError{Kind: kind, Msg: err.Error(), err: err}
+	if m := errPos.FindStringSubmatch(e.Msg); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Column, _ = strconv.Atoi(m[2])
+	}
+	return e
+}
+
+// EvalResult is the outcome of evaluating an expression.
+type EvalResult struct {
+	// Values are the values of the expression; nil if there are
+	// none, empty for a void function call.
+	Values []reflect.Value
+
+	// ResultIndex is the index of the value in the session's
+	// Results, or -1 if it wasn't saved.
+	ResultIndex int
+
+	Errors []*EvalError
+}
+
+// Eval parses, type checks and evaluates expression line, saving its
+// value in s.Results. Nothing is shown. Use this from within
+// commands or hooks, where the session is already running; otherwise
+// see Capture.
+func (s *Session) Eval(line string) *EvalResult {
+	result := &EvalResult{ResultIndex: -1}
+	ctx := &eval.Ctx{line}
+	if expr, err := parser.ParseExpr(line); err != nil {
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			for _, perr := range list {
+				result.Errors = append(result.Errors, &EvalError{
+					Kind: "parse", Msg: perr.Error(), err: err,
+					Line: perr.Pos.Line, Column: perr.Pos.Column})
+			}
+		} else {
+			result.Errors = append(result.Errors, newEvalError("parse", err))
+		}
+	} else if err := s.CheckAllowed(expr); err != nil {
+		kind := "read-only"
+		if _, ok := err.(*SandboxError); ok {
+			kind = "sandbox"
+		}
+		result.Errors = append(result.Errors, newEvalError(kind, err))
+	} else if cexpr, errs := eval.CheckExpr(ctx, expr, s.Env); len(errs) != 0 {
+		for _, cerr := range errs {
+			result.Errors = append(result.Errors, newEvalError("check", cerr))
+		}
+	} else if vals, err := s.evalLimited(ctx, cexpr); err != nil {
+		kind := "eval"
+		if _, ok := err.(*LimitError); ok {
+			kind = "limit"
+		}
+		result.Errors = append(result.Errors, newEvalError(kind, err))
+	} else if vals != nil {
+		result.Values = *vals
+		if result.Values == nil {
+			result.Values = []reflect.Value{}
+		}
+		if len(*vals) == 1 {
+			if value := (*vals)[0]; value.IsValid() {
+				result.ResultIndex = len(s.Results)
+				s.Results = append(s.Results, value.Interface())
+			}
+		} else if len(*vals) > 1 {
+			result.ResultIndex = len(s.Results)
+			s.Results = append(s.Results, (*vals))
+		}
+	}
+	return result
+}
+
+// evalLimited evaluates cexpr within the session's Limits.
+func (s *Session) evalLimited(ctx *eval.Ctx, cexpr eval.Expr) (*[]reflect.Value, error) {
+	// An evaluation that goes over a limit goes on in the background,
+	// so it hands its values over rather than setting them here.
+	env := s.Env
+	values := make(chan *[]reflect.Value, 1)
+	err := s.WithLimits(func() error {
+		vals, _, err := eval.EvalExpr(ctx, cexpr, env)
+		values <- vals
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return <-values, nil
+}
+
+// showResult shows the outcome of evaluating line.
+func (s *Session) showResult(line string, result *EvalResult) {
+	if len(result.Errors) > 0 {
+		for _, e := range result.Errors {
+			switch e.Kind {
+			case "parse":
+				if pair := eval.FormatErrorPos(line, e.err.Error()); len(pair) == 2 {
+					Msg(pair[0])
+					Msg(pair[1])
+				}
+				Errmsg("parse error: %s", e.err)
+				return
+			case "eval":
+				Errmsg("eval error: %s", e.Msg)
+			default:
+				Errmsg("%s", e.Msg)
+			}
+		}
+		return
+	}
+	vals := result.Values
+	if vals == nil {
+		Msg("Kind=nil\nnil")
+	} else if len(vals) == 0 {
+		Msg("Kind=Slice\nvoid")
+	} else if len(vals) == 1 {
+		value := vals[0]
+		if value.IsValid() {
+			kind := value.Kind().String()
+			typ  := value.Type().String()
+			if typ != kind {
+				Msg("Kind = %v", kind)
+				Msg("Type = %v", typ)
+			} else {
+				Msg("Kind = Type = %v", kind)
+			}
+			Msg("results[%d] = %s", result.ResultIndex, s.inspect(value))
+		} else {
+			Msg("%s", value)
+		}
+	} else {
+		Msg("Kind = Multi-Value")
+		size := len(vals)
+		for i, v := range vals {
+			MsgNoCr("%s", s.inspect(v))
+			if i < size-1 { MsgNoCr(", ") }
+		}
+		Msg("")
+	}
+}
@@ -0,0 +1,56 @@
+package repl_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+)
+
+func TestSessionsIndependent(t *testing.T) {
+	// The slow session's evaluation waits until it is released.
+	slow := repl.NewSession(nil, nil, nil)
+	var slowOut bytes.Buffer
+	slow.Out = &slowOut
+	waiting, release := make(chan bool), make(chan bool)
+	err := slow.AddHook(&repl.Hook{Name: "wait", OnInput: func(line string) string {
+		slow.WithLimits(func() error {
+			waiting <- true
+			<-release
+			return nil
+		})
+		return line
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan bool)
+	go func() {
+		slow.ProcessLine("history")
+		done <- true
+	}()
+	<-waiting
+
+	s := repl.NewSession(nil, nil, nil)
+	output := make(chan string)
+	go func() { output <- s.Capture("history", true).Output }()
+	select {
+	case out := <-output:
+		if !strings.Contains(out, "history") {
+			t.Errorf("expecting history in output; got %q", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("a slow evaluation held up another session")
+	}
+	close(release)
+	<-done
+	// Once released, the slow session's command writes to its own
+	// output.
+	if !strings.Contains(slowOut.String(), "history") {
+		t.Errorf("expecting history in the slow session's output; got %q",
+			slowOut.String())
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2014 Rocky Bernstein.
+// Settings shown by "show" and changed by "set"
+
+package repl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Setting is something that can be shown with the "show" command and
+// changed with "set". Get and Set work on the current session, if the
+// setting is one a session has its own value of.
+type Setting struct {
+	// Help is a one-line description.
+	Help string
+
+	Get func() string
+
+	// Set changes the setting to value, or returns an error saying
+	// what is expected.
+	Set func(value string) error
+}
+
+// Settings are the settings by name.
+var Settings = make(map[string]*Setting)
+
+// sessionSettings are values of the package variables CmdPrefix,
+// LegacyCommands, Highlight and Maxwidth. Outside of sessions they are
+// what all sessions start with; a session that changes one with "set"
+// gets values of its own, which are put in place while it runs.
+type sessionSettings struct {
+	cmdPrefix      string
+	legacyCommands bool
+	highlight      bool
+	maxwidth       int
+}
+
+func currentSettings() sessionSettings {
+	return sessionSettings{CmdPrefix, LegacyCommands, *Highlight, Maxwidth}
+}
+
+func (v sessionSettings) apply() {
+	CmdPrefix, LegacyCommands, *Highlight, Maxwidth = v.cmdPrefix,
+		v.legacyCommands, v.highlight, v.maxwidth
+}
+
+// ownSettings gives the current session, if any, settings of its own,
+// so that changing CmdPrefix, LegacyCommands, Highlight or Maxwidth
+// changes them for it alone.
+func ownSettings() {
+	if Current != nil && Current.settings == nil {
+		v := currentSettings()
+		Current.settings = &v
+	}
+}
+
+// SettingNames returns the names of the settings in order.
+func SettingNames() []string {
+	names := []string{}
+	for name := range Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseOnOff parses "on" or "off", or the other ways strconv.ParseBool
+// accepts of saying true or false.
+func ParseOnOff(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("expecting on or off; got %s", value)
+	}
+	return b, nil
+}
+
+// OnOff shows b as "on" or "off".
+func OnOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func init() {
+	Settings["highlight"] = &Setting{
+		Help: "use syntax highlighting in output",
+		Get:  func() string { return OnOff(*Highlight) },
+		Set: func(value string) error {
+			b, err := ParseOnOff(value)
+			if err == nil {
+				ownSettings()
+				*Highlight = b
+			}
+			return err
+		},
+	}
+	Settings["width"] = &Setting{
+		Help: "the width of a line; longer text is wrapped",
+		Get:  func() string { return strconv.Itoa(Maxwidth) },
+		Set: func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 10 {
+				return fmt.Errorf("expecting a width of at least 10; got %s", value)
+			}
+			ownSettings()
+			Maxwidth = n
+			return nil
+		},
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2014 Rocky Bernstein.
+// Go-like signatures from reflect types
+
+package repl
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FuncSignature returns a Go declaration-like signature for a
+// function named "name" of type t, e.g.
+//    func Fields(string) []string
+// reflect doesn't record parameter names so those are omitted.
+func FuncSignature(name string, t reflect.Type) string {
+	return "func " + name + funcTypeString(t, 0)
+}
+
+// MethodSignature returns a Go declaration-like signature for method
+// m of type recv, e.g.
+//    func (*strings.Reader) Len() int
+func MethodSignature(recv reflect.Type, m reflect.Method) string {
+	skip := 1 // Method types of non-interface types include the receiver.
+	if recv.Kind() == reflect.Interface {
+		skip = 0
+	}
+	return "func (" + recv.String() + ") " + m.Name + funcTypeString(m.Type, skip)
+}
+
+// funcTypeString gives the parameter and result part of function
+// type t, skipping the first "skip" parameters.
+func funcTypeString(t reflect.Type, skip int) string {
+	params := []string{}
+	for i := skip; i < t.NumIn(); i++ {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			params = append(params, "..."+t.In(i).Elem().String())
+		} else {
+			params = append(params, t.In(i).String())
+		}
+	}
+	s := "(" + strings.Join(params, ", ") + ")"
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		s += " " + t.Out(0).String()
+	default:
+		results := []string{}
+		for i := 0; i < t.NumOut(); i++ {
+			results = append(results, t.Out(i).String())
+		}
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
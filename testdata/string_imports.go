@@ -29,8 +29,8 @@ import (
 
 type pkgType map[string] eval.Pkg
 
-// EvalEnvironment adds to eval.Pkg those packages included
-// with import "strings".
+// EvalEnvironment adds to pkgs, keyed by import path, those packages
+// included with import "strings".
 
 func EvalEnvironment(pkgs pkgType) {
 	var consts map[string] reflect.Value
@@ -54,11 +54,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["ScanWords"] = reflect.ValueOf(bufio.ScanWords)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(bufio.Reader))
-	types["Writer"] = reflect.TypeOf(*new(bufio.Writer))
-	types["ReadWriter"] = reflect.TypeOf(*new(bufio.ReadWriter))
-	types["Scanner"] = reflect.TypeOf(*new(bufio.Scanner))
-	types["SplitFunc"] = reflect.TypeOf(*new(bufio.SplitFunc))
+	types["Reader"] = reflect.TypeOf(new(bufio.Reader)).Elem()
+	types["Writer"] = reflect.TypeOf(new(bufio.Writer)).Elem()
+	types["ReadWriter"] = reflect.TypeOf(new(bufio.ReadWriter)).Elem()
+	types["Scanner"] = reflect.TypeOf(new(bufio.Scanner)).Elem()
+	types["SplitFunc"] = reflect.TypeOf(new(bufio.SplitFunc)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrInvalidUnreadByte"] = reflect.ValueOf(&bufio.ErrInvalidUnreadByte)
@@ -128,8 +128,8 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewReader"] = reflect.ValueOf(bytes.NewReader)
 
 	types = make(map[string] reflect.Type)
-	types["Buffer"] = reflect.TypeOf(*new(bytes.Buffer))
-	types["Reader"] = reflect.TypeOf(*new(bytes.Reader))
+	types["Buffer"] = reflect.TypeOf(new(bytes.Buffer)).Elem()
+	types["Reader"] = reflect.TypeOf(new(bytes.Reader)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrTooLarge"] = reflect.ValueOf(&bytes.ErrTooLarge)
@@ -196,11 +196,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewFlagSet"] = reflect.ValueOf(flag.NewFlagSet)
 
 	types = make(map[string] reflect.Type)
-	types["Value"] = reflect.TypeOf(*new(flag.Value))
-	types["Getter"] = reflect.TypeOf(*new(flag.Getter))
-	types["ErrorHandling"] = reflect.TypeOf(*new(flag.ErrorHandling))
-	types["FlagSet"] = reflect.TypeOf(*new(flag.FlagSet))
-	types["Flag"] = reflect.TypeOf(*new(flag.Flag))
+	types["Value"] = reflect.TypeOf(new(flag.Value)).Elem()
+	types["Getter"] = reflect.TypeOf(new(flag.Getter)).Elem()
+	types["ErrorHandling"] = reflect.TypeOf(new(flag.ErrorHandling)).Elem()
+	types["FlagSet"] = reflect.TypeOf(new(flag.FlagSet)).Elem()
+	types["Flag"] = reflect.TypeOf(new(flag.Flag)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrHelp"] = reflect.ValueOf(&flag.ErrHelp)
@@ -239,12 +239,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Fscanf"] = reflect.ValueOf(fmt.Fscanf)
 
 	types = make(map[string] reflect.Type)
-	types["State"] = reflect.TypeOf(*new(fmt.State))
-	types["Formatter"] = reflect.TypeOf(*new(fmt.Formatter))
-	types["Stringer"] = reflect.TypeOf(*new(fmt.Stringer))
-	types["GoStringer"] = reflect.TypeOf(*new(fmt.GoStringer))
-	types["ScanState"] = reflect.TypeOf(*new(fmt.ScanState))
-	types["Scanner"] = reflect.TypeOf(*new(fmt.Scanner))
+	types["State"] = reflect.TypeOf(new(fmt.State)).Elem()
+	types["Formatter"] = reflect.TypeOf(new(fmt.Formatter)).Elem()
+	types["Stringer"] = reflect.TypeOf(new(fmt.Stringer)).Elem()
+	types["GoStringer"] = reflect.TypeOf(new(fmt.GoStringer)).Elem()
+	types["ScanState"] = reflect.TypeOf(new(fmt.ScanState)).Elem()
+	types["Scanner"] = reflect.TypeOf(new(fmt.Scanner)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["fmt"] = &eval.Env {
@@ -272,30 +272,30 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Pipe"] = reflect.ValueOf(io.Pipe)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(io.Reader))
-	types["Writer"] = reflect.TypeOf(*new(io.Writer))
-	types["Closer"] = reflect.TypeOf(*new(io.Closer))
-	types["Seeker"] = reflect.TypeOf(*new(io.Seeker))
-	types["ReadWriter"] = reflect.TypeOf(*new(io.ReadWriter))
-	types["ReadCloser"] = reflect.TypeOf(*new(io.ReadCloser))
-	types["WriteCloser"] = reflect.TypeOf(*new(io.WriteCloser))
-	types["ReadWriteCloser"] = reflect.TypeOf(*new(io.ReadWriteCloser))
-	types["ReadSeeker"] = reflect.TypeOf(*new(io.ReadSeeker))
-	types["WriteSeeker"] = reflect.TypeOf(*new(io.WriteSeeker))
-	types["ReadWriteSeeker"] = reflect.TypeOf(*new(io.ReadWriteSeeker))
-	types["ReaderFrom"] = reflect.TypeOf(*new(io.ReaderFrom))
-	types["WriterTo"] = reflect.TypeOf(*new(io.WriterTo))
-	types["ReaderAt"] = reflect.TypeOf(*new(io.ReaderAt))
-	types["WriterAt"] = reflect.TypeOf(*new(io.WriterAt))
-	types["ByteReader"] = reflect.TypeOf(*new(io.ByteReader))
-	types["ByteScanner"] = reflect.TypeOf(*new(io.ByteScanner))
-	types["ByteWriter"] = reflect.TypeOf(*new(io.ByteWriter))
-	types["RuneReader"] = reflect.TypeOf(*new(io.RuneReader))
-	types["RuneScanner"] = reflect.TypeOf(*new(io.RuneScanner))
-	types["LimitedReader"] = reflect.TypeOf(*new(io.LimitedReader))
-	types["SectionReader"] = reflect.TypeOf(*new(io.SectionReader))
-	types["PipeReader"] = reflect.TypeOf(*new(io.PipeReader))
-	types["PipeWriter"] = reflect.TypeOf(*new(io.PipeWriter))
+	types["Reader"] = reflect.TypeOf(new(io.Reader)).Elem()
+	types["Writer"] = reflect.TypeOf(new(io.Writer)).Elem()
+	types["Closer"] = reflect.TypeOf(new(io.Closer)).Elem()
+	types["Seeker"] = reflect.TypeOf(new(io.Seeker)).Elem()
+	types["ReadWriter"] = reflect.TypeOf(new(io.ReadWriter)).Elem()
+	types["ReadCloser"] = reflect.TypeOf(new(io.ReadCloser)).Elem()
+	types["WriteCloser"] = reflect.TypeOf(new(io.WriteCloser)).Elem()
+	types["ReadWriteCloser"] = reflect.TypeOf(new(io.ReadWriteCloser)).Elem()
+	types["ReadSeeker"] = reflect.TypeOf(new(io.ReadSeeker)).Elem()
+	types["WriteSeeker"] = reflect.TypeOf(new(io.WriteSeeker)).Elem()
+	types["ReadWriteSeeker"] = reflect.TypeOf(new(io.ReadWriteSeeker)).Elem()
+	types["ReaderFrom"] = reflect.TypeOf(new(io.ReaderFrom)).Elem()
+	types["WriterTo"] = reflect.TypeOf(new(io.WriterTo)).Elem()
+	types["ReaderAt"] = reflect.TypeOf(new(io.ReaderAt)).Elem()
+	types["WriterAt"] = reflect.TypeOf(new(io.WriterAt)).Elem()
+	types["ByteReader"] = reflect.TypeOf(new(io.ByteReader)).Elem()
+	types["ByteScanner"] = reflect.TypeOf(new(io.ByteScanner)).Elem()
+	types["ByteWriter"] = reflect.TypeOf(new(io.ByteWriter)).Elem()
+	types["RuneReader"] = reflect.TypeOf(new(io.RuneReader)).Elem()
+	types["RuneScanner"] = reflect.TypeOf(new(io.RuneScanner)).Elem()
+	types["LimitedReader"] = reflect.TypeOf(new(io.LimitedReader)).Elem()
+	types["SectionReader"] = reflect.TypeOf(new(io.SectionReader)).Elem()
+	types["PipeReader"] = reflect.TypeOf(new(io.PipeReader)).Elem()
+	types["PipeWriter"] = reflect.TypeOf(new(io.PipeWriter)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrShortWrite"] = reflect.ValueOf(&io.ErrShortWrite)
@@ -438,12 +438,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewZipf"] = reflect.ValueOf(rand.NewZipf)
 
 	types = make(map[string] reflect.Type)
-	types["Source"] = reflect.TypeOf(*new(rand.Source))
-	types["Rand"] = reflect.TypeOf(*new(rand.Rand))
-	types["Zipf"] = reflect.TypeOf(*new(rand.Zipf))
+	types["Source"] = reflect.TypeOf(new(rand.Source)).Elem()
+	types["Rand"] = reflect.TypeOf(new(rand.Rand)).Elem()
+	types["Zipf"] = reflect.TypeOf(new(rand.Zipf)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["rand"] = &eval.Env {
+	pkgs["math/rand"] = &eval.Env {
 		Name: "rand",
 		Consts: consts,
 		Funcs:  funcs,
@@ -532,16 +532,16 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["SameFile"] = reflect.ValueOf(os.SameFile)
 
 	types = make(map[string] reflect.Type)
-	types["PathError"] = reflect.TypeOf(*new(os.PathError))
-	types["SyscallError"] = reflect.TypeOf(*new(os.SyscallError))
-	types["Process"] = reflect.TypeOf(*new(os.Process))
-	types["ProcAttr"] = reflect.TypeOf(*new(os.ProcAttr))
-	types["Signal"] = reflect.TypeOf(*new(os.Signal))
-	types["ProcessState"] = reflect.TypeOf(*new(os.ProcessState))
-	types["LinkError"] = reflect.TypeOf(*new(os.LinkError))
-	types["File"] = reflect.TypeOf(*new(os.File))
-	types["FileInfo"] = reflect.TypeOf(*new(os.FileInfo))
-	types["FileMode"] = reflect.TypeOf(*new(os.FileMode))
+	types["PathError"] = reflect.TypeOf(new(os.PathError)).Elem()
+	types["SyscallError"] = reflect.TypeOf(new(os.SyscallError)).Elem()
+	types["Process"] = reflect.TypeOf(new(os.Process)).Elem()
+	types["ProcAttr"] = reflect.TypeOf(new(os.ProcAttr)).Elem()
+	types["Signal"] = reflect.TypeOf(new(os.Signal)).Elem()
+	types["ProcessState"] = reflect.TypeOf(new(os.ProcessState)).Elem()
+	types["LinkError"] = reflect.TypeOf(new(os.LinkError)).Elem()
+	types["File"] = reflect.TypeOf(new(os.File)).Elem()
+	types["FileInfo"] = reflect.TypeOf(new(os.FileInfo)).Elem()
+	types["FileMode"] = reflect.TypeOf(new(os.FileMode)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrInvalid"] = reflect.ValueOf(&os.ErrInvalid)
@@ -620,18 +620,18 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewAt"] = reflect.ValueOf(reflect.NewAt)
 
 	types = make(map[string] reflect.Type)
-	types["Type"] = reflect.TypeOf(*new(reflect.Type))
-	types["Kind"] = reflect.TypeOf(*new(reflect.Kind))
-	types["ChanDir"] = reflect.TypeOf(*new(reflect.ChanDir))
-	types["Method"] = reflect.TypeOf(*new(reflect.Method))
-	types["StructField"] = reflect.TypeOf(*new(reflect.StructField))
-	types["StructTag"] = reflect.TypeOf(*new(reflect.StructTag))
-	types["Value"] = reflect.TypeOf(*new(reflect.Value))
-	types["ValueError"] = reflect.TypeOf(*new(reflect.ValueError))
-	types["StringHeader"] = reflect.TypeOf(*new(reflect.StringHeader))
-	types["SliceHeader"] = reflect.TypeOf(*new(reflect.SliceHeader))
-	types["SelectDir"] = reflect.TypeOf(*new(reflect.SelectDir))
-	types["SelectCase"] = reflect.TypeOf(*new(reflect.SelectCase))
+	types["Type"] = reflect.TypeOf(new(reflect.Type)).Elem()
+	types["Kind"] = reflect.TypeOf(new(reflect.Kind)).Elem()
+	types["ChanDir"] = reflect.TypeOf(new(reflect.ChanDir)).Elem()
+	types["Method"] = reflect.TypeOf(new(reflect.Method)).Elem()
+	types["StructField"] = reflect.TypeOf(new(reflect.StructField)).Elem()
+	types["StructTag"] = reflect.TypeOf(new(reflect.StructTag)).Elem()
+	types["Value"] = reflect.TypeOf(new(reflect.Value)).Elem()
+	types["ValueError"] = reflect.TypeOf(new(reflect.ValueError)).Elem()
+	types["StringHeader"] = reflect.TypeOf(new(reflect.StringHeader)).Elem()
+	types["SliceHeader"] = reflect.TypeOf(new(reflect.SliceHeader)).Elem()
+	types["SelectDir"] = reflect.TypeOf(new(reflect.SelectDir)).Elem()
+	types["SelectCase"] = reflect.TypeOf(new(reflect.SelectCase)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["reflect"] = &eval.Env {
@@ -676,13 +676,13 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["GC"] = reflect.ValueOf(runtime.GC)
 
 	types = make(map[string] reflect.Type)
-	types["MemProfileRecord"] = reflect.TypeOf(*new(runtime.MemProfileRecord))
-	types["StackRecord"] = reflect.TypeOf(*new(runtime.StackRecord))
-	types["BlockProfileRecord"] = reflect.TypeOf(*new(runtime.BlockProfileRecord))
-	types["Error"] = reflect.TypeOf(*new(runtime.Error))
-	types["TypeAssertionError"] = reflect.TypeOf(*new(runtime.TypeAssertionError))
-	types["Func"] = reflect.TypeOf(*new(runtime.Func))
-	types["MemStats"] = reflect.TypeOf(*new(runtime.MemStats))
+	types["MemProfileRecord"] = reflect.TypeOf(new(runtime.MemProfileRecord)).Elem()
+	types["StackRecord"] = reflect.TypeOf(new(runtime.StackRecord)).Elem()
+	types["BlockProfileRecord"] = reflect.TypeOf(new(runtime.BlockProfileRecord)).Elem()
+	types["Error"] = reflect.TypeOf(new(runtime.Error)).Elem()
+	types["TypeAssertionError"] = reflect.TypeOf(new(runtime.TypeAssertionError)).Elem()
+	types["Func"] = reflect.TypeOf(new(runtime.Func)).Elem()
+	types["MemStats"] = reflect.TypeOf(new(runtime.MemStats)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["MemProfileRate"] = reflect.ValueOf(&runtime.MemProfileRate)
@@ -706,10 +706,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["StopCPUProfile"] = reflect.ValueOf(pprof.StopCPUProfile)
 
 	types = make(map[string] reflect.Type)
-	types["Profile"] = reflect.TypeOf(*new(pprof.Profile))
+	types["Profile"] = reflect.TypeOf(new(pprof.Profile)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["pprof"] = &eval.Env {
+	pkgs["runtime/pprof"] = &eval.Env {
 		Name: "pprof",
 		Consts: consts,
 		Funcs:  funcs,
@@ -737,10 +737,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Stable"] = reflect.ValueOf(sort.Stable)
 
 	types = make(map[string] reflect.Type)
-	types["Interface"] = reflect.TypeOf(*new(sort.Interface))
-	types["IntSlice"] = reflect.TypeOf(*new(sort.IntSlice))
-	types["Float64Slice"] = reflect.TypeOf(*new(sort.Float64Slice))
-	types["StringSlice"] = reflect.TypeOf(*new(sort.StringSlice))
+	types["Interface"] = reflect.TypeOf(new(sort.Interface)).Elem()
+	types["IntSlice"] = reflect.TypeOf(new(sort.IntSlice)).Elem()
+	types["Float64Slice"] = reflect.TypeOf(new(sort.Float64Slice)).Elem()
+	types["StringSlice"] = reflect.TypeOf(new(sort.StringSlice)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["sort"] = &eval.Env {
@@ -784,7 +784,7 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["IsPrint"] = reflect.ValueOf(strconv.IsPrint)
 
 	types = make(map[string] reflect.Type)
-	types["NumError"] = reflect.TypeOf(*new(strconv.NumError))
+	types["NumError"] = reflect.TypeOf(new(strconv.NumError)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ErrRange"] = reflect.ValueOf(&strconv.ErrRange)
@@ -846,8 +846,8 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["IndexByte"] = reflect.ValueOf(strings.IndexByte)
 
 	types = make(map[string] reflect.Type)
-	types["Reader"] = reflect.TypeOf(*new(strings.Reader))
-	types["Replacer"] = reflect.TypeOf(*new(strings.Replacer))
+	types["Reader"] = reflect.TypeOf(new(strings.Reader)).Elem()
+	types["Replacer"] = reflect.TypeOf(new(strings.Replacer)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["strings"] = &eval.Env {
@@ -865,12 +865,12 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewCond"] = reflect.ValueOf(sync.NewCond)
 
 	types = make(map[string] reflect.Type)
-	types["Cond"] = reflect.TypeOf(*new(sync.Cond))
-	types["Mutex"] = reflect.TypeOf(*new(sync.Mutex))
-	types["Locker"] = reflect.TypeOf(*new(sync.Locker))
-	types["Once"] = reflect.TypeOf(*new(sync.Once))
-	types["RWMutex"] = reflect.TypeOf(*new(sync.RWMutex))
-	types["WaitGroup"] = reflect.TypeOf(*new(sync.WaitGroup))
+	types["Cond"] = reflect.TypeOf(new(sync.Cond)).Elem()
+	types["Mutex"] = reflect.TypeOf(new(sync.Mutex)).Elem()
+	types["Locker"] = reflect.TypeOf(new(sync.Locker)).Elem()
+	types["Once"] = reflect.TypeOf(new(sync.Once)).Elem()
+	types["RWMutex"] = reflect.TypeOf(new(sync.RWMutex)).Elem()
+	types["WaitGroup"] = reflect.TypeOf(new(sync.WaitGroup)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["sync"] = &eval.Env {
@@ -918,7 +918,7 @@ func EvalEnvironment(pkgs pkgType) {
 	types = make(map[string] reflect.Type)
 
 	vars = make(map[string] reflect.Value)
-	pkgs["atomic"] = &eval.Env {
+	pkgs["sync/atomic"] = &eval.Env {
 		Name: "atomic",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1157,73 +1157,73 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["Time"] = reflect.ValueOf(syscall.Time)
 
 	types = make(map[string] reflect.Type)
-	types["SysProcAttr"] = reflect.TypeOf(*new(syscall.SysProcAttr))
-	types["Credential"] = reflect.TypeOf(*new(syscall.Credential))
-	types["ProcAttr"] = reflect.TypeOf(*new(syscall.ProcAttr))
-	types["NetlinkRouteRequest"] = reflect.TypeOf(*new(syscall.NetlinkRouteRequest))
-	types["NetlinkMessage"] = reflect.TypeOf(*new(syscall.NetlinkMessage))
-	types["NetlinkRouteAttr"] = reflect.TypeOf(*new(syscall.NetlinkRouteAttr))
-	types["SocketControlMessage"] = reflect.TypeOf(*new(syscall.SocketControlMessage))
-	types["WaitStatus"] = reflect.TypeOf(*new(syscall.WaitStatus))
-	types["SockaddrLinklayer"] = reflect.TypeOf(*new(syscall.SockaddrLinklayer))
-	types["SockaddrNetlink"] = reflect.TypeOf(*new(syscall.SockaddrNetlink))
-	types["Errno"] = reflect.TypeOf(*new(syscall.Errno))
-	types["Signal"] = reflect.TypeOf(*new(syscall.Signal))
-	types["Sockaddr"] = reflect.TypeOf(*new(syscall.Sockaddr))
-	types["SockaddrInet4"] = reflect.TypeOf(*new(syscall.SockaddrInet4))
-	types["SockaddrInet6"] = reflect.TypeOf(*new(syscall.SockaddrInet6))
-	types["SockaddrUnix"] = reflect.TypeOf(*new(syscall.SockaddrUnix))
-	types["Timespec"] = reflect.TypeOf(*new(syscall.Timespec))
-	types["Timeval"] = reflect.TypeOf(*new(syscall.Timeval))
-	types["Timex"] = reflect.TypeOf(*new(syscall.Timex))
-	types["Time_t"] = reflect.TypeOf(*new(syscall.Time_t))
-	types["Tms"] = reflect.TypeOf(*new(syscall.Tms))
-	types["Utimbuf"] = reflect.TypeOf(*new(syscall.Utimbuf))
-	types["Rusage"] = reflect.TypeOf(*new(syscall.Rusage))
-	types["Rlimit"] = reflect.TypeOf(*new(syscall.Rlimit))
-	types["Stat_t"] = reflect.TypeOf(*new(syscall.Stat_t))
-	types["Statfs_t"] = reflect.TypeOf(*new(syscall.Statfs_t))
-	types["Dirent"] = reflect.TypeOf(*new(syscall.Dirent))
-	types["Fsid"] = reflect.TypeOf(*new(syscall.Fsid))
-	types["RawSockaddrInet4"] = reflect.TypeOf(*new(syscall.RawSockaddrInet4))
-	types["RawSockaddrInet6"] = reflect.TypeOf(*new(syscall.RawSockaddrInet6))
-	types["RawSockaddrUnix"] = reflect.TypeOf(*new(syscall.RawSockaddrUnix))
-	types["RawSockaddrLinklayer"] = reflect.TypeOf(*new(syscall.RawSockaddrLinklayer))
-	types["RawSockaddrNetlink"] = reflect.TypeOf(*new(syscall.RawSockaddrNetlink))
-	types["RawSockaddr"] = reflect.TypeOf(*new(syscall.RawSockaddr))
-	types["RawSockaddrAny"] = reflect.TypeOf(*new(syscall.RawSockaddrAny))
-	types["Linger"] = reflect.TypeOf(*new(syscall.Linger))
-	types["Iovec"] = reflect.TypeOf(*new(syscall.Iovec))
-	types["IPMreq"] = reflect.TypeOf(*new(syscall.IPMreq))
-	types["IPMreqn"] = reflect.TypeOf(*new(syscall.IPMreqn))
-	types["IPv6Mreq"] = reflect.TypeOf(*new(syscall.IPv6Mreq))
-	types["Msghdr"] = reflect.TypeOf(*new(syscall.Msghdr))
-	types["Cmsghdr"] = reflect.TypeOf(*new(syscall.Cmsghdr))
-	types["Inet4Pktinfo"] = reflect.TypeOf(*new(syscall.Inet4Pktinfo))
-	types["Inet6Pktinfo"] = reflect.TypeOf(*new(syscall.Inet6Pktinfo))
-	types["IPv6MTUInfo"] = reflect.TypeOf(*new(syscall.IPv6MTUInfo))
-	types["ICMPv6Filter"] = reflect.TypeOf(*new(syscall.ICMPv6Filter))
-	types["Ucred"] = reflect.TypeOf(*new(syscall.Ucred))
-	types["TCPInfo"] = reflect.TypeOf(*new(syscall.TCPInfo))
-	types["NlMsghdr"] = reflect.TypeOf(*new(syscall.NlMsghdr))
-	types["NlMsgerr"] = reflect.TypeOf(*new(syscall.NlMsgerr))
-	types["RtGenmsg"] = reflect.TypeOf(*new(syscall.RtGenmsg))
-	types["NlAttr"] = reflect.TypeOf(*new(syscall.NlAttr))
-	types["RtAttr"] = reflect.TypeOf(*new(syscall.RtAttr))
-	types["IfInfomsg"] = reflect.TypeOf(*new(syscall.IfInfomsg))
-	types["IfAddrmsg"] = reflect.TypeOf(*new(syscall.IfAddrmsg))
-	types["RtMsg"] = reflect.TypeOf(*new(syscall.RtMsg))
-	types["RtNexthop"] = reflect.TypeOf(*new(syscall.RtNexthop))
-	types["SockFilter"] = reflect.TypeOf(*new(syscall.SockFilter))
-	types["SockFprog"] = reflect.TypeOf(*new(syscall.SockFprog))
-	types["InotifyEvent"] = reflect.TypeOf(*new(syscall.InotifyEvent))
-	types["PtraceRegs"] = reflect.TypeOf(*new(syscall.PtraceRegs))
-	types["FdSet"] = reflect.TypeOf(*new(syscall.FdSet))
-	types["Sysinfo_t"] = reflect.TypeOf(*new(syscall.Sysinfo_t))
-	types["Utsname"] = reflect.TypeOf(*new(syscall.Utsname))
-	types["Ustat_t"] = reflect.TypeOf(*new(syscall.Ustat_t))
-	types["EpollEvent"] = reflect.TypeOf(*new(syscall.EpollEvent))
-	types["Termios"] = reflect.TypeOf(*new(syscall.Termios))
+	types["SysProcAttr"] = reflect.TypeOf(new(syscall.SysProcAttr)).Elem()
+	types["Credential"] = reflect.TypeOf(new(syscall.Credential)).Elem()
+	types["ProcAttr"] = reflect.TypeOf(new(syscall.ProcAttr)).Elem()
+	types["NetlinkRouteRequest"] = reflect.TypeOf(new(syscall.NetlinkRouteRequest)).Elem()
+	types["NetlinkMessage"] = reflect.TypeOf(new(syscall.NetlinkMessage)).Elem()
+	types["NetlinkRouteAttr"] = reflect.TypeOf(new(syscall.NetlinkRouteAttr)).Elem()
+	types["SocketControlMessage"] = reflect.TypeOf(new(syscall.SocketControlMessage)).Elem()
+	types["WaitStatus"] = reflect.TypeOf(new(syscall.WaitStatus)).Elem()
+	types["SockaddrLinklayer"] = reflect.TypeOf(new(syscall.SockaddrLinklayer)).Elem()
+	types["SockaddrNetlink"] = reflect.TypeOf(new(syscall.SockaddrNetlink)).Elem()
+	types["Errno"] = reflect.TypeOf(new(syscall.Errno)).Elem()
+	types["Signal"] = reflect.TypeOf(new(syscall.Signal)).Elem()
+	types["Sockaddr"] = reflect.TypeOf(new(syscall.Sockaddr)).Elem()
+	types["SockaddrInet4"] = reflect.TypeOf(new(syscall.SockaddrInet4)).Elem()
+	types["SockaddrInet6"] = reflect.TypeOf(new(syscall.SockaddrInet6)).Elem()
+	types["SockaddrUnix"] = reflect.TypeOf(new(syscall.SockaddrUnix)).Elem()
+	types["Timespec"] = reflect.TypeOf(new(syscall.Timespec)).Elem()
+	types["Timeval"] = reflect.TypeOf(new(syscall.Timeval)).Elem()
+	types["Timex"] = reflect.TypeOf(new(syscall.Timex)).Elem()
+	types["Time_t"] = reflect.TypeOf(new(syscall.Time_t)).Elem()
+	types["Tms"] = reflect.TypeOf(new(syscall.Tms)).Elem()
+	types["Utimbuf"] = reflect.TypeOf(new(syscall.Utimbuf)).Elem()
+	types["Rusage"] = reflect.TypeOf(new(syscall.Rusage)).Elem()
+	types["Rlimit"] = reflect.TypeOf(new(syscall.Rlimit)).Elem()
+	types["Stat_t"] = reflect.TypeOf(new(syscall.Stat_t)).Elem()
+	types["Statfs_t"] = reflect.TypeOf(new(syscall.Statfs_t)).Elem()
+	types["Dirent"] = reflect.TypeOf(new(syscall.Dirent)).Elem()
+	types["Fsid"] = reflect.TypeOf(new(syscall.Fsid)).Elem()
+	types["RawSockaddrInet4"] = reflect.TypeOf(new(syscall.RawSockaddrInet4)).Elem()
+	types["RawSockaddrInet6"] = reflect.TypeOf(new(syscall.RawSockaddrInet6)).Elem()
+	types["RawSockaddrUnix"] = reflect.TypeOf(new(syscall.RawSockaddrUnix)).Elem()
+	types["RawSockaddrLinklayer"] = reflect.TypeOf(new(syscall.RawSockaddrLinklayer)).Elem()
+	types["RawSockaddrNetlink"] = reflect.TypeOf(new(syscall.RawSockaddrNetlink)).Elem()
+	types["RawSockaddr"] = reflect.TypeOf(new(syscall.RawSockaddr)).Elem()
+	types["RawSockaddrAny"] = reflect.TypeOf(new(syscall.RawSockaddrAny)).Elem()
+	types["Linger"] = reflect.TypeOf(new(syscall.Linger)).Elem()
+	types["Iovec"] = reflect.TypeOf(new(syscall.Iovec)).Elem()
+	types["IPMreq"] = reflect.TypeOf(new(syscall.IPMreq)).Elem()
+	types["IPMreqn"] = reflect.TypeOf(new(syscall.IPMreqn)).Elem()
+	types["IPv6Mreq"] = reflect.TypeOf(new(syscall.IPv6Mreq)).Elem()
+	types["Msghdr"] = reflect.TypeOf(new(syscall.Msghdr)).Elem()
+	types["Cmsghdr"] = reflect.TypeOf(new(syscall.Cmsghdr)).Elem()
+	types["Inet4Pktinfo"] = reflect.TypeOf(new(syscall.Inet4Pktinfo)).Elem()
+	types["Inet6Pktinfo"] = reflect.TypeOf(new(syscall.Inet6Pktinfo)).Elem()
+	types["IPv6MTUInfo"] = reflect.TypeOf(new(syscall.IPv6MTUInfo)).Elem()
+	types["ICMPv6Filter"] = reflect.TypeOf(new(syscall.ICMPv6Filter)).Elem()
+	types["Ucred"] = reflect.TypeOf(new(syscall.Ucred)).Elem()
+	types["TCPInfo"] = reflect.TypeOf(new(syscall.TCPInfo)).Elem()
+	types["NlMsghdr"] = reflect.TypeOf(new(syscall.NlMsghdr)).Elem()
+	types["NlMsgerr"] = reflect.TypeOf(new(syscall.NlMsgerr)).Elem()
+	types["RtGenmsg"] = reflect.TypeOf(new(syscall.RtGenmsg)).Elem()
+	types["NlAttr"] = reflect.TypeOf(new(syscall.NlAttr)).Elem()
+	types["RtAttr"] = reflect.TypeOf(new(syscall.RtAttr)).Elem()
+	types["IfInfomsg"] = reflect.TypeOf(new(syscall.IfInfomsg)).Elem()
+	types["IfAddrmsg"] = reflect.TypeOf(new(syscall.IfAddrmsg)).Elem()
+	types["RtMsg"] = reflect.TypeOf(new(syscall.RtMsg)).Elem()
+	types["RtNexthop"] = reflect.TypeOf(new(syscall.RtNexthop)).Elem()
+	types["SockFilter"] = reflect.TypeOf(new(syscall.SockFilter)).Elem()
+	types["SockFprog"] = reflect.TypeOf(new(syscall.SockFprog)).Elem()
+	types["InotifyEvent"] = reflect.TypeOf(new(syscall.InotifyEvent)).Elem()
+	types["PtraceRegs"] = reflect.TypeOf(new(syscall.PtraceRegs)).Elem()
+	types["FdSet"] = reflect.TypeOf(new(syscall.FdSet)).Elem()
+	types["Sysinfo_t"] = reflect.TypeOf(new(syscall.Sysinfo_t)).Elem()
+	types["Utsname"] = reflect.TypeOf(new(syscall.Utsname)).Elem()
+	types["Ustat_t"] = reflect.TypeOf(new(syscall.Ustat_t)).Elem()
+	types["EpollEvent"] = reflect.TypeOf(new(syscall.EpollEvent)).Elem()
+	types["Termios"] = reflect.TypeOf(new(syscall.Termios)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["ForkLock"] = reflect.ValueOf(&syscall.ForkLock)
@@ -1254,15 +1254,15 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["RunTests"] = reflect.ValueOf(testing.RunTests)
 
 	types = make(map[string] reflect.Type)
-	types["InternalBenchmark"] = reflect.TypeOf(*new(testing.InternalBenchmark))
-	types["B"] = reflect.TypeOf(*new(testing.B))
-	types["BenchmarkResult"] = reflect.TypeOf(*new(testing.BenchmarkResult))
-	types["CoverBlock"] = reflect.TypeOf(*new(testing.CoverBlock))
-	types["Cover"] = reflect.TypeOf(*new(testing.Cover))
-	types["InternalExample"] = reflect.TypeOf(*new(testing.InternalExample))
-	types["TB"] = reflect.TypeOf(*new(testing.TB))
-	types["T"] = reflect.TypeOf(*new(testing.T))
-	types["InternalTest"] = reflect.TypeOf(*new(testing.InternalTest))
+	types["InternalBenchmark"] = reflect.TypeOf(new(testing.InternalBenchmark)).Elem()
+	types["B"] = reflect.TypeOf(new(testing.B)).Elem()
+	types["BenchmarkResult"] = reflect.TypeOf(new(testing.BenchmarkResult)).Elem()
+	types["CoverBlock"] = reflect.TypeOf(new(testing.CoverBlock)).Elem()
+	types["Cover"] = reflect.TypeOf(new(testing.Cover)).Elem()
+	types["InternalExample"] = reflect.TypeOf(new(testing.InternalExample)).Elem()
+	types["TB"] = reflect.TypeOf(new(testing.TB)).Elem()
+	types["T"] = reflect.TypeOf(new(testing.T)).Elem()
+	types["InternalTest"] = reflect.TypeOf(new(testing.InternalTest)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	pkgs["testing"] = &eval.Env {
@@ -1287,10 +1287,10 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["NewWriter"] = reflect.ValueOf(tabwriter.NewWriter)
 
 	types = make(map[string] reflect.Type)
-	types["Writer"] = reflect.TypeOf(*new(tabwriter.Writer))
+	types["Writer"] = reflect.TypeOf(new(tabwriter.Writer)).Elem()
 
 	vars = make(map[string] reflect.Value)
-	pkgs["tabwriter"] = &eval.Env {
+	pkgs["text/tabwriter"] = &eval.Env {
 		Name: "tabwriter",
 		Consts: consts,
 		Funcs:  funcs,
@@ -1359,14 +1359,14 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["LoadLocation"] = reflect.ValueOf(time.LoadLocation)
 
 	types = make(map[string] reflect.Type)
-	types["ParseError"] = reflect.TypeOf(*new(time.ParseError))
-	types["Timer"] = reflect.TypeOf(*new(time.Timer))
-	types["Ticker"] = reflect.TypeOf(*new(time.Ticker))
-	types["Time"] = reflect.TypeOf(*new(time.Time))
-	types["Month"] = reflect.TypeOf(*new(time.Month))
-	types["Weekday"] = reflect.TypeOf(*new(time.Weekday))
-	types["Duration"] = reflect.TypeOf(*new(time.Duration))
-	types["Location"] = reflect.TypeOf(*new(time.Location))
+	types["ParseError"] = reflect.TypeOf(new(time.ParseError)).Elem()
+	types["Timer"] = reflect.TypeOf(new(time.Timer)).Elem()
+	types["Ticker"] = reflect.TypeOf(new(time.Ticker)).Elem()
+	types["Time"] = reflect.TypeOf(new(time.Time)).Elem()
+	types["Month"] = reflect.TypeOf(new(time.Month)).Elem()
+	types["Weekday"] = reflect.TypeOf(new(time.Weekday)).Elem()
+	types["Duration"] = reflect.TypeOf(new(time.Duration)).Elem()
+	types["Location"] = reflect.TypeOf(new(time.Location)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["UTC"] = reflect.ValueOf(&time.UTC)
@@ -1416,11 +1416,11 @@ func EvalEnvironment(pkgs pkgType) {
 	funcs["SimpleFold"] = reflect.ValueOf(unicode.SimpleFold)
 
 	types = make(map[string] reflect.Type)
-	types["RangeTable"] = reflect.TypeOf(*new(unicode.RangeTable))
-	types["Range16"] = reflect.TypeOf(*new(unicode.Range16))
-	types["Range32"] = reflect.TypeOf(*new(unicode.Range32))
-	types["CaseRange"] = reflect.TypeOf(*new(unicode.CaseRange))
-	types["SpecialCase"] = reflect.TypeOf(*new(unicode.SpecialCase))
+	types["RangeTable"] = reflect.TypeOf(new(unicode.RangeTable)).Elem()
+	types["Range16"] = reflect.TypeOf(new(unicode.Range16)).Elem()
+	types["Range32"] = reflect.TypeOf(new(unicode.Range32)).Elem()
+	types["CaseRange"] = reflect.TypeOf(new(unicode.CaseRange)).Elem()
+	types["SpecialCase"] = reflect.TypeOf(new(unicode.SpecialCase)).Elem()
 
 	vars = make(map[string] reflect.Value)
 	vars["TurkishCase"] = reflect.ValueOf(&unicode.TurkishCase)
@@ -1648,7 +1648,7 @@ func EvalEnvironment(pkgs pkgType) {
 	types = make(map[string] reflect.Type)
 
 	vars = make(map[string] reflect.Value)
-	pkgs["utf8"] = &eval.Env {
+	pkgs["unicode/utf8"] = &eval.Env {
 		Name: "utf8",
 		Consts: consts,
 		Funcs:  funcs,
@@ -0,0 +1,12 @@
+package repl_test
+
+import (
+	"testing"
+
+	_ "github.com/rocky/go-fish/cmd"
+	"github.com/rocky/go-fish/repltest"
+)
+
+func TestTranscripts(t *testing.T) {
+	repltest.Run(t, "testdata/transcripts/*.txt")
+}
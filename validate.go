@@ -3,7 +3,10 @@
 
 package repl
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+)
 
 func ArgCountOK(min int, max int, args [] string) bool {
 	l := len(args)-1 // strip command name from count
@@ -17,50 +20,52 @@ func ArgCountOK(min int, max int, args [] string) bool {
 	return true
 }
 
-type NumError struct {
-	bogus bool
-}
-
-func (e *NumError) Error() string {
-	return "generic error"
-}
-var genericError = &NumError{bogus: true}
-
+// GetInt converts arg, the value of "what", to an integer of at least
+// min and, if max > 0, at most max. If it can't, an *ArgError saying
+// why is returned for the caller to show.
 func GetInt(arg string, what string, min int, max int) (int, error) {
-	errmsg_fmt := "Expecting integer " + what + "; got '%s'."
 	i, err := strconv.Atoi(arg)
+	if err == nil {
+		err = checkRange(int64(i), what, int64(min), int64(max))
+	} else {
+		err = &ArgError{Arg: what, Value: arg, Reason: "expecting an integer"}
+	}
 	if err != nil {
-		Errmsg(errmsg_fmt, arg)
 		return 0, err
 	}
-	if i < min {
-		Errmsg("Expecting integer value %s to be at least %d; got %d.",
-			what, min, i)
-        return 0, genericError
-	} else if max > 0 && i > max {
-        Errmsg("Expecting integer value %s to be at most %d; got %d.",
-			what, max, i)
-        return 0, genericError
-	}
 	return i, nil
 }
 
-
+// GetUInt is GetInt for unsigned integers.
 func GetUInt(arg string, what string, min uint64, max uint64) (uint64, error) {
-	errmsg_fmt := "Expecting integer " + what + "; got '%s'."
 	i, err := strconv.ParseUint(arg, 10, 0)
 	if err != nil {
-		Errmsg(errmsg_fmt, arg)
+		err = &ArgError{Arg: what, Value: arg,
+			Reason: "expecting an unsigned integer"}
+	} else if i < min {
+		err = &ArgError{Arg: what, Value: arg,
+			Reason: fmt.Sprintf("expecting at least %d", min)}
+	} else if max > 0 && i > max {
+		err = &ArgError{Arg: what, Value: arg,
+			Reason: fmt.Sprintf("expecting at most %d", max)}
+	}
+	if err != nil {
 		return 0, err
 	}
+	return i, nil
+}
+
+// checkRange returns an *ArgError if i is less than min, or more than
+// max when max > 0.
+func checkRange(i int64, what string, min, max int64) error {
+	value := strconv.FormatInt(i, 10)
 	if i < min {
-		Errmsg("Expecting integer value %s to be at least %d; got %d.",
-			what, min, i)
-        return 0, genericError
-	} else if max > 0 && i > max {
-        Errmsg("Expecting integer value %s to be at most %d; got %d.",
-			what, max, i)
-        return 0, genericError
+		return &ArgError{Arg: what, Value: value,
+			Reason: fmt.Sprintf("expecting at least %d", min)}
 	}
-	return i, nil
+	if max > 0 && i > max {
+		return &ArgError{Arg: what, Value: value,
+			Reason: fmt.Sprintf("expecting at most %d", max)}
+	}
+	return nil
 }
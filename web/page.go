@@ -0,0 +1,161 @@
+// Copyright 2014 Rocky Bernstein.
+// The browser REPL page
+
+package web
+
+// page is the REPL page. Each input and its output is a block, which
+// can be collapsed by clicking on the input; blocks with long output
+// start out collapsed.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>go-fish</title>
+<style>
+body { font-family: monospace; margin: 1em; background: #fdfdf8; }
+#log pre { margin: 0 0 0 1.5em; white-space: pre-wrap; }
+#log details { margin: 0.2em 0; }
+#log summary { cursor: pointer; color: #204a87; }
+#log summary .count { color: #888; }
+.stderr { color: #a40000; }
+#line { display: flex; }
+#prompt { white-space: pre; color: #204a87; }
+#input { flex: 1; font: inherit; border: none; outline: none; background: transparent; }
+#hint { color: #888; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<div id="log"></div>
+<div id="line"><span id="prompt"></span><input id="input" autocomplete="off" spellcheck="false" disabled></div>
+<div id="hint"></div>
+<script>
+(function() {
+  var log = document.getElementById("log");
+  var input = document.getElementById("input");
+  var promptEl = document.getElementById("prompt");
+  var hint = document.getElementById("hint");
+  var history = [], histPos = 0;
+  var block = null, out = null, lines = 0;
+  var collapseLines = 12;
+
+  var url = (location.protocol == "https:" ? "wss://" : "ws://") + location.host + "/ws";
+  var secret = new URLSearchParams(location.search).get("secret");
+  if (secret) url += "?secret=" + encodeURIComponent(secret);
+  var ws = new WebSocket(url);
+
+  function send(m) { ws.send(JSON.stringify(m)); }
+
+  // startBlock starts the block for input line, or one for output that
+  // comes before any input.
+  function startBlock(line) {
+    out = document.createElement("pre");
+    lines = 0;
+    if (line === null) {
+      block = null;
+      log.appendChild(out);
+      return;
+    }
+    block = document.createElement("details");
+    block.open = true;
+    var summary = document.createElement("summary");
+    summary.textContent = promptEl.textContent + line;
+    block.appendChild(summary);
+    block.appendChild(out);
+    log.appendChild(block);
+  }
+
+  function endBlock() {
+    if (block && lines > collapseLines) {
+      block.open = false;
+      var count = document.createElement("span");
+      count.className = "count";
+      count.textContent = "  (" + lines + " lines)";
+      block.firstChild.appendChild(count);
+    }
+    block = out = null;
+  }
+
+  function output(text, stream) {
+    if (!out) startBlock(null);
+    var span = document.createElement("span");
+    if (stream == "stderr") span.className = "stderr";
+    span.textContent = text;
+    out.appendChild(span);
+    lines += text.split("\n").length - 1;
+    window.scrollTo(0, document.body.scrollHeight);
+  }
+
+  function commonPrefix(words) {
+    var p = words[0];
+    for (var i = 1; i < words.length; i++) {
+      while (words[i].indexOf(p) != 0) p = p.slice(0, -1);
+    }
+    return p;
+  }
+
+  ws.onmessage = function(e) {
+    var m = JSON.parse(e.data);
+    switch (m.type) {
+    case "output":
+      output(m.text, m.stream);
+      break;
+    case "prompt":
+      endBlock();
+      if (m.text === undefined) break;
+      promptEl.textContent = m.text;
+      input.disabled = false;
+      input.focus();
+      break;
+    case "completions":
+      var matches = m.matches || [];
+      var prefix = m.prefix || "";
+      var rest = input.value.slice(input.selectionStart);
+      hint.textContent = matches.length > 1 ? matches.join("  ") : "";
+      if (matches.length == 0) break;
+      var word = commonPrefix(matches);
+      input.value = prefix + word + rest;
+      input.selectionStart = input.selectionEnd = (prefix + word).length;
+      break;
+    }
+  };
+
+  ws.onclose = function() {
+    endBlock();
+    input.disabled = true;
+    promptEl.textContent = "";
+    hint.textContent = "Session ended. Reload the page for a new one.";
+  };
+
+  input.addEventListener("keydown", function(e) {
+    switch (e.key) {
+    case "Enter":
+      var line = input.value;
+      if (line != "" && line != history[history.length - 1]) history.push(line);
+      histPos = history.length;
+      startBlock(line);
+      input.value = hint.textContent = "";
+      input.disabled = true;
+      send({type: "input", line: line});
+      break;
+    case "Tab":
+      e.preventDefault();
+      send({type: "complete", line: input.value.slice(0, input.selectionStart)});
+      break;
+    case "ArrowUp":
+      if (histPos > 0) input.value = history[--histPos];
+      e.preventDefault();
+      break;
+    case "ArrowDown":
+      if (histPos < history.length) histPos++;
+      input.value = histPos < history.length ? history[histPos] : "";
+      e.preventDefault();
+      break;
+    default:
+      return;
+    }
+  });
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,165 @@
+// Copyright 2014 Rocky Bernstein.
+// REPL sessions in a web browser
+
+// Package web serves go-fish REPL sessions to web browsers: a page
+// and a WebSocket endpoint that runs one session for each browser tab.
+// Nothing is loaded from elsewhere, so it works without a network
+// connection.
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/0xfaded/eval"
+	"github.com/rocky/go-fish"
+)
+
+// Server serves the REPL page at "/" and runs a session for each
+// WebSocket connection to "/ws". Like repl.Server, each session gets
+// its own copy of the environment.
+type Server struct {
+	Env *eval.Env
+
+	// Secret, if not empty, must be given in the page's URL as
+	// ?secret=SECRET.
+	Secret string
+
+	// ReadOnly makes sessions read-only; see repl.CheckReadOnly.
+	ReadOnly bool
+
+	// Policy, if not nil, is the sandbox policy for sessions; see
+	// repl.Policy.
+	Policy *repl.Policy
+}
+
+// ListenAndServe serves REPL sessions in env on TCP address addr,
+// e.g. ":8080".
+func ListenAndServe(addr string, env *eval.Env) error {
+	return http.ListenAndServe(addr, &Server{Env: env})
+}
+
+// message is what is sent each way over the WebSocket, as JSON.
+//
+// From the browser:
+//    {"type": "input", "line": LINE}
+//    {"type": "complete", "line": LINE-UP-TO-CURSOR}
+// From us:
+//    {"type": "output", "text": TEXT, "stream": "stdout" or "stderr"}
+//    {"type": "prompt", "text": PROMPT}
+//    {"type": "completions", "prefix": PREFIX, "matches": [...]}
+// where the line with a completion is PREFIX followed by a match.
+type message struct {
+	Type    string   `json:"type"`
+	Line    string   `json:"line,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Stream  string   `json:"stream,omitempty"`
+	Prefix  string   `json:"prefix,omitempty"`
+	Matches []string `json:"matches,omitempty"`
+}
+
+// ServeHTTP serves the page and WebSocket connections.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	case "/ws":
+		secret := r.URL.Query().Get("secret")
+		if srv.Secret != "" &&
+			subtle.ConstantTimeCompare([]byte(secret), []byte(srv.Secret)) != 1 {
+			http.Error(w, "wrong secret", http.StatusForbidden)
+			return
+		}
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		srv.serveSession(c)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// conn sends messages to a browser.
+type conn struct {
+	*Conn
+}
+
+func (c conn) send(m message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(data)
+}
+
+// Write sends output, so that a conn can be a session's Out.
+func (c conn) Write(p []byte) (int, error) {
+	if err := c.send(message{Type: "output", Text: string(p),
+		Stream: "stdout"}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// serveSession runs a REPL session for the browser tab on ws. What
+// the session shows goes to the tab, but what expressions write to
+// os.Stdout and os.Stderr, e.g. with fmt.Println, goes to the
+// server's own output, since those are shared by all tabs.
+func (srv *Server) serveSession(ws *Conn) {
+	c := conn{ws}
+	var s *repl.Session
+
+	// readLine sends the prompt and waits for a line, answering
+	// completion requests while it waits.
+	readLine := func(prompt string, add_history ...bool) (string, error) {
+		if err := c.send(message{Type: "prompt", Text: prompt}); err != nil {
+			return "", err
+		}
+		for {
+			data, err := c.ReadMessage()
+			if err != nil {
+				return "", err
+			}
+			var m message
+			if err := json.Unmarshal(data, &m); err != nil {
+				return "", err
+			}
+			switch m.Type {
+			case "input":
+				return m.Line, nil
+			case "complete":
+				start, matches := s.Complete(m.Line, len(m.Line))
+				c.send(message{Type: "completions", Prefix: m.Line[:start],
+					Matches: matches})
+			}
+		}
+	}
+
+	s = repl.NewSession(repl.CopyEnv(srv.Env), readLine, nil)
+	s.Out = c
+	s.ReadOnly = srv.ReadOnly
+	s.Policy = srv.Policy
+	mode := ""
+	if restrictions := s.Restrictions(); restrictions != "" {
+		mode = " (" + restrictions + ")"
+	}
+	io.WriteString(c, "go-fish session"+mode+". Enter Go expressions, "+
+		"or \"help\" for REPL commands.\nTab completes; up and down arrows "+
+		"go through history.\n")
+
+	for !s.LeaveREPL {
+		line, err := s.ReadLine("gofish> ", true)
+		if err != nil {
+			return
+		}
+		// Not s.Capture: capturing os.Stdout would keep other tabs
+		// waiting until this line is done.
+		s.ProcessLine(line)
+	}
+	c.send(message{Type: "prompt"})
+}
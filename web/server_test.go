@@ -0,0 +1,188 @@
+package web_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocky/go-fish"
+	_ "github.com/rocky/go-fish/cmd"
+	"github.com/rocky/go-fish/web"
+)
+
+// client is a stand-in for a browser tab.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+type message struct {
+	Type    string   `json:"type"`
+	Line    string   `json:"line,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Stream  string   `json:"stream,omitempty"`
+	Prefix  string   `json:"prefix,omitempty"`
+	Matches []string `json:"matches,omitempty"`
+}
+
+// dial opens a WebSocket to the server at addr, returning the HTTP
+// status line if the server doesn't switch protocols.
+func dial(t *testing.T, addr, path, origin string) (*client, string) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: "+addr+"\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n"+
+		"Origin: "+origin+"\r\n\r\n")
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	accept := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "\r\n" {
+			break
+		}
+		if strings.HasPrefix(line, "Sec-WebSocket-Accept: ") {
+			accept = strings.TrimSpace(line[len("Sec-WebSocket-Accept: "):])
+		}
+	}
+	if !strings.Contains(status, " 101 ") {
+		conn.Close()
+		return nil, status
+	}
+	// The example from RFC 6455.
+	if accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("bad Sec-WebSocket-Accept %q", accept)
+	}
+	return &client{t, conn, r}, status
+}
+
+func (c *client) send(m message) {
+	data, _ := json.Marshal(m)
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | 126, 0, 0}
+	binary.BigEndian.PutUint16(frame[2:], uint16(len(data)))
+	frame = append(frame, mask...)
+	for i, b := range data {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) recv() message {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	size := int(hdr[1] & 0x7f)
+	switch size {
+	case 126:
+		var n uint16
+		binary.Read(c.r, binary.BigEndian, &n)
+		size = int(n)
+	case 127:
+		var n uint64
+		binary.Read(c.r, binary.BigEndian, &n)
+		size = int(n)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		c.t.Fatal(err)
+	}
+	var m message
+	if hdr[0]&0x0f == 0x8 {
+		return message{Type: "close"}
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// output returns the output up to the next prompt.
+func (c *client) output() string {
+	text := ""
+	for {
+		m := c.recv()
+		switch m.Type {
+		case "output":
+			text += m.Text
+		case "prompt":
+			return text
+		default:
+			c.t.Fatalf("unexpected message %v", m)
+		}
+	}
+}
+
+func TestWebSession(t *testing.T) {
+	*repl.Highlight = false
+	env := repl.MakeEvalEnv()
+	ts := httptest.NewServer(&web.Server{Env: &env, Secret: "xyzzy"})
+	defer ts.Close()
+	addr := ts.Listener.Addr().String()
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 ||
+		!strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("expecting an HTML page; got %s", resp.Status)
+	}
+
+	if _, status := dial(t, addr, "/ws?secret=wrong", "http://"+addr); !strings.Contains(status, " 403 ") {
+		t.Errorf("wrong secret: expecting 403; got %s", status)
+	}
+	if _, status := dial(t, addr, "/ws?secret=xyzzy", "http://evil.example.com"); !strings.Contains(status, " 403 ") {
+		t.Errorf("other origin: expecting 403; got %s", status)
+	}
+
+	c, status := dial(t, addr, "/ws?secret=xyzzy", "http://"+addr)
+	if c == nil {
+		t.Fatalf("expecting a WebSocket; got %s", status)
+	}
+	defer c.conn.Close()
+	if greeting := c.output(); !strings.Contains(greeting, "go-fish session") {
+		t.Errorf("unexpected greeting %q", greeting)
+	}
+
+	c.send(message{Type: "input", Line: "help quit"})
+	if out := c.output(); !strings.Contains(out, "quit") {
+		t.Errorf("expecting help for quit; got %q", out)
+	}
+
+	c.send(message{Type: "complete", Line: "x := strin"})
+	m := c.recv()
+	if m.Type != "completions" || m.Prefix != "x := " ||
+		len(m.Matches) == 0 || m.Matches[0] != "strings" {
+		t.Errorf("unexpected completions %v", m)
+	}
+
+	c.send(message{Type: "input", Line: "quit"})
+	c.output()
+	if m := c.recv(); m.Type != "close" {
+		t.Errorf("expecting the session to close; got %v", m)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2014 Rocky Bernstein.
+// Minimal server side of the WebSocket protocol
+
+package web
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// This is just the server side of RFC 6455 that a REPL page needs:
+// no extensions or subprotocols, and text messages.
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// maxMessageSize limits the messages we accept from browsers.
+const maxMessageSize = 1 << 20
+
+// websocketGUID is what RFC 6455 says to append to the client's key.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Conn is a WebSocket connection.
+type Conn struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	wlock sync.Mutex
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for key.
+func AcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// sameOrigin reports whether request r comes from a page served from
+// the host r was sent to. Browsers let any page open a WebSocket to
+// any host, so without this check another web site could drive our
+// REPL.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Not from a browser.
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Upgrade turns HTTP request r into a WebSocket connection. An HTTP
+// error has been sent if an error is returned.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != "GET" ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		http.Error(w, "expecting a WebSocket request", http.StatusBadRequest)
+		return nil, errors.New("not a WebSocket request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusBadRequest)
+		return nil, errors.New("unsupported WebSocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("missing Sec-WebSocket-Key")
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin WebSocket request", http.StatusForbidden)
+		return nil, errors.New("cross-origin WebSocket request")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "can't take over connection", http.StatusInternalServerError)
+		return nil, errors.New("http.ResponseWriter is not an http.Hijacker")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, r: rw.Reader}, nil
+}
+
+// readFrame reads one frame, unmasking its payload.
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(c.r, hdr[:]); err != nil {
+		return
+	}
+	fin, opcode = hdr[0]&0x80 != 0, hdr[0]&0x0f
+	masked := hdr[1]&0x80 != 0
+	size := uint64(hdr[1] & 0x7f)
+	switch size {
+	case 126:
+		var n uint16
+		err = binary.Read(c.r, binary.BigEndian, &n)
+		size = uint64(n)
+	case 127:
+		err = binary.Read(c.r, binary.BigEndian, &size)
+	}
+	if err != nil {
+		return
+	}
+	if size > maxMessageSize {
+		err = errors.New("WebSocket message too big")
+		return
+	}
+	if !masked {
+		err = errors.New("unmasked frame from client")
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.r, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(c.r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// writeFrame writes a single, final, unmasked frame.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	hdr := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		hdr[1] = byte(n)
+	case n <= 0xffff:
+		hdr[1] = 126
+		hdr = append(hdr, byte(n>>8), byte(n))
+	default:
+		hdr[1] = 127
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(n))
+		hdr = append(hdr, size[:]...)
+	}
+	if _, err := c.conn.Write(hdr); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// ReadMessage returns the next text or binary message, answering
+// pings along the way. io.EOF is returned when the browser closes the
+// connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case opPing:
+			c.writeFrame(opPong, payload)
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			msg = append(msg, payload...)
+			if len(msg) > maxMessageSize {
+				return nil, errors.New("WebSocket message too big")
+			}
+			if fin {
+				return msg, nil
+			}
+		default:
+			return nil, errors.New("unknown WebSocket opcode")
+		}
+	}
+}
+
+// WriteMessage sends data as a text message.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.writeFrame(opClose, []byte{0x03, 0xe8}) // 1000: normal closure
+	return c.conn.Close()
+}